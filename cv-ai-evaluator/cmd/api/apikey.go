@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+	"cv-ai-evaluator/internal/services"
+
+	"gorm.io/gorm"
+)
+
+const apiKeyUsage = `usage: apikey <command>
+
+commands:
+  create [-org ID] NAME  create a key in an organization (default: default)
+                         and print it (shown only once)
+  list                   list keys with their organization, prefix, last use
+                         and revocation time
+  revoke ID              revoke a key immediately`
+
+// runAPIKey implements the "apikey" admin subcommand
+func runAPIKey(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", apiKeyUsage)
+	}
+
+	apiKeyService := services.NewAPIKeyService(
+		repository.NewGormAPIKeyRepository(db),
+		repository.NewGormOrganizationRepository(db),
+	)
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		orgID := flags.String("org", models.DefaultOrganizationID, "organization the key belongs to")
+		if err := flags.Parse(args[1:]); err != nil {
+			return fmt.Errorf("%v\n%s", err, apiKeyUsage)
+		}
+		if flags.NArg() == 0 {
+			return fmt.Errorf("create requires a name\n%s", apiKeyUsage)
+		}
+		plaintext, key, err := apiKeyService.CreateKey(strings.Join(flags.Args(), " "), *orgID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created API key %s (%s) in organization %s\n", key.ID, key.Name, key.OrganizationID)
+		fmt.Printf("Key: %s\n", plaintext)
+		fmt.Println("Store it now; it cannot be shown again.")
+
+	case "list":
+		keys, err := apiKeyService.ListKeys()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			state := "active"
+			if key.RevokedAt.Valid {
+				state = "revoked " + key.RevokedAt.Time.Format("2006-01-02 15:04:05")
+			}
+			lastUsed := "never"
+			if key.LastUsedAt.Valid {
+				lastUsed = key.LastUsedAt.Time.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s  %-36s  %s…  %-24s last used %-19s  %s\n", key.ID, key.OrganizationID, key.Prefix, key.Name, lastUsed, state)
+		}
+
+	case "revoke":
+		if len(args) < 2 {
+			return fmt.Errorf("revoke requires a key ID\n%s", apiKeyUsage)
+		}
+		if err := apiKeyService.RevokeKey(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked API key %s\n", args[1])
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], apiKeyUsage)
+	}
+
+	return nil
+}
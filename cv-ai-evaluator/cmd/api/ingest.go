@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cv-ai-evaluator/config"
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+	"cv-ai-evaluator/internal/services"
+	"cv-ai-evaluator/pkg/vectordb"
+
+	"gorm.io/gorm"
+)
+
+const ingestUsage = `usage: ingest [-org ID] [-dry-run] (-dir DIR | -manifest FILE)
+
+Syncs an organization's ground truth (default: default) with a directory:
+new files are indexed, changed files become the next version of their
+document, unchanged files are skipped and documents whose file is gone are
+retired. A running API server reads the changes after SIGHUP or a restart.
+
+  -dir DIR        ingest every .md, .txt and .pdf file under DIR. The type
+                  comes from a parent directory named after it (for example
+                  DIR/cv_rubric/backend.md) or else from the file name
+                  (job_description, case_study, cv..rubric, project..rubric)
+  -manifest FILE  ingest the files listed in a JSON manifest, relative to
+                  its directory:
+                  [{"path": "jd.md", "type": "job_description",
+                    "role": "backend-engineer", "name": "Backend Engineer"}]
+  -dry-run        print what would change without writing anything`
+
+// manifestEntry is one document of an ingest manifest
+type manifestEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Role string `json:"role"`
+	Name string `json:"name"`
+}
+
+// runIngest implements the "ingest" admin subcommand
+func runIngest(cfg *config.Config, db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("ingest", flag.ContinueOnError)
+	orgID := flags.String("org", models.DefaultOrganizationID, "organization that owns the ingested documents")
+	dir := flags.String("dir", "", "directory to scan for ground truth files")
+	manifest := flags.String("manifest", "", "JSON manifest listing path, type, role and name")
+	dryRun := flags.Bool("dry-run", false, "report changes without applying them")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%v\n%s", err, ingestUsage)
+	}
+	if (*dir == "") == (*manifest == "") {
+		return fmt.Errorf("pass exactly one of -dir and -manifest\n%s", ingestUsage)
+	}
+
+	org, err := services.NewOrganizationService(repository.NewGormOrganizationRepository(db)).GetOrganization(*orgID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		root    string
+		sources []services.GroundTruthSource
+	)
+	if *manifest != "" {
+		root = filepath.Dir(*manifest)
+		sources, err = readManifest(*manifest)
+	} else {
+		root = *dir
+		sources, err = scanGroundTruthDir(*dir)
+	}
+	if err != nil {
+		return err
+	}
+
+	chromaClient, err := vectordb.NewChromaClient(cfg.VectorDBPath, cfg.OllamaURL, vectordb.ChunkOptions{
+		MaxTokens:     cfg.RAGChunkTokens,
+		OverlapTokens: cfg.RAGChunkOverlapTokens,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to initialize ChromaDB: %w", err)
+	}
+	groundTruthService := services.NewGroundTruthService(
+		repository.NewGormGroundTruthRepository(db),
+		chromaClient,
+		filepath.Join(cfg.UploadDir, "groundtruth"),
+	)
+
+	report, err := groundTruthService.SyncSources(context.Background(), org.ID, root, sources, *dryRun)
+	if err != nil {
+		return err
+	}
+	printIngestReport(org, report)
+
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%d document(s) could not be ingested", failed)
+	}
+	return nil
+}
+
+func readManifest(path string) ([]services.GroundTruthSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var entries []manifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	sources := make([]services.GroundTruthSource, 0, len(entries))
+	for i, entry := range entries {
+		if entry.Path == "" {
+			return nil, fmt.Errorf("manifest entry %d has no path", i+1)
+		}
+		gtType, err := services.ParseGroundTruthType(entry.Type)
+		if err != nil {
+			return nil, fmt.Errorf("manifest entry %d (%s): %w", i+1, entry.Path, err)
+		}
+		if !filepath.IsAbs(entry.Path) {
+			entry.Path = filepath.Join(filepath.Dir(path), entry.Path)
+		}
+		sources = append(sources, services.GroundTruthSource{
+			Path:         entry.Path,
+			DocumentType: gtType,
+			Role:         entry.Role,
+			DocumentName: entry.Name,
+		})
+	}
+	return sources, nil
+}
+
+func scanGroundTruthDir(dir string) ([]services.GroundTruthSource, error) {
+	var sources []services.GroundTruthSource
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".md", ".txt", ".pdf":
+		default:
+			return nil
+		}
+
+		gtType, ok := groundTruthTypeOf(dir, path)
+		if !ok {
+			log.Printf("Warning: skipping %s, its type cannot be told from its directory or name", path)
+			return nil
+		}
+		sources = append(sources, services.GroundTruthSource{Path: path, DocumentType: gtType})
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("ground truth directory not found: %s", dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	return sources, nil
+}
+
+// groundTruthTypeOf infers the type of a file found by a directory scan: a
+// parent directory named after a type wins over the file name
+func groundTruthTypeOf(root, path string) (models.GroundTruthType, bool) {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err == nil && rel != "." {
+		for _, part := range strings.Split(rel, string(filepath.Separator)) {
+			if gtType, err := services.ParseGroundTruthType(part); err == nil {
+				return gtType, true
+			}
+		}
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.Contains(name, "job_description"):
+		return models.GroundTruthTypeJobDescription, true
+	case strings.Contains(name, "case_study"):
+		return models.GroundTruthTypeCaseStudyBrief, true
+	case strings.Contains(name, "cv") && strings.Contains(name, "rubric"):
+		return models.GroundTruthTypeCVRubric, true
+	case strings.Contains(name, "project") && strings.Contains(name, "rubric"):
+		return models.GroundTruthTypeProjectRubric, true
+	}
+	return "", false
+}
+
+func printIngestReport(org *models.Organization, report *services.IngestReport) {
+	if report.DryRun {
+		fmt.Printf("Dry run for organization %s (%s), nothing was written\n", org.ID, org.Name)
+	} else {
+		fmt.Printf("Ingested ground truth for organization %s (%s)\n", org.ID, org.Name)
+	}
+
+	symbols := map[services.IngestAction]string{
+		services.IngestCreate:    "+",
+		services.IngestUpdate:    "~",
+		services.IngestUnchanged: "=",
+		services.IngestRemove:    "-",
+	}
+	for _, change := range report.Changes {
+		if change.Err != nil {
+			fmt.Printf("! %-9s %s: %v\n", change.Action, change.Path, change.Err)
+			continue
+		}
+		doc := change.Document
+		if doc == nil {
+			doc = change.Previous
+		}
+		version := "v" + doc.Version
+		if change.Action == services.IngestUpdate {
+			version = fmt.Sprintf("v%s -> v%s", change.Previous.Version, doc.Version)
+		}
+		fmt.Printf("%s %-9s %-16s %-32s %-14s %s\n", symbols[change.Action], change.Action, doc.DocumentType, doc.DocumentName, version, change.Path)
+	}
+
+	fmt.Printf("%d created, %d updated, %d unchanged, %d removed, %d failed\n",
+		report.Count(services.IngestCreate), report.Count(services.IngestUpdate),
+		report.Count(services.IngestUnchanged), report.Count(services.IngestRemove), report.Failed())
+	if !report.DryRun && report.Count(services.IngestUnchanged) < len(report.Changes) {
+		fmt.Println("A running API server still sees the old vector DB: send it SIGHUP (kill -HUP <pid>) or restart it")
+	}
+}
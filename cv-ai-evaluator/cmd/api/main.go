@@ -1,14 +1,20 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
 	"syscall"
 
 	"cv-ai-evaluator/config"
 	"cv-ai-evaluator/internal/database"
 	"cv-ai-evaluator/internal/handlers"
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
 	"cv-ai-evaluator/internal/services"
 	"cv-ai-evaluator/internal/worker"
 	"cv-ai-evaluator/pkg/llm"
@@ -26,16 +32,71 @@ func main() {
 	}
 
 	// Initialize database
-	if err := database.InitDB(cfg); err != nil {
+	db, err := database.InitDB(cfg)
+	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer database.CloseDB()
+	defer database.CloseDB(db)
+
+	// Subcommand: go run ./cmd/api migrate [up|down|status|force]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// Subcommand: go run ./cmd/api org [create|list|callback]
+	if len(os.Args) > 1 && os.Args[1] == "org" {
+		if err := runOrg(db, os.Args[2:]); err != nil {
+			log.Fatalf("Organization command failed: %v", err)
+		}
+		return
+	}
+
+	// Subcommand: go run ./cmd/api apikey [create|list|revoke]
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		if err := runAPIKey(db, os.Args[2:]); err != nil {
+			log.Fatalf("API key command failed: %v", err)
+		}
+		return
+	}
+
+	// Subcommand: go run ./cmd/api ingest [-org ID] [-dry-run] (-dir DIR | -manifest FILE)
+	if len(os.Args) > 1 && os.Args[1] == "ingest" {
+		if err := runIngest(cfg, db, os.Args[2:]); err != nil {
+			log.Fatalf("Ground truth ingestion failed: %v", err)
+		}
+		return
+	}
 
-	// Initialize Ollama client
-	ollamaClient := llm.NewOllamaClient(cfg.OllamaURL, "gemma3:4b")
+	migrateOnStartup := flag.Bool("migrate", cfg.MigrateOnStartup, "apply pending database migrations before starting")
+	flag.Parse()
+	if *migrateOnStartup {
+		applied, err := database.MigrateUp(db, 0)
+		if err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		log.Printf("Applied %d pending migration(s)", applied)
+	}
+
+	// Initialize LLM provider (ollama / openai-compatible)
+	llmProvider, err := llm.NewProvider(llm.ProviderConfig{
+		Provider: cfg.LLMProvider,
+		BaseURL:  cfg.GetLLMBaseURL(),
+		Model:    cfg.LLMModel,
+		APIKey:   cfg.LLMAPIKey,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize LLM provider: %v", err)
+	}
+	log.Printf("Using LLM provider %q with model %q", cfg.LLMProvider, cfg.LLMModel)
 
 	// Initialize ChromaDB client
-	chromaClient, err := vectordb.NewChromaClient("./chroma_data")
+	chromaClient, err := vectordb.NewChromaClient(cfg.VectorDBPath, cfg.OllamaURL, vectordb.ChunkOptions{
+		MaxTokens:     cfg.RAGChunkTokens,
+		OverlapTokens: cfg.RAGChunkOverlapTokens,
+	})
 	if err != nil {
 		log.Fatalf("Failed to initialize ChromaDB: %v", err)
 	}
@@ -45,41 +106,172 @@ func main() {
 		log.Fatalf("Failed to create upload directory: %v", err)
 	}
 
+	// Initialize repositories
+	jobRepo, err := repository.NewGormJobRepository(db)
+	if err != nil {
+		log.Fatalf("Failed to initialize job repository: %v", err)
+	}
+	documentRepo := repository.NewGormDocumentRepository(db)
+	groundTruthRepo := repository.NewGormGroundTruthRepository(db)
+	webhookDeliveryRepo := repository.NewGormWebhookDeliveryRepository(db)
+	apiKeyRepo := repository.NewGormAPIKeyRepository(db)
+	organizationRepo := repository.NewGormOrganizationRepository(db)
+
 	// Initialize services
-	documentService := services.NewDocumentService(cfg.UploadDir)
-	evaluationService := services.NewEvaluationService()
+	documentService := services.NewDocumentService(cfg.UploadDir, documentRepo)
+	evaluationService := services.NewEvaluationService(jobRepo)
+	groundTruthService := services.NewGroundTruthService(groundTruthRepo, chromaClient, filepath.Join(cfg.UploadDir, "groundtruth"))
+	progressService := services.NewProgressService(jobRepo)
+	webhookService := services.NewWebhookService(jobRepo, webhookDeliveryRepo)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, organizationRepo)
+	organizationService := services.NewOrganizationService(organizationRepo)
+
+	// Ground truth indexed before chunking is split per section once
+	if n, err := groundTruthService.ChunkLegacyDocuments(context.Background()); err != nil {
+		log.Printf("Warning: failed to chunk ground truth documents: %v", err)
+	} else if n > 0 {
+		log.Printf("Re-indexed %d ground truth document(s) per section", n)
+	}
+
+	// Check that ingested ground truth is actually available for RAG
+	reportGroundTruthCoverage(groundTruthService, organizationService, chromaClient)
+
+	// Ground truth retrieval: embedding and BM25 rankings fused, optionally reranked
+	reranker, err := vectordb.NewReranker(cfg.RAGReranker, cfg.RAGRerankerURL, llmProvider, cfg.LLMMaxRepairAttempts)
+	if err != nil {
+		log.Fatalf("Failed to initialize reranker: %v", err)
+	}
+	retriever := vectordb.NewRetriever(chromaClient, vectordb.RetrieverConfig{
+		Hybrid:     cfg.RAGHybrid,
+		RRFK:       cfg.RAGRRFK,
+		Candidates: cfg.RAGCandidates,
+		Reranker:   reranker,
+	})
+	log.Printf("Ground truth retrieval: hybrid=%t, reranker %q", cfg.RAGHybrid, cfg.RAGReranker)
+	retrievalService := services.NewRetrievalService(retriever, groundTruthService, cfg.RAGContextTokens)
 
 	// Initialize worker pool with services
-	workerPool := worker.NewWorkerPool(3, ollamaClient, chromaClient, evaluationService)
+	workerPool := worker.NewWorkerPool(worker.PoolConfig{
+		WorkerCount:   cfg.WorkerCount,
+		LeaseDuration: cfg.JobLeaseDuration,
+		PollInterval:  cfg.JobPollInterval,
+		MaxQueueDepth: cfg.MaxQueueDepth,
+		RetryAfter:    cfg.QueueRetryAfter,
+		JobTimeout:    cfg.JobTimeout,
+		ContextTokens: cfg.RAGContextTokens,
+		Retry: worker.RetryPolicy{
+			MaxAttempts: cfg.JobMaxAttempts,
+			BaseDelay:   cfg.JobRetryBaseDelay,
+			MaxDelay:    cfg.JobRetryMaxDelay,
+		},
+
+		MaxRepairAttempts: cfg.LLMMaxRepairAttempts,
+	}, llmProvider, retriever, evaluationService, groundTruthService)
 	workerPool.Start()
 
+	// Deliver job results to callback URLs, never to internal addresses
+	// unless their host is allowlisted
+	callbackPolicy := services.NewCallbackURLPolicy(cfg.WebhookAllowedHosts)
+	if cfg.WebhookDefaultURL != "" {
+		if cfg.WebhookSecret == "" {
+			log.Fatal("WEBHOOK_DEFAULT_URL is set but WEBHOOK_SECRET is empty; callbacks are never sent unsigned")
+		}
+		if err := callbackPolicy.Validate(context.Background(), cfg.WebhookDefaultURL); err != nil {
+			log.Printf("Warning: WEBHOOK_DEFAULT_URL is rejected, jobs that fall back to it cannot be created (add its host to WEBHOOK_ALLOWED_HOSTS): %v", err)
+		}
+	}
+	webhookDispatcher := worker.NewWebhookDispatcher(worker.WebhookConfig{
+		Secret:         cfg.WebhookSecret,
+		Timeout:        cfg.WebhookTimeout,
+		PollInterval:   cfg.JobPollInterval,
+		CallbackPolicy: callbackPolicy,
+		Retry: worker.RetryPolicy{
+			MaxAttempts: cfg.WebhookMaxAttempts,
+			BaseDelay:   cfg.WebhookRetryBaseDelay,
+			MaxDelay:    cfg.WebhookRetryMaxDelay,
+		},
+	}, webhookService, organizationService, func(job *models.EvaluationJob) interface{} {
+		return handlers.BuildResultResponse(job)
+	})
+	webhookDispatcher.Start()
+
 	// Setup Gin router
 	router := gin.Default()
 
-	// CORS configuration
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-	}))
+	// CORS configuration. API keys travel in a header, not cookies, so
+	// credentials are never allowed; without CORS_ALLOWED_ORIGINS any origin may call.
+	corsConfig := cors.Config{
+		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE"},
+		AllowHeaders:  []string{"Origin", "Content-Type", "Authorization", "X-API-Key"},
+		ExposeHeaders: []string{"Content-Length", "Retry-After"},
+	}
+	if len(cfg.CORSAllowedOrigins) > 0 {
+		corsConfig.AllowOrigins = cfg.CORSAllowedOrigins
+	} else {
+		corsConfig.AllowAllOrigins = true
+	}
+	router.Use(cors.New(corsConfig))
 
 	// Initialize handlers with services
 	uploadHandler := handlers.NewUploadHandler(documentService)
-	evaluateHandler := handlers.NewEvaluateHandler(workerPool, documentService, evaluationService)
-	resultHandler := handlers.NewResultHandler(evaluationService)
+	evaluateHandler := handlers.NewEvaluateHandler(workerPool, documentService, evaluationService, groundTruthService, organizationService, cfg.WebhookDefaultURL, callbackPolicy, cfg.WebhookSecret)
+	resultHandler := handlers.NewResultHandler(evaluationService, progressService)
+	metricsHandler := handlers.NewMetricsHandler(workerPool)
+	jobHandler := handlers.NewJobHandler(workerPool, evaluationService, webhookService)
+	groundTruthHandler := handlers.NewGroundTruthHandler(groundTruthService)
+	retrievalHandler := handlers.NewRetrievalHandler(retrievalService)
 
-	// Routes
-	router.POST("/upload", uploadHandler.Upload)
-	router.POST("/evaluate", evaluateHandler.Evaluate)
-	router.GET("/result/:id", resultHandler.GetResult)
+	// Routes. Every client only sees the documents and jobs of its own organization.
+	api := router.Group("/")
+	if cfg.AuthEnabled {
+		api.Use(handlers.RequireAPIKey(apiKeyService))
+		// Authentication is on by default, so an upgraded install starts with no keys
+		keys, err := apiKeyService.ListKeys()
+		if err == nil && !slices.ContainsFunc(keys, func(k models.APIKey) bool { return k.Active() }) {
+			log.Println("Warning: AUTH_ENABLED=true but no active API key exists, every request will get 401; create one with `go run ./cmd/api apikey create \"<name>\"`")
+		}
+	} else {
+		log.Println("Warning: AUTH_ENABLED=false, API routes are open and not scoped per client")
+	}
+	api.POST("/upload", uploadHandler.Upload)
+	api.POST("/evaluate", evaluateHandler.Evaluate)
+	api.GET("/result/:id", resultHandler.GetResult)
+	api.GET("/result/:id/events", resultHandler.StreamEvents)
+	api.GET("/jobs", jobHandler.List)
+	api.GET("/jobs/stats", jobHandler.Stats)
+	api.POST("/jobs/:id/cancel", jobHandler.Cancel)
+	api.GET("/jobs/:id/webhooks", jobHandler.Webhooks)
+	api.POST("/jobs/:id/rerun", evaluateHandler.Rerun)
+	api.POST("/ground-truth", groundTruthHandler.Create)
+	api.GET("/ground-truth", groundTruthHandler.List)
+	api.GET("/ground-truth/:id", groundTruthHandler.Get)
+	api.GET("/ground-truth/:id/versions", groundTruthHandler.Versions)
+	api.PUT("/ground-truth/:id", groundTruthHandler.Replace)
+	api.DELETE("/ground-truth/:id", groundTruthHandler.Retire)
+	api.GET("/debug/retrieval", retrievalHandler.Debug)
+	// Operator metrics (queue depth, in-flight jobs); scrapers send an API key
+	api.GET("/metrics", metricsHandler.Metrics)
 
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// `ingest` writes VECTOR_DB_PATH from another process; SIGHUP makes the
+	// server read the vector DB from disk again
+	go func() {
+		hupChan := make(chan os.Signal, 1)
+		signal.Notify(hupChan, syscall.SIGHUP)
+		for range hupChan {
+			if err := chromaClient.Reload(); err != nil {
+				log.Printf("Warning: failed to reload vector DB: %v", err)
+				continue
+			}
+			log.Printf("Reloaded vector DB from %s", cfg.VectorDBPath)
+			reportGroundTruthCoverage(groundTruthService, organizationService, chromaClient)
+		}
+	}()
+
 	// Graceful shutdown
 	go func() {
 		sigChan := make(chan os.Signal, 1)
@@ -88,6 +280,7 @@ func main() {
 
 		log.Println("Shutting down gracefully...")
 		workerPool.Stop()
+		webhookDispatcher.Stop()
 		os.Exit(0)
 	}()
 
@@ -97,3 +290,35 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// reportGroundTruthCoverage logs, per organization, how many ground truth
+// documents per type are loaded in the vector store, warning about types that
+// will fall back to defaults
+func reportGroundTruthCoverage(groundTruthService *services.GroundTruthService, organizationService *services.OrganizationService, chromaClient *vectordb.ChromaClient) {
+	docs, err := groundTruthService.GetAllDocuments()
+	if err != nil {
+		log.Printf("Warning: could not check ground truth coverage: %v", err)
+		return
+	}
+	orgs, err := organizationService.ListOrganizations()
+	if err != nil {
+		log.Printf("Warning: could not check ground truth coverage: %v", err)
+		return
+	}
+
+	idsByOrg := make(map[string][]string)
+	for _, doc := range docs {
+		idsByOrg[doc.OrganizationID] = append(idsByOrg[doc.OrganizationID], doc.ID)
+	}
+
+	for _, org := range orgs {
+		counts := chromaClient.CountByType(context.Background(), org.ID, idsByOrg[org.ID])
+		for _, gtType := range services.AllGroundTruthTypes() {
+			if n := counts[string(gtType)]; n > 0 {
+				log.Printf("Ground truth [%s] %-17s: %d document(s) loaded", org.Name, gtType, n)
+			} else {
+				log.Printf("Warning: no %s documents in vector store for organization %s, RAG will fall back to defaults (upload with POST /ground-truth or run ingest -org %s -manifest storage/groundtruth/manifest.json)", gtType, org.Name, org.ID)
+			}
+		}
+	}
+}
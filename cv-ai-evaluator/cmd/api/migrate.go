@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"cv-ai-evaluator/internal/database"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up [N]         apply all (or the next N) pending migrations
+  down [N]       roll back the last N migrations (default 1)
+  status         list migrations and whether they are applied
+  force VERSION  mark migrations up to VERSION as applied without running them`
+
+// runMigrate implements the "migrate" subcommand
+func runMigrate(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", migrateUsage)
+	}
+
+	var n int
+	if len(args) > 1 {
+		var err error
+		if n, err = strconv.Atoi(args[1]); err != nil || n < 0 {
+			return fmt.Errorf("invalid number %q\n%s", args[1], migrateUsage)
+		}
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db, n)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+
+	case "down":
+		reverted, err := database.MigrateDown(db, n)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rolled back %d migration(s)\n", reverted)
+
+	case "status":
+		statuses, err := database.MigrationStatuses(db)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("force requires a version\n%s", migrateUsage)
+		}
+		if err := database.ForceVersion(db, n); err != nil {
+			return err
+		}
+		fmt.Printf("Schema version set to %d\n", n)
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], migrateUsage)
+	}
+
+	return nil
+}
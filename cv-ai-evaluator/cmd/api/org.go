@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"cv-ai-evaluator/internal/repository"
+	"cv-ai-evaluator/internal/services"
+
+	"gorm.io/gorm"
+)
+
+const orgUsage = `usage: org <command>
+
+commands:
+  create [-callback-url URL] NAME  create an organization (tenant) and print
+                                   its webhook secret
+  list                             list organizations and their callback URL
+  callback ID [URL]                set the default webhook URL of an
+                                   organization's jobs, or clear it
+  webhook-secret ID                generate a new secret that signs the
+                                   organization's webhook callbacks`
+
+// runOrg implements the "org" admin subcommand
+func runOrg(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", orgUsage)
+	}
+
+	organizationService := services.NewOrganizationService(repository.NewGormOrganizationRepository(db))
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("org create", flag.ContinueOnError)
+		callbackURL := flags.String("callback-url", "", "default webhook URL for the organization's jobs")
+		if err := flags.Parse(args[1:]); err != nil {
+			return fmt.Errorf("%v\n%s", err, orgUsage)
+		}
+		if flags.NArg() == 0 {
+			return fmt.Errorf("create requires a name\n%s", orgUsage)
+		}
+		org, err := organizationService.CreateOrganization(strings.Join(flags.Args(), " "), *callbackURL)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created organization %s (%s)\n", org.ID, org.Name)
+		fmt.Printf("Webhook secret (shown only once, callbacks are signed with it): %s\n", org.WebhookSecret.String)
+		fmt.Printf("Create a key with: apikey create -org %s NAME\n", org.ID)
+
+	case "list":
+		orgs, err := organizationService.ListOrganizations()
+		if err != nil {
+			return err
+		}
+		for _, org := range orgs {
+			callback := "-"
+			if org.CallbackURL.Valid {
+				callback = org.CallbackURL.String
+			}
+			secret := "own"
+			if !org.WebhookSecret.Valid {
+				secret = "WEBHOOK_SECRET"
+			}
+			fmt.Printf("%-36s  %-24s callback %s  signed with %s\n", org.ID, org.Name, callback, secret)
+		}
+
+	case "callback":
+		if len(args) < 2 {
+			return fmt.Errorf("callback requires an organization ID\n%s", orgUsage)
+		}
+		var callbackURL string
+		if len(args) > 2 {
+			callbackURL = args[2]
+		}
+		if err := organizationService.SetCallbackURL(args[1], callbackURL); err != nil {
+			return err
+		}
+		if callbackURL == "" {
+			fmt.Printf("Cleared callback URL of organization %s\n", args[1])
+		} else {
+			fmt.Printf("Callback URL of organization %s set to %s\n", args[1], callbackURL)
+		}
+
+	case "webhook-secret":
+		if len(args) < 2 {
+			return fmt.Errorf("webhook-secret requires an organization ID\n%s", orgUsage)
+		}
+		secret, err := organizationService.RotateWebhookSecret(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("New webhook secret of organization %s (shown only once): %s\n", args[1], secret)
+		fmt.Println("Callbacks are signed with it from now on; update the receiver before the next job finishes.")
+
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], orgUsage)
+	}
+
+	return nil
+}
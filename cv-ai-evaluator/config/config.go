@@ -3,36 +3,168 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Driver database yang didukung (DB_DRIVER)
+const (
+    DBDriverMySQL    = "mysql"
+    DBDriverPostgres = "postgres"
+    DBDriverSQLite   = "sqlite"
+)
+
 type Config struct {
+    // DB_DRIVER: mysql, postgres, atau sqlite (satu file lokal, tanpa server)
+    DBDriver   string
     DBHost     string
     DBPort     string
     DBUser     string
     DBPassword string
     DBName     string
+    DBSSLMode  string // postgres: disable, require, verify-full, ...
+    DBPath     string // sqlite: lokasi file database
     ServerPort string
     OllamaURL  string
     ChromaURL  string
     UploadDir  string
+
+    // Autentikasi API key (dibuat lewat subcommand `apikey`). Jika false, semua
+    // route terbuka dan data tidak dipisah per client (hanya untuk development).
+    AuthEnabled bool
+    // Origin yang boleh memanggil API dari browser; kosong berarti semua origin
+    CORSAllowedOrigins []string
+
+    // Jalankan migration database yang belum diterapkan saat server start
+    MigrateOnStartup bool
+
+    // Direktori penyimpanan vector DB (chromem-go persistent)
+    VectorDBPath string
+
+    // RAG: ground truth dipotong per section markdown sebesar RAGChunkTokens
+    // (dengan overlap RAGChunkOverlapTokens untuk section panjang); retrieval
+    // mengambil section teratas per tipe sampai RAGContextTokens. Semua dalam
+    // token perkiraan (±4 karakter per token).
+    RAGChunkTokens        int
+    RAGChunkOverlapTokens int
+    RAGContextTokens      int
+
+    // Hybrid retrieval: peringkat embedding digabung dengan peringkat BM25
+    // lewat reciprocal rank fusion (konstanta RAGRRFK), lalu RAGCandidates
+    // section teratas per tipe dipertimbangkan. RAGReranker ("none", "llm"
+    // atau "cross-encoder") menilai ulang kandidat tersebut; cross-encoder
+    // memanggil endpoint /rerank di RAGRerankerURL (API Text Embeddings Inference).
+    RAGHybrid      bool
+    RAGRRFK        int
+    RAGCandidates  int
+    RAGReranker    string
+    RAGRerankerURL string
+
+    // Worker pool & queue berbasis tabel evaluation_jobs
+    WorkerCount      int
+    JobLeaseDuration time.Duration
+    JobPollInterval  time.Duration
+    MaxQueueDepth    int
+    QueueRetryAfter  time.Duration
+    JobTimeout       time.Duration
+
+    // Retry policy untuk job yang gagal karena error sementara
+    JobMaxAttempts    int
+    JobRetryBaseDelay time.Duration
+    JobRetryMaxDelay  time.Duration
+
+    // Webhook callback saat job selesai (signature HMAC-SHA256 dengan secret
+    // organisasi job; WebhookSecret untuk organisasi yang belum punya secret)
+    WebhookSecret         string
+    WebhookDefaultURL     string
+    WebhookTimeout        time.Duration
+    WebhookMaxAttempts    int
+    WebhookRetryBaseDelay time.Duration
+    WebhookRetryMaxDelay  time.Duration
+    // Host callback yang boleh dituju; kosong berarti semua host publik
+    // (alamat private, loopback, dan link-local selalu ditolak kecuali di-allowlist)
+    WebhookAllowedHosts   []string
+
+    // LLM provider: "ollama" atau "openai" (OpenAI-compatible server)
+    LLMProvider string
+    LLMBaseURL  string
+    LLMModel    string
+    LLMAPIKey   string
+
+    // Batas re-prompt saat JSON dari LLM tidak lolos validasi schema
+    LLMMaxRepairAttempts int
 }
 
 func LoadConfig() (*Config, error) {
     // Load .env file jika ada
     godotenv.Load()
 
+    dbDriver := getEnv("DB_DRIVER", DBDriverMySQL)
+
     config := &Config{
+        DBDriver:   dbDriver,
         DBHost:     getEnv("DB_HOST", "localhost"),
-        DBPort:     getEnv("DB_PORT", "3306"),
+        DBPort:     getEnv("DB_PORT", defaultDBPort(dbDriver)),
         DBUser:     getEnv("DB_USER", "root"),
         DBPassword: getEnv("DB_PASSWORD", ""),
         DBName:     getEnv("DB_NAME", "cv_ai_evaluator"),
+        DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
+        DBPath:     getEnv("DB_PATH", "./cv_ai_evaluator.db"),
         ServerPort: getEnv("SERVER_PORT", "8080"),
         OllamaURL:  getEnv("OLLAMA_URL", "http://localhost:11434"),
         ChromaURL:  getEnv("CHROMA_URL", "http://localhost:8000"),
         UploadDir:  getEnv("UPLOAD_DIR", "./storage/uploads"),
+
+        AuthEnabled:        getEnvBool("AUTH_ENABLED", true),
+        CORSAllowedOrigins: getEnvList("CORS_ALLOWED_ORIGINS"),
+
+        MigrateOnStartup: getEnvBool("MIGRATE_ON_STARTUP", false),
+
+        VectorDBPath: getEnv("VECTOR_DB_PATH", "./chroma_data"),
+
+        RAGChunkTokens:        getEnvInt("RAG_CHUNK_TOKENS", 400),
+        RAGChunkOverlapTokens: getEnvInt("RAG_CHUNK_OVERLAP_TOKENS", 60),
+        RAGContextTokens:      getEnvInt("RAG_CONTEXT_TOKENS", 1500),
+        RAGHybrid:             getEnvBool("RAG_HYBRID", true),
+        RAGRRFK:               getEnvInt("RAG_RRF_K", 60),
+        RAGCandidates:         getEnvInt("RAG_CANDIDATES", 12),
+        RAGReranker:           getEnv("RAG_RERANKER", "none"),
+        RAGRerankerURL:        getEnv("RAG_RERANKER_URL", "http://localhost:8082"),
+
+        WorkerCount:      getEnvInt("WORKER_COUNT", 3),
+        JobLeaseDuration: getEnvDuration("JOB_LEASE_DURATION", 2*time.Minute),
+        JobPollInterval:  getEnvDuration("JOB_POLL_INTERVAL", 2*time.Second),
+        MaxQueueDepth:    getEnvInt("MAX_QUEUE_DEPTH", 100),
+        QueueRetryAfter:  getEnvDuration("QUEUE_RETRY_AFTER", 30*time.Second),
+        JobTimeout:       getEnvDuration("JOB_TIMEOUT", 15*time.Minute),
+
+        JobMaxAttempts:    getEnvInt("JOB_MAX_ATTEMPTS", 3),
+        JobRetryBaseDelay: getEnvDuration("JOB_RETRY_BASE_DELAY", 30*time.Second),
+        JobRetryMaxDelay:  getEnvDuration("JOB_RETRY_MAX_DELAY", 10*time.Minute),
+
+        WebhookSecret:         getEnv("WEBHOOK_SECRET", ""),
+        WebhookDefaultURL:     getEnv("WEBHOOK_DEFAULT_URL", ""),
+        WebhookTimeout:        getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+        WebhookMaxAttempts:    getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+        WebhookRetryBaseDelay: getEnvDuration("WEBHOOK_RETRY_BASE_DELAY", 10*time.Second),
+        WebhookRetryMaxDelay:  getEnvDuration("WEBHOOK_RETRY_MAX_DELAY", 10*time.Minute),
+        WebhookAllowedHosts:   getEnvList("WEBHOOK_ALLOWED_HOSTS"),
+
+        LLMProvider: getEnv("LLM_PROVIDER", "ollama"),
+        LLMBaseURL:  getEnv("LLM_BASE_URL", ""),
+        LLMModel:    getEnv("LLM_MODEL", "gemma3:4b"),
+        LLMAPIKey:   getEnv("LLM_API_KEY", ""),
+
+        LLMMaxRepairAttempts: getEnvInt("LLM_MAX_REPAIR_ATTEMPTS", 2),
+    }
+
+    // Server OpenAI-compatible tidak punya port standar (vLLM default 8000 sama
+    // dengan CHROMA_URL), jadi alamatnya harus ditulis eksplisit
+    if config.LLMProvider == "openai" && config.LLMBaseURL == "" {
+        return nil, fmt.Errorf("LLM_BASE_URL is required when LLM_PROVIDER=openai (for example http://localhost:8001/v1)")
     }
 
     return config, nil
@@ -45,7 +177,74 @@ func getEnv(key, fallback string) string {
     return fallback
 }
 
+func getEnvInt(key string, fallback int) int {
+    if value := os.Getenv(key); value != "" {
+        if n, err := strconv.Atoi(value); err == nil {
+            return n
+        }
+    }
+    return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+    if value := os.Getenv(key); value != "" {
+        if b, err := strconv.ParseBool(value); err == nil {
+            return b
+        }
+    }
+    return fallback
+}
+
+// getEnvList membaca daftar dipisah koma, contoh "https://a.com,https://b.com"
+func getEnvList(key string) []string {
+    var values []string
+    for _, value := range strings.Split(os.Getenv(key), ",") {
+        if value = strings.TrimSpace(value); value != "" {
+            values = append(values, value)
+        }
+    }
+    return values
+}
+
+// getEnvDuration membaca durasi format Go, contoh "90s" atau "2m"
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+    if value := os.Getenv(key); value != "" {
+        if d, err := time.ParseDuration(value); err == nil {
+            return d
+        }
+    }
+    return fallback
+}
+
+func defaultDBPort(driver string) string {
+    if driver == DBDriverPostgres {
+        return "5432"
+    }
+    return "3306"
+}
+
+// GetDSN mengembalikan connection string sesuai DBDriver
 func (c *Config) GetDSN() string {
-    return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-        c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+    switch c.DBDriver {
+    case DBDriverPostgres:
+        return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+            c.DBHost, c.DBPort, c.DBUser, c.DBPassword, c.DBName, c.DBSSLMode)
+    case DBDriverSQLite:
+        // Foreign key aktif, WAL agar pembaca tidak memblokir penulis, dan
+        // transaksi langsung mengambil write lock supaya tidak deadlock saat upgrade
+        return c.DBPath + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+    default:
+        return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+            c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+    }
+}
+
+// GetLLMBaseURL mengembalikan base URL untuk provider LLM.
+// Jika LLM_BASE_URL kosong, provider ollama memakai OLLAMA_URL
+// (provider openai selalu butuh LLM_BASE_URL, lihat LoadConfig).
+func (c *Config) GetLLMBaseURL() string {
+    if c.LLMBaseURL != "" {
+        return c.LLMBaseURL
+    }
+    return c.OllamaURL
 }
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"cv-ai-evaluator/config"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// InitDB membuka koneksi database; caller bertanggung jawab menutupnya dengan CloseDB
+func InitDB(cfg *config.Config) (*gorm.DB, error) {
+    dialector, err := openDialector(cfg.DBDriver, cfg.GetDSN())
+    if err != nil {
+        return nil, err
+    }
+    
+    db, err := gorm.Open(dialector, &gorm.Config{
+        Logger: logger.Default.LogMode(logger.Info),
+        NowFunc: func() time.Time {
+            return time.Now().Local()
+        },
+    })
+
+    if err != nil {
+        return nil, fmt.Errorf("failed to connect to database: %w", err)
+    }
+
+    // Get underlying SQL DB untuk konfigurasi connection pool
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, fmt.Errorf("failed to get database instance: %w", err)
+    }
+
+    // Connection pool settings
+    sqlDB.SetMaxIdleConns(10)
+    sqlDB.SetMaxOpenConns(100)
+    sqlDB.SetConnMaxLifetime(time.Hour)
+
+    log.Printf("Database connected successfully! (driver: %s)", cfg.DBDriver)
+    return db, nil
+}
+
+// openDialector memilih dialect GORM sesuai DB_DRIVER. SQLite memakai driver
+// pure-Go sehingga tidak butuh cgo.
+func openDialector(driver, dsn string) (gorm.Dialector, error) {
+    switch driver {
+    case config.DBDriverMySQL:
+        return mysql.Open(dsn), nil
+    case config.DBDriverPostgres:
+        return postgres.Open(dsn), nil
+    case config.DBDriverSQLite:
+        return sqlite.Open(dsn), nil
+    default:
+        return nil, fmt.Errorf("unsupported DB_DRIVER %q (use mysql, postgres or sqlite)", driver)
+    }
+}
+
+func CloseDB(db *gorm.DB) error {
+    sqlDB, err := db.DB()
+    if err != nil {
+        return err
+    }
+    return sqlDB.Close()
+}
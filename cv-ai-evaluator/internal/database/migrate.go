@@ -0,0 +1,279 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Setiap driver punya direktori migration sendiri (migrations/<dialect>)
+// karena DDL-nya berbeda. Versi yang sama di tiap direktori harus menghasilkan
+// skema yang setara.
+//
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// Nama file: <versi>_<nama>.up.sql dan <versi>_<nama>.down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration adalah satu perubahan skema beserta rollback-nya
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus menunjukkan apakah sebuah migration sudah dijalankan
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// schemaMigration adalah baris di tabel schema_migrations
+type schemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// LoadMigrations membaca migration yang di-embed untuk dialect GORM tertentu
+// ("mysql", "postgres", "sqlite"), terurut berdasarkan versi
+func LoadMigrations(dialect string) ([]Migration, error) {
+	dir := "migrations/" + dialect
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database dialect %q: %w", dialect, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := migrationFiles.ReadFile(dir + "/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// MigrateUp menjalankan maksimal steps migration yang belum diterapkan
+// (steps <= 0 berarti semua). Mengembalikan jumlah migration yang dijalankan.
+func MigrateUp(db *gorm.DB, steps int) (int, error) {
+	statuses, err := MigrationStatuses(db)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, status := range statuses {
+		if status.Applied {
+			continue
+		}
+		if steps > 0 && applied >= steps {
+			break
+		}
+
+		log.Printf("Applying migration %04d_%s", status.Version, status.Name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execStatements(tx, status.Up); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{
+				Version:   status.Version,
+				Name:      status.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return applied, fmt.Errorf("migration %04d_%s failed: %w", status.Version, status.Name, err)
+		}
+		applied++
+	}
+
+	return applied, nil
+}
+
+// MigrateDown me-rollback steps migration terakhir (steps <= 0 berarti 1)
+func MigrateDown(db *gorm.DB, steps int) (int, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	statuses, err := MigrationStatuses(db)
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	for i := len(statuses) - 1; i >= 0 && reverted < steps; i-- {
+		status := statuses[i]
+		if !status.Applied {
+			continue
+		}
+
+		log.Printf("Reverting migration %04d_%s", status.Version, status.Name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := execStatements(tx, status.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, "version = ?", status.Version).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("rollback of %04d_%s failed: %w", status.Version, status.Name, err)
+		}
+		reverted++
+	}
+
+	return reverted, nil
+}
+
+// MigrationStatuses mengembalikan semua migration beserta status penerapannya
+func MigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var rows []schemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	appliedAt := make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		appliedAt[row.Version] = row.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Migration: m}
+		if at, ok := appliedAt[m.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// ForceVersion menandai semua migration sampai version sebagai sudah diterapkan
+// (dan yang lebih baru sebagai belum) tanpa menjalankan SQL-nya. Dipakai untuk
+// database yang skemanya dibuat manual, atau setelah memperbaiki migration
+// yang gagal di tengah jalan.
+func ForceVersion(db *gorm.DB, version int) error {
+	statuses, err := MigrationStatuses(db)
+	if err != nil {
+		return err
+	}
+
+	known := version == 0
+	for _, status := range statuses {
+		known = known || status.Version == version
+	}
+	if !known {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("version > ?", version).Delete(&schemaMigration{}).Error; err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			if status.Version > version || status.Applied {
+				continue
+			}
+			if err := tx.Create(&schemaMigration{
+				Version:   status.Version,
+				Name:      status.Name,
+				AppliedAt: time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// execStatements menjalankan isi file migration statement per statement, karena
+// driver MySQL (dan pgx dengan argumen) menolak beberapa statement dalam satu Exec
+func execStatements(tx *gorm.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements memecah script berdasarkan ';' di akhir baris dan membuang
+// baris komentar "--"
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
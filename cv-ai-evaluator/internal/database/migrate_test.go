@@ -0,0 +1,80 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"cv-ai-evaluator/config"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestOwnerOnlyBackfill checks that migration 0016 marks documents and jobs
+// created before migration 0011 as visible only to their API key, and leaves
+// records created since (shared within the organisation) alone
+func TestOwnerOnlyBackfill(t *testing.T) {
+	cfg := &config.Config{DBDriver: config.DBDriverSQLite, DBPath: filepath.Join(t.TempDir(), "test.db")}
+	db, err := gorm.Open(sqlite.Open(cfg.GetDSN()), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrations, err := LoadMigrations("sqlite")
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	steps := 0
+	for _, m := range migrations {
+		if m.Version < 16 {
+			steps++
+		}
+	}
+	if _, err := MigrateUp(db, steps); err != nil {
+		t.Fatalf("migrate to 0015: %v", err)
+	}
+	for _, stmt := range []string{
+		`INSERT INTO uploaded_documents (id, file_path, original_filename, document_type, uploaded_at, owner_key_id)
+		 VALUES ('doc-legacy', 'a.pdf', 'a.pdf', 'cv', '2020-01-01 00:00:00+00:00', 'key-1'),
+		        ('doc-anonymous', 'b.pdf', 'b.pdf', 'project_report', '2020-01-01 00:00:00+00:00', NULL),
+		        ('doc-shared', 'c.pdf', 'c.pdf', 'cv', '2999-01-01 00:00:00+00:00', 'key-1')`,
+		`INSERT INTO evaluation_jobs (id, cv_document_id, report_document_id, job_title_evaluated, status, created_at, owner_key_id)
+		 VALUES ('job-legacy', 'doc-legacy', 'doc-anonymous', 'Backend', 'completed', '2020-01-01 00:00:00+00:00', 'key-1'),
+		        ('job-shared', 'doc-shared', 'doc-anonymous', 'Backend', 'queued', '2999-01-01 00:00:00+00:00', 'key-1')`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("insert fixtures: %v", err)
+		}
+	}
+
+	if _, err := MigrateUp(db, 0); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	tests := []struct {
+		table string
+		id    string
+		want  bool
+	}{
+		{"uploaded_documents", "doc-legacy", true},
+		{"uploaded_documents", "doc-anonymous", false},
+		{"uploaded_documents", "doc-shared", false},
+		{"evaluation_jobs", "job-legacy", true},
+		{"evaluation_jobs", "job-shared", false},
+	}
+	for _, tt := range tests {
+		var ownerOnly bool
+		if err := db.Table(tt.table).Select("owner_only").Where("id = ?", tt.id).Scan(&ownerOnly).Error; err != nil {
+			t.Fatalf("read %s %s: %v", tt.table, tt.id, err)
+		}
+		if ownerOnly != tt.want {
+			t.Errorf("%s %s owner_only = %v, want %v", tt.table, tt.id, ownerOnly, tt.want)
+		}
+	}
+}
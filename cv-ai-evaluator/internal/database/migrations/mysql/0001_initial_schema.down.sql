@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS evaluation_jobs;
+DROP TABLE IF EXISTS ground_truth_documents;
+DROP TABLE IF EXISTS uploaded_documents;
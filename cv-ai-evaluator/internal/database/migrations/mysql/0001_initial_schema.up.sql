@@ -0,0 +1,40 @@
+-- Skema awal. IF NOT EXISTS agar database yang tabelnya dibuat manual
+-- (sesuai README lama) bisa langsung diadopsi oleh sistem migrasi.
+CREATE TABLE IF NOT EXISTS uploaded_documents (
+  id VARCHAR(36) PRIMARY KEY,
+  file_path VARCHAR(500) NOT NULL,
+  original_filename VARCHAR(255) NOT NULL,
+  document_type ENUM('cv','project_report') NOT NULL,
+  uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_document_type (document_type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS evaluation_jobs (
+  id VARCHAR(36) PRIMARY KEY,
+  cv_document_id VARCHAR(36) NOT NULL,
+  report_document_id VARCHAR(36) NOT NULL,
+  job_title_evaluated VARCHAR(255) NOT NULL,
+  status ENUM('queued','processing','completed','failed') DEFAULT 'queued',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  completed_at DATETIME NULL,
+  error_message TEXT NULL,
+  cv_match_rate DECIMAL(3,2) NULL,
+  cv_feedback TEXT NULL,
+  project_score DECIMAL(3,2) NULL,
+  project_feedback TEXT NULL,
+  overall_summary TEXT NULL,
+  INDEX idx_status (status),
+  INDEX idx_created_at (created_at),
+  FOREIGN KEY (cv_document_id) REFERENCES uploaded_documents(id),
+  FOREIGN KEY (report_document_id) REFERENCES uploaded_documents(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS ground_truth_documents (
+  id VARCHAR(36) PRIMARY KEY,
+  document_name VARCHAR(255) NOT NULL,
+  document_type ENUM('job_description','case_study_brief','cv_rubric','project_rubric') NOT NULL,
+  source_file_path VARCHAR(500) NOT NULL,
+  ingested_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  version VARCHAR(50) NULL,
+  INDEX idx_gt_document_type (document_type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
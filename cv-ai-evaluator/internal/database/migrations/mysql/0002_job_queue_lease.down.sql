@@ -0,0 +1,7 @@
+ALTER TABLE evaluation_jobs
+  DROP INDEX idx_status_created_at,
+  DROP INDEX idx_lease_expires_at,
+  DROP INDEX idx_lease_owner,
+  DROP COLUMN lease_expires_at,
+  DROP COLUMN lease_owner,
+  DROP COLUMN started_at;
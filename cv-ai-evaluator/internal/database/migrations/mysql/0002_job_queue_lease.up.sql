@@ -0,0 +1,8 @@
+-- Queue berbasis database: lease worker untuk crash recovery
+ALTER TABLE evaluation_jobs
+  ADD COLUMN started_at DATETIME NULL,
+  ADD COLUMN lease_owner VARCHAR(100) NULL,
+  ADD COLUMN lease_expires_at DATETIME NULL,
+  ADD INDEX idx_lease_owner (lease_owner),
+  ADD INDEX idx_lease_expires_at (lease_expires_at),
+  ADD INDEX idx_status_created_at (status, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE evaluation_jobs
+  DROP COLUMN project_score_breakdown,
+  DROP COLUMN cv_score_breakdown;
@@ -0,0 +1,4 @@
+-- Skor per parameter rubric (JSON); cv_match_rate & project_score dihitung server
+ALTER TABLE evaluation_jobs
+  ADD COLUMN cv_score_breakdown TEXT NULL,
+  ADD COLUMN project_score_breakdown TEXT NULL;
@@ -0,0 +1,4 @@
+UPDATE evaluation_jobs SET status = 'failed' WHERE status = 'cancelled';
+
+ALTER TABLE evaluation_jobs
+  MODIFY COLUMN status ENUM('queued','processing','completed','failed') DEFAULT 'queued';
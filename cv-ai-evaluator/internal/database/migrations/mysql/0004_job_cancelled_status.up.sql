@@ -0,0 +1,3 @@
+-- Status cancelled (POST /jobs/:id/cancel)
+ALTER TABLE evaluation_jobs
+  MODIFY COLUMN status ENUM('queued','processing','completed','failed','cancelled') DEFAULT 'queued';
@@ -0,0 +1,7 @@
+UPDATE evaluation_jobs SET status = 'failed' WHERE status = 'dead_letter';
+
+ALTER TABLE evaluation_jobs
+  DROP COLUMN next_attempt_at,
+  DROP COLUMN error_history,
+  DROP COLUMN attempts,
+  MODIFY COLUMN status ENUM('queued','processing','completed','failed','cancelled') DEFAULT 'queued';
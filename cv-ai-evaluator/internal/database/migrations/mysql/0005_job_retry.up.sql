@@ -0,0 +1,6 @@
+-- Retry otomatis: jumlah percobaan, riwayat error, jadwal retry, status dead_letter
+ALTER TABLE evaluation_jobs
+  MODIFY COLUMN status ENUM('queued','processing','completed','failed','cancelled','dead_letter') DEFAULT 'queued',
+  ADD COLUMN attempts INT NOT NULL DEFAULT 0,
+  ADD COLUMN error_history TEXT NULL,
+  ADD COLUMN next_attempt_at DATETIME NULL;
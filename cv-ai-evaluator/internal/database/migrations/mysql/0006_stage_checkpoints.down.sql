@@ -0,0 +1,7 @@
+ALTER TABLE evaluation_jobs
+  DROP COLUMN summarized_at,
+  DROP COLUMN project_evaluated_at,
+  DROP COLUMN cv_evaluated_at,
+  DROP COLUMN text_extracted_at,
+  DROP COLUMN report_text,
+  DROP COLUMN cv_text;
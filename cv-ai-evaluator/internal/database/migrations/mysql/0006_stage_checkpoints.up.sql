@@ -0,0 +1,8 @@
+-- Checkpoint per stage: retry/recovery melanjutkan dari stage terakhir yang selesai
+ALTER TABLE evaluation_jobs
+  ADD COLUMN cv_text LONGTEXT NULL,
+  ADD COLUMN report_text LONGTEXT NULL,
+  ADD COLUMN text_extracted_at DATETIME NULL,
+  ADD COLUMN cv_evaluated_at DATETIME NULL,
+  ADD COLUMN project_evaluated_at DATETIME NULL,
+  ADD COLUMN summarized_at DATETIME NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE evaluation_jobs
+  DROP COLUMN stage_timeline,
+  DROP COLUMN current_stage;
@@ -0,0 +1,4 @@
+-- Progress: stage yang sedang berjalan dan timeline per stage
+ALTER TABLE evaluation_jobs
+  ADD COLUMN current_stage VARCHAR(32) NULL,
+  ADD COLUMN stage_timeline TEXT NULL;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+
+ALTER TABLE evaluation_jobs
+  DROP INDEX idx_webhook_status,
+  DROP COLUMN webhook_next_attempt_at,
+  DROP COLUMN webhook_attempts,
+  DROP COLUMN webhook_status,
+  DROP COLUMN callback_url;
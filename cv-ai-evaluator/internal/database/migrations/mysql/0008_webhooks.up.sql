@@ -0,0 +1,24 @@
+-- Webhook callback: URL tujuan dan status pengiriman
+ALTER TABLE evaluation_jobs
+  ADD COLUMN callback_url VARCHAR(2048) NULL,
+  ADD COLUMN webhook_status VARCHAR(20) NULL,
+  ADD COLUMN webhook_attempts INT NOT NULL DEFAULT 0,
+  ADD COLUMN webhook_next_attempt_at DATETIME NULL,
+  ADD INDEX idx_webhook_status (webhook_status);
+
+-- Riwayat setiap percobaan pengiriman webhook
+CREATE TABLE webhook_deliveries (
+  id VARCHAR(36) PRIMARY KEY,
+  job_id VARCHAR(36) NOT NULL,
+  url VARCHAR(2048) NOT NULL,
+  event VARCHAR(50) NOT NULL,
+  attempt INT NOT NULL,
+  status_code INT NULL,
+  success BOOLEAN NOT NULL DEFAULT FALSE,
+  error TEXT NULL,
+  response_body TEXT NULL,
+  duration_ms BIGINT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_webhook_job_id (job_id),
+  FOREIGN KEY (job_id) REFERENCES evaluation_jobs(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,8 @@
+ALTER TABLE ground_truth_documents
+  MODIFY COLUMN document_type ENUM('job_description','case_study_brief','cv_rubric','project_rubric') NOT NULL;
+
+ALTER TABLE evaluation_jobs
+  MODIFY COLUMN status ENUM('queued','processing','completed','failed','cancelled','dead_letter') DEFAULT 'queued';
+
+ALTER TABLE uploaded_documents
+  MODIFY COLUMN document_type ENUM('cv','project_report') NOT NULL;
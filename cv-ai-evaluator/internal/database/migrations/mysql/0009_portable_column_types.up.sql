@@ -0,0 +1,11 @@
+-- ENUM diganti VARCHAR agar skema sama di MySQL, PostgreSQL dan SQLite.
+-- Nilai yang valid dijaga oleh aplikasi (konstanta di package models),
+-- jadi status baru tidak lagi butuh ALTER TABLE.
+ALTER TABLE uploaded_documents
+  MODIFY COLUMN document_type VARCHAR(20) NOT NULL;
+
+ALTER TABLE evaluation_jobs
+  MODIFY COLUMN status VARCHAR(20) NOT NULL DEFAULT 'queued';
+
+ALTER TABLE ground_truth_documents
+  MODIFY COLUMN document_type VARCHAR(32) NOT NULL;
@@ -0,0 +1,9 @@
+ALTER TABLE evaluation_jobs
+  DROP INDEX idx_evaluation_jobs_owner_key_id,
+  DROP COLUMN owner_key_id;
+
+ALTER TABLE uploaded_documents
+  DROP INDEX idx_uploaded_documents_owner_key_id,
+  DROP COLUMN owner_key_id;
+
+DROP TABLE IF EXISTS api_keys;
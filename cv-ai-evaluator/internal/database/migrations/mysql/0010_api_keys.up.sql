@@ -0,0 +1,19 @@
+-- API key (hanya hash yang disimpan) dan pemilik dokumen/job
+CREATE TABLE api_keys (
+  id VARCHAR(36) PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  prefix VARCHAR(16) NOT NULL,
+  key_hash VARCHAR(64) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  last_used_at DATETIME NULL,
+  revoked_at DATETIME NULL,
+  UNIQUE INDEX idx_api_keys_key_hash (key_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE uploaded_documents
+  ADD COLUMN owner_key_id VARCHAR(36) NULL,
+  ADD INDEX idx_uploaded_documents_owner_key_id (owner_key_id);
+
+ALTER TABLE evaluation_jobs
+  ADD COLUMN owner_key_id VARCHAR(36) NULL,
+  ADD INDEX idx_evaluation_jobs_owner_key_id (owner_key_id);
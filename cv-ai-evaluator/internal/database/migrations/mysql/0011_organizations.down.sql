@@ -0,0 +1,17 @@
+ALTER TABLE ground_truth_documents
+  DROP INDEX idx_ground_truth_documents_organization_id,
+  DROP COLUMN organization_id;
+
+ALTER TABLE evaluation_jobs
+  DROP INDEX idx_evaluation_jobs_organization_id,
+  DROP COLUMN organization_id;
+
+ALTER TABLE uploaded_documents
+  DROP INDEX idx_uploaded_documents_organization_id,
+  DROP COLUMN organization_id;
+
+ALTER TABLE api_keys
+  DROP INDEX idx_api_keys_organization_id,
+  DROP COLUMN organization_id;
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,25 @@
+-- Organisasi (tenant). Data yang sudah ada masuk ke organisasi "default".
+CREATE TABLE organizations (
+  id VARCHAR(36) PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  callback_url VARCHAR(2048) NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO organizations (id, name) VALUES ('default', 'Default');
+
+ALTER TABLE api_keys
+  ADD COLUMN organization_id VARCHAR(36) NOT NULL DEFAULT 'default',
+  ADD INDEX idx_api_keys_organization_id (organization_id);
+
+ALTER TABLE uploaded_documents
+  ADD COLUMN organization_id VARCHAR(36) NOT NULL DEFAULT 'default',
+  ADD INDEX idx_uploaded_documents_organization_id (organization_id);
+
+ALTER TABLE evaluation_jobs
+  ADD COLUMN organization_id VARCHAR(36) NOT NULL DEFAULT 'default',
+  ADD INDEX idx_evaluation_jobs_organization_id (organization_id);
+
+ALTER TABLE ground_truth_documents
+  ADD COLUMN organization_id VARCHAR(36) NOT NULL DEFAULT 'default',
+  ADD INDEX idx_ground_truth_documents_organization_id (organization_id);
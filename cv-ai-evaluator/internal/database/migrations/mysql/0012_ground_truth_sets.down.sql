@@ -0,0 +1,6 @@
+ALTER TABLE evaluation_jobs
+  DROP COLUMN ground_truth_refs;
+
+ALTER TABLE ground_truth_documents
+  DROP INDEX idx_ground_truth_documents_org_type,
+  DROP COLUMN role;
@@ -0,0 +1,7 @@
+-- Role per dokumen ground truth dan dokumen yang dipakai setiap job
+ALTER TABLE ground_truth_documents
+  ADD COLUMN role VARCHAR(100) NOT NULL DEFAULT '',
+  ADD INDEX idx_ground_truth_documents_org_type (organization_id, document_type);
+
+ALTER TABLE evaluation_jobs
+  ADD COLUMN ground_truth_refs TEXT NULL;
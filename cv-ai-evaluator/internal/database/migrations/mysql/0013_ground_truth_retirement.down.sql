@@ -0,0 +1,2 @@
+ALTER TABLE ground_truth_documents
+  DROP COLUMN retired_at;
@@ -0,0 +1,3 @@
+-- Ground truth yang di-retire lewat DELETE /ground-truth/:id
+ALTER TABLE ground_truth_documents
+  ADD COLUMN retired_at DATETIME NULL;
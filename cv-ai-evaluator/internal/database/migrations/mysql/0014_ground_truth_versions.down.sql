@@ -0,0 +1,9 @@
+ALTER TABLE evaluation_jobs
+  DROP INDEX idx_evaluation_jobs_rerun_of_job_id,
+  DROP COLUMN rerun_of_job_id,
+  DROP COLUMN retrieved_chunks;
+
+ALTER TABLE ground_truth_documents
+  DROP INDEX idx_ground_truth_documents_lineage_version,
+  DROP COLUMN content,
+  DROP COLUMN lineage_id;
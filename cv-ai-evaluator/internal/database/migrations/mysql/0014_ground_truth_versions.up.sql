@@ -0,0 +1,15 @@
+-- Versi ground truth: versi satu dokumen berbagi lineage_id, isi yang di-index
+-- disimpan per versi, dan job mencatat potongan ground truth yang dipakai
+ALTER TABLE ground_truth_documents
+  ADD COLUMN lineage_id VARCHAR(36) NOT NULL DEFAULT '',
+  ADD COLUMN content LONGTEXT NULL;
+
+UPDATE ground_truth_documents SET lineage_id = id;
+
+ALTER TABLE ground_truth_documents
+  ADD UNIQUE INDEX idx_ground_truth_documents_lineage_version (lineage_id, version);
+
+ALTER TABLE evaluation_jobs
+  ADD COLUMN retrieved_chunks LONGTEXT NULL,
+  ADD COLUMN rerun_of_job_id VARCHAR(36) NULL,
+  ADD INDEX idx_evaluation_jobs_rerun_of_job_id (rerun_of_job_id);
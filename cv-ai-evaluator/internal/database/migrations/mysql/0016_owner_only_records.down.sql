@@ -0,0 +1,5 @@
+ALTER TABLE evaluation_jobs
+  DROP COLUMN owner_only;
+
+ALTER TABLE uploaded_documents
+  DROP COLUMN owner_only;
@@ -0,0 +1,4 @@
+-- Secret HMAC webhook per organisasi, supaya penerima satu tenant tidak bisa
+-- memalsukan callback tenant lain. NULL berarti callback ditandatangani
+-- dengan WEBHOOK_SECRET global (organisasi yang dibuat sebelum migration ini).
+ALTER TABLE organizations ADD COLUMN webhook_secret VARCHAR(64) NULL;
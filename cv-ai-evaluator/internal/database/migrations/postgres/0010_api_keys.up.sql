@@ -0,0 +1,18 @@
+-- API key (hanya hash yang disimpan) dan pemilik dokumen/job
+CREATE TABLE api_keys (
+  id VARCHAR(36) PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  prefix VARCHAR(16) NOT NULL,
+  key_hash VARCHAR(64) NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+  last_used_at TIMESTAMPTZ NULL,
+  revoked_at TIMESTAMPTZ NULL
+);
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash);
+
+ALTER TABLE uploaded_documents ADD COLUMN owner_key_id VARCHAR(36) NULL;
+CREATE INDEX idx_uploaded_documents_owner_key_id ON uploaded_documents (owner_key_id);
+
+ALTER TABLE evaluation_jobs ADD COLUMN owner_key_id VARCHAR(36) NULL;
+CREATE INDEX idx_evaluation_jobs_owner_key_id ON evaluation_jobs (owner_key_id);
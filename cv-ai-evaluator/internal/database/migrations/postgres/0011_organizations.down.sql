@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_ground_truth_documents_organization_id;
+ALTER TABLE ground_truth_documents DROP COLUMN organization_id;
+
+DROP INDEX IF EXISTS idx_evaluation_jobs_organization_id;
+ALTER TABLE evaluation_jobs DROP COLUMN organization_id;
+
+DROP INDEX IF EXISTS idx_uploaded_documents_organization_id;
+ALTER TABLE uploaded_documents DROP COLUMN organization_id;
+
+DROP INDEX IF EXISTS idx_api_keys_organization_id;
+ALTER TABLE api_keys DROP COLUMN organization_id;
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,4 @@
+ALTER TABLE evaluation_jobs DROP COLUMN ground_truth_refs;
+
+DROP INDEX IF EXISTS idx_ground_truth_documents_org_type;
+ALTER TABLE ground_truth_documents DROP COLUMN role;
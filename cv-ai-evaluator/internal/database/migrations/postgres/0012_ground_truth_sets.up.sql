@@ -0,0 +1,5 @@
+-- Role per dokumen ground truth dan dokumen yang dipakai setiap job
+ALTER TABLE ground_truth_documents ADD COLUMN role VARCHAR(100) NOT NULL DEFAULT '';
+CREATE INDEX idx_ground_truth_documents_org_type ON ground_truth_documents (organization_id, document_type);
+
+ALTER TABLE evaluation_jobs ADD COLUMN ground_truth_refs TEXT NULL;
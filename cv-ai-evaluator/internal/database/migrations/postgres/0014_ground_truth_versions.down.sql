@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_evaluation_jobs_rerun_of_job_id;
+ALTER TABLE evaluation_jobs DROP COLUMN rerun_of_job_id;
+ALTER TABLE evaluation_jobs DROP COLUMN retrieved_chunks;
+
+DROP INDEX IF EXISTS idx_ground_truth_documents_lineage_version;
+ALTER TABLE ground_truth_documents DROP COLUMN content;
+ALTER TABLE ground_truth_documents DROP COLUMN lineage_id;
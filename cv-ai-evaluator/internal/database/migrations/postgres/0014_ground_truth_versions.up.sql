@@ -0,0 +1,12 @@
+-- Versi ground truth: versi satu dokumen berbagi lineage_id, isi yang di-index
+-- disimpan per versi, dan job mencatat potongan ground truth yang dipakai
+ALTER TABLE ground_truth_documents ADD COLUMN lineage_id VARCHAR(36) NOT NULL DEFAULT '';
+ALTER TABLE ground_truth_documents ADD COLUMN content TEXT NULL;
+
+UPDATE ground_truth_documents SET lineage_id = id;
+
+CREATE UNIQUE INDEX idx_ground_truth_documents_lineage_version ON ground_truth_documents (lineage_id, version);
+
+ALTER TABLE evaluation_jobs ADD COLUMN retrieved_chunks TEXT NULL;
+ALTER TABLE evaluation_jobs ADD COLUMN rerun_of_job_id VARCHAR(36) NULL;
+CREATE INDEX idx_evaluation_jobs_rerun_of_job_id ON evaluation_jobs (rerun_of_job_id);
@@ -0,0 +1,2 @@
+ALTER TABLE evaluation_jobs DROP COLUMN owner_only;
+ALTER TABLE uploaded_documents DROP COLUMN owner_only;
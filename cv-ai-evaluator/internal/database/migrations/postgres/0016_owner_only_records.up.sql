@@ -0,0 +1,13 @@
+-- Dokumen dan job yang dibuat sebelum organisasi ada (migration 0011) dulu hanya
+-- terlihat oleh API key pembuatnya. 0011 memasukkan semuanya ke organisasi
+-- "default"; owner_only menjaga isolasi per key untuk data lama tersebut.
+ALTER TABLE uploaded_documents ADD COLUMN owner_only BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE evaluation_jobs ADD COLUMN owner_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE uploaded_documents SET owner_only = TRUE
+WHERE owner_key_id IS NOT NULL AND organization_id = 'default'
+  AND uploaded_at < (SELECT created_at FROM organizations WHERE id = 'default');
+
+UPDATE evaluation_jobs SET owner_only = TRUE
+WHERE owner_key_id IS NOT NULL AND organization_id = 'default'
+  AND created_at < (SELECT created_at FROM organizations WHERE id = 'default');
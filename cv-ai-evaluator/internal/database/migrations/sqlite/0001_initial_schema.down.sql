@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS evaluation_jobs;
+DROP TABLE IF EXISTS ground_truth_documents;
+DROP TABLE IF EXISTS uploaded_documents;
@@ -0,0 +1,81 @@
+-- Skema SQLite setara dengan migrations/mysql sampai versi 0009.
+-- Migration berikutnya memakai nomor versi yang sama di semua driver.
+CREATE TABLE IF NOT EXISTS uploaded_documents (
+  id VARCHAR(36) PRIMARY KEY,
+  file_path VARCHAR(500) NOT NULL,
+  original_filename VARCHAR(255) NOT NULL,
+  document_type VARCHAR(20) NOT NULL,
+  uploaded_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_uploaded_documents_type ON uploaded_documents (document_type);
+
+CREATE TABLE IF NOT EXISTS evaluation_jobs (
+  id VARCHAR(36) PRIMARY KEY,
+  cv_document_id VARCHAR(36) NOT NULL REFERENCES uploaded_documents(id),
+  report_document_id VARCHAR(36) NOT NULL REFERENCES uploaded_documents(id),
+  job_title_evaluated VARCHAR(255) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'queued',
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  completed_at DATETIME NULL,
+  error_message TEXT NULL,
+  cv_match_rate DECIMAL(3,2) NULL,
+  cv_feedback TEXT NULL,
+  project_score DECIMAL(3,2) NULL,
+  project_feedback TEXT NULL,
+  overall_summary TEXT NULL,
+  cv_score_breakdown TEXT NULL,
+  project_score_breakdown TEXT NULL,
+  started_at DATETIME NULL,
+  lease_owner VARCHAR(100) NULL,
+  lease_expires_at DATETIME NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  error_history TEXT NULL,
+  next_attempt_at DATETIME NULL,
+  cv_text TEXT NULL,
+  report_text TEXT NULL,
+  text_extracted_at DATETIME NULL,
+  cv_evaluated_at DATETIME NULL,
+  project_evaluated_at DATETIME NULL,
+  summarized_at DATETIME NULL,
+  current_stage VARCHAR(32) NULL,
+  stage_timeline TEXT NULL,
+  callback_url VARCHAR(2048) NULL,
+  webhook_status VARCHAR(20) NULL,
+  webhook_attempts INTEGER NOT NULL DEFAULT 0,
+  webhook_next_attempt_at DATETIME NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_evaluation_jobs_status ON evaluation_jobs (status);
+CREATE INDEX IF NOT EXISTS idx_evaluation_jobs_created_at ON evaluation_jobs (created_at);
+CREATE INDEX IF NOT EXISTS idx_evaluation_jobs_status_created_at ON evaluation_jobs (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_evaluation_jobs_lease_owner ON evaluation_jobs (lease_owner);
+CREATE INDEX IF NOT EXISTS idx_evaluation_jobs_lease_expires_at ON evaluation_jobs (lease_expires_at);
+CREATE INDEX IF NOT EXISTS idx_evaluation_jobs_webhook_status ON evaluation_jobs (webhook_status);
+
+CREATE TABLE IF NOT EXISTS ground_truth_documents (
+  id VARCHAR(36) PRIMARY KEY,
+  document_name VARCHAR(255) NOT NULL,
+  document_type VARCHAR(32) NOT NULL,
+  source_file_path VARCHAR(500) NOT NULL,
+  ingested_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  version VARCHAR(50) NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ground_truth_documents_type ON ground_truth_documents (document_type);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id VARCHAR(36) PRIMARY KEY,
+  job_id VARCHAR(36) NOT NULL REFERENCES evaluation_jobs(id) ON DELETE CASCADE,
+  url VARCHAR(2048) NOT NULL,
+  event VARCHAR(50) NOT NULL,
+  attempt INTEGER NOT NULL,
+  status_code INTEGER NULL,
+  success BOOLEAN NOT NULL DEFAULT FALSE,
+  error TEXT NULL,
+  response_body TEXT NULL,
+  duration_ms BIGINT NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_job_id ON webhook_deliveries (job_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_evaluation_jobs_owner_key_id;
+ALTER TABLE evaluation_jobs DROP COLUMN owner_key_id;
+
+DROP INDEX IF EXISTS idx_uploaded_documents_owner_key_id;
+ALTER TABLE uploaded_documents DROP COLUMN owner_key_id;
+
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,21 @@
+-- Organisasi (tenant). Data yang sudah ada masuk ke organisasi "default".
+CREATE TABLE organizations (
+  id VARCHAR(36) PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  callback_url VARCHAR(2048) NULL,
+  created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO organizations (id, name) VALUES ('default', 'Default');
+
+ALTER TABLE api_keys ADD COLUMN organization_id VARCHAR(36) NOT NULL DEFAULT 'default';
+CREATE INDEX idx_api_keys_organization_id ON api_keys (organization_id);
+
+ALTER TABLE uploaded_documents ADD COLUMN organization_id VARCHAR(36) NOT NULL DEFAULT 'default';
+CREATE INDEX idx_uploaded_documents_organization_id ON uploaded_documents (organization_id);
+
+ALTER TABLE evaluation_jobs ADD COLUMN organization_id VARCHAR(36) NOT NULL DEFAULT 'default';
+CREATE INDEX idx_evaluation_jobs_organization_id ON evaluation_jobs (organization_id);
+
+ALTER TABLE ground_truth_documents ADD COLUMN organization_id VARCHAR(36) NOT NULL DEFAULT 'default';
+CREATE INDEX idx_ground_truth_documents_organization_id ON ground_truth_documents (organization_id);
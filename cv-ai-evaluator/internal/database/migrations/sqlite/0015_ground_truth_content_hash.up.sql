@@ -0,0 +1,2 @@
+-- Hash SHA-256 file sumber ground truth; ingestion melewati file yang tidak berubah
+ALTER TABLE ground_truth_documents ADD COLUMN content_hash VARCHAR(64) NOT NULL DEFAULT '';
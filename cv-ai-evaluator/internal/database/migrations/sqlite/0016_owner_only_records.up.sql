@@ -0,0 +1,14 @@
+-- Dokumen dan job yang dibuat sebelum organisasi ada (migration 0011) dulu hanya
+-- terlihat oleh API key pembuatnya. 0011 memasukkan semuanya ke organisasi
+-- "default"; owner_only menjaga isolasi per key untuk data lama tersebut.
+-- datetime() menyamakan format waktu dari GORM (dengan zona) dan CURRENT_TIMESTAMP.
+ALTER TABLE uploaded_documents ADD COLUMN owner_only BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE evaluation_jobs ADD COLUMN owner_only BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE uploaded_documents SET owner_only = TRUE
+WHERE owner_key_id IS NOT NULL AND organization_id = 'default'
+  AND datetime(uploaded_at) < (SELECT datetime(created_at) FROM organizations WHERE id = 'default');
+
+UPDATE evaluation_jobs SET owner_only = TRUE
+WHERE owner_key_id IS NOT NULL AND organization_id = 'default'
+  AND datetime(created_at) < (SELECT datetime(created_at) FROM organizations WHERE id = 'default');
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gin context key holding the authenticated *models.APIKey
+const apiKeyContextKey = "api_key"
+
+// RequireAPIKey rejects requests without a valid API key, sent either as
+// "Authorization: Bearer <key>" or "X-API-Key: <key>"
+func RequireAPIKey(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plaintext := apiKeyFromRequest(c.Request)
+		if plaintext == "" {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+			return
+		}
+
+		key, err := apiKeyService.Authenticate(plaintext)
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			log.Printf("Error authenticating API key: %v", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate request"})
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, ok := strings.Cut(auth, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// requestScope returns the data scope of the calling client's organisation.
+// Without the authentication middleware (AUTH_ENABLED=false) the scope is
+// unrestricted.
+func requestScope(c *gin.Context) services.Scope {
+	if value, ok := c.Get(apiKeyContextKey); ok {
+		if key, ok := value.(*models.APIKey); ok {
+			return services.Scope{OrganizationID: key.OrganizationID, APIKeyID: key.ID}
+		}
+	}
+	return services.Scope{}
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
 
 	"cv-ai-evaluator/internal/services"
 	"cv-ai-evaluator/internal/worker"
@@ -10,27 +13,50 @@ import (
 )
 
 type EvaluateHandler struct {
-	workerPool        *worker.WorkerPool
-	documentService   *services.DocumentService
-	evaluationService *services.EvaluationService
+	workerPool          *worker.WorkerPool
+	documentService     *services.DocumentService
+	evaluationService   *services.EvaluationService
+	groundTruthService  *services.GroundTruthService
+	organizationService *services.OrganizationService
+
+	// Used when neither the request nor the caller's organisation specify a callback URL
+	defaultCallbackURL string
+	// Which callback URLs the server may call
+	callbackPolicy *services.CallbackURLPolicy
+	// Signs callbacks of organisations without their own webhook secret
+	webhookSecret string
 }
 
 func NewEvaluateHandler(
 	workerPool *worker.WorkerPool,
 	documentService *services.DocumentService,
 	evaluationService *services.EvaluationService,
+	groundTruthService *services.GroundTruthService,
+	organizationService *services.OrganizationService,
+	defaultCallbackURL string,
+	callbackPolicy *services.CallbackURLPolicy,
+	webhookSecret string,
 ) *EvaluateHandler {
 	return &EvaluateHandler{
-		workerPool:        workerPool,
-		documentService:   documentService,
-		evaluationService: evaluationService,
+		workerPool:          workerPool,
+		documentService:     documentService,
+		evaluationService:   evaluationService,
+		groundTruthService:  groundTruthService,
+		organizationService: organizationService,
+		defaultCallbackURL:  defaultCallbackURL,
+		callbackPolicy:      callbackPolicy,
+		webhookSecret:       webhookSecret,
 	}
 }
 
 type EvaluateRequest struct {
-	JobTitle string `json:"job_title" binding:"required"`
-	CVId     string `json:"cv_id" binding:"required"`
-	ReportId string `json:"report_id" binding:"required"`
+	JobTitle    string `json:"job_title" binding:"required"`
+	CVId        string `json:"cv_id" binding:"required"`
+	ReportId    string `json:"report_id" binding:"required"`
+	CallbackURL string `json:"callback_url" binding:"omitempty,url"`
+	// Optional ground truth job description; without it job_title is resolved
+	// to one of the organization's job descriptions
+	JobDescriptionID string `json:"job_description_id"`
 }
 
 type EvaluateResponse struct {
@@ -38,6 +64,12 @@ type EvaluateResponse struct {
 	Status string `json:"status"`
 }
 
+type RerunResponse struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	RerunOf string `json:"rerun_of"`
+}
+
 func (h *EvaluateHandler) Evaluate(c *gin.Context) {
 	var req EvaluateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -45,24 +77,143 @@ func (h *EvaluateHandler) Evaluate(c *gin.Context) {
 		return
 	}
 
-	// Validate documents exist using service
-	if err := h.documentService.ValidateDocumentsExist(req.CVId, req.ReportId); err != nil {
+	scope := requestScope(c)
+
+	// Validate documents exist and belong to the caller
+	if err := h.documentService.ValidateDocumentsExist(req.CVId, req.ReportId, scope); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Pin the job description and rubrics the job is evaluated against
+	groundTruth, err := h.groundTruthService.ResolveSet(req.JobTitle, req.JobDescriptionID, scope)
+	switch {
+	case errors.Is(err, services.ErrGroundTruthNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
+	case errors.Is(err, services.ErrJobDescriptionUnresolved):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		log.Printf("Error resolving ground truth: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve ground truth"})
+		return
+	}
+
+	// Callback URL: request, then the organisation's default, then the global default
+	callbackURL := req.CallbackURL
+	if callbackURL == "" {
+		orgCallbackURL, err := h.organizationService.DefaultCallbackURL(scope)
+		if err != nil {
+			log.Printf("Error loading organization callback URL: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve callback URL"})
+			return
+		}
+		callbackURL = orgCallbackURL
+	}
+	if callbackURL == "" {
+		callbackURL = h.defaultCallbackURL
+	}
+	if callbackURL != "" {
+		// Receivers verify the signature, so an unsigned callback is never sent
+		canSign, err := h.organizationService.CanSignCallbacks(scope, h.webhookSecret)
+		if err != nil {
+			log.Printf("Error loading organization webhook secret: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve callback URL"})
+			return
+		}
+		if !canSign {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "callback_url is not available: neither the organization nor the server (WEBHOOK_SECRET) has a webhook secret to sign callbacks"})
+			return
+		}
+		if err := h.callbackPolicy.Validate(c.Request.Context(), callbackURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid callback_url: " + err.Error()})
+			return
+		}
 	}
 
 	// Create evaluation job using service
-	job, err := h.evaluationService.CreateEvaluationJob(req.CVId, req.ReportId, req.JobTitle)
+	job, err := h.evaluationService.CreateEvaluationJob(services.NewJob{
+		CVDocumentID:     req.CVId,
+		ReportDocumentID: req.ReportId,
+		JobTitle:         req.JobTitle,
+		CallbackURL:      callbackURL,
+		GroundTruth:      groundTruth,
+	}, scope)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Submit job to worker pool
-	h.workerPool.SubmitJob(job.ID)
+	if !h.submit(c, job.ID) {
+		return
+	}
 
 	c.JSON(http.StatusOK, EvaluateResponse{
 		ID:     job.ID,
 		Status: string(job.Status),
 	})
 }
+
+// Rerun handles POST /jobs/:id/rerun: a new job replays a finished job against
+// the same pinned ground truth versions, for audits and disputed results
+func (h *EvaluateHandler) Rerun(c *gin.Context) {
+	job, err := h.evaluationService.RerunJob(c.Param("id"), requestScope(c))
+	switch {
+	case errors.Is(err, services.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	case errors.Is(err, services.ErrJobNotReplayable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !h.submit(c, job.ID) {
+		return
+	}
+
+	c.JSON(http.StatusOK, RerunResponse{
+		ID:      job.ID,
+		Status:  string(job.Status),
+		RerunOf: job.RerunOfJobID.String,
+	})
+}
+
+// submit hands a stored job to the worker pool. When the pool rejects it the
+// job is withdrawn so the client can safely retry later, the error response is
+// written and false returned. If a worker already claimed the job in the
+// meantime, it was accepted after all.
+func (h *EvaluateHandler) submit(c *gin.Context, jobID string) bool {
+	err := h.workerPool.SubmitJob(jobID)
+	if err == nil {
+		return true
+	}
+	deleted, delErr := h.evaluationService.DeleteQueuedJob(jobID)
+	if delErr != nil {
+		log.Printf("Warning: failed to withdraw rejected job %s: %v", jobID, delErr)
+	}
+	if deleted {
+		h.respondSubmitError(c, err)
+		return false
+	}
+	return true
+}
+
+// respondSubmitError maps worker pool rejections to 429/503 with Retry-After
+func (h *EvaluateHandler) respondSubmitError(c *gin.Context, err error) {
+	retryAfter := strconv.Itoa(int(h.workerPool.RetryAfter().Seconds()))
+
+	switch {
+	case errors.Is(err, worker.ErrQueueFull):
+		c.Header("Retry-After", retryAfter)
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, worker.ErrPoolStopped):
+		c.Header("Retry-After", retryAfter)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroundTruthHandler struct {
+	groundTruthService *services.GroundTruthService
+}
+
+func NewGroundTruthHandler(groundTruthService *services.GroundTruthService) *GroundTruthHandler {
+	return &GroundTruthHandler{groundTruthService: groundTruthService}
+}
+
+// GroundTruthDocumentResponse is the API representation of one version of a
+// ground truth document. Content, the text indexed for retrieval, is only set
+// on GET by ID.
+type GroundTruthDocumentResponse struct {
+	ID             string                 `json:"id"`
+	LineageID      string                 `json:"lineage_id"`
+	DocumentName   string                 `json:"document_name"`
+	DocumentType   models.GroundTruthType `json:"document_type"`
+	Role           string                 `json:"role"`
+	Version        string                 `json:"version"`
+	SourceFilename string                 `json:"source_filename"`
+	Active         bool                   `json:"active"`
+	IngestedAt     time.Time              `json:"ingested_at"`
+	RetiredAt      *time.Time             `json:"retired_at,omitempty"`
+	Content        string                 `json:"content,omitempty"`
+}
+
+type GroundTruthListResponse struct {
+	Data []GroundTruthDocumentResponse `json:"data"`
+}
+
+// Create handles POST /ground-truth: a multipart upload with fields file,
+// document_type, and optionally document_name, role and version. Uploading
+// an active document's type, role and name again adds a version of it.
+func (h *GroundTruthHandler) Create(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Form field 'file' is required"})
+		return
+	}
+	gtType, err := services.ParseGroundTruthType(c.PostForm("document_type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	doc, err := h.groundTruthService.CreateDocument(c.Request.Context(), services.GroundTruthUpload{
+		File:         file,
+		DocumentType: gtType,
+		DocumentName: c.PostForm("document_name"),
+		Role:         c.PostForm("role"),
+		Version:      c.PostForm("version"),
+	}, requestScope(c))
+	if err != nil {
+		respondGroundTruthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newGroundTruthDocumentResponse(doc))
+}
+
+// List handles GET /ground-truth. Retired documents are left out unless
+// include_retired=true.
+func (h *GroundTruthHandler) List(c *gin.Context) {
+	var filter services.GroundTruthFilter
+	if raw := c.Query("document_type"); raw != "" {
+		gtType, err := services.ParseGroundTruthType(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.DocumentType = gtType
+	}
+	filter.Role = c.Query("role")
+	if raw := c.Query("include_retired"); raw != "" {
+		includeRetired, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "include_retired must be true or false"})
+			return
+		}
+		filter.IncludeRetired = includeRetired
+	}
+
+	docs, err := h.groundTruthService.ListDocuments(filter, requestScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	data := make([]GroundTruthDocumentResponse, 0, len(docs))
+	for i := range docs {
+		data = append(data, newGroundTruthDocumentResponse(&docs[i]))
+	}
+
+	c.JSON(http.StatusOK, GroundTruthListResponse{Data: data})
+}
+
+// Get handles GET /ground-truth/:id
+func (h *GroundTruthHandler) Get(c *gin.Context) {
+	doc, err := h.groundTruthService.GetDocument(c.Param("id"), requestScope(c))
+	if err != nil {
+		respondGroundTruthError(c, err)
+		return
+	}
+
+	content, err := h.groundTruthService.GetDocumentContent(c.Request.Context(), doc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := newGroundTruthDocumentResponse(doc)
+	response.Content = content
+	c.JSON(http.StatusOK, response)
+}
+
+// Versions handles GET /ground-truth/:id/versions, listing every version of
+// the document, oldest first; any version's ID can be used
+func (h *GroundTruthHandler) Versions(c *gin.Context) {
+	docs, err := h.groundTruthService.ListVersions(c.Param("id"), requestScope(c))
+	if err != nil {
+		respondGroundTruthError(c, err)
+		return
+	}
+
+	data := make([]GroundTruthDocumentResponse, 0, len(docs))
+	for i := range docs {
+		data = append(data, newGroundTruthDocumentResponse(&docs[i]))
+	}
+
+	c.JSON(http.StatusOK, GroundTruthListResponse{Data: data})
+}
+
+// Replace handles PUT /ground-truth/:id: uploads the next version of an
+// active document as multipart field file, optionally with document_name,
+// role and version
+func (h *GroundTruthHandler) Replace(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Form field 'file' is required"})
+		return
+	}
+	upload := services.GroundTruthUpload{
+		File:         file,
+		DocumentName: c.PostForm("document_name"),
+		Role:         c.PostForm("role"),
+		Version:      c.PostForm("version"),
+	}
+	if raw := c.PostForm("document_type"); raw != "" {
+		if upload.DocumentType, err = services.ParseGroundTruthType(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	doc, err := h.groundTruthService.ReplaceDocument(c.Request.Context(), c.Param("id"), upload, requestScope(c))
+	if err != nil {
+		respondGroundTruthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newGroundTruthDocumentResponse(doc))
+}
+
+// Retire handles DELETE /ground-truth/:id. The document stops being used for
+// new jobs but its versions stay queryable.
+func (h *GroundTruthHandler) Retire(c *gin.Context) {
+	doc, err := h.groundTruthService.RetireDocument(c.Request.Context(), c.Param("id"), requestScope(c))
+	if err != nil {
+		respondGroundTruthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newGroundTruthDocumentResponse(doc))
+}
+
+func newGroundTruthDocumentResponse(doc *models.GroundTruthDocument) GroundTruthDocumentResponse {
+	response := GroundTruthDocumentResponse{
+		ID:             doc.ID,
+		LineageID:      doc.LineageID,
+		DocumentName:   doc.DocumentName,
+		DocumentType:   doc.DocumentType,
+		Role:           doc.Role,
+		Version:        doc.Version,
+		SourceFilename: filepath.Base(doc.SourceFilePath),
+		Active:         doc.Active(),
+		IngestedAt:     doc.IngestedAt,
+	}
+	if doc.RetiredAt.Valid {
+		response.RetiredAt = &doc.RetiredAt.Time
+	}
+	return response
+}
+
+func respondGroundTruthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrGroundTruthNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ground truth document not found"})
+	case errors.Is(err, services.ErrGroundTruthRetired),
+		errors.Is(err, services.ErrGroundTruthVersionExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidGroundTruthType),
+		errors.Is(err, services.ErrInvalidFileType),
+		errors.Is(err, services.ErrEmptyGroundTruth),
+		errors.Is(err, services.ErrFileReadError):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
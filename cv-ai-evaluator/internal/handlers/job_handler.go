@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/services"
+	"cv-ai-evaluator/internal/worker"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	workerPool        *worker.WorkerPool
+	evaluationService *services.EvaluationService
+	webhookService    *services.WebhookService
+}
+
+func NewJobHandler(
+	workerPool *worker.WorkerPool,
+	evaluationService *services.EvaluationService,
+	webhookService *services.WebhookService,
+) *JobHandler {
+	return &JobHandler{
+		workerPool:        workerPool,
+		evaluationService: evaluationService,
+		webhookService:    webhookService,
+	}
+}
+
+type CancelResponse struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+}
+
+type WebhookDeliveriesResponse struct {
+	JobID       string                   `json:"job_id"`
+	CallbackURL string                   `json:"callback_url,omitempty"`
+	Status      string                   `json:"status,omitempty"`
+	Attempts    int                      `json:"attempts"`
+	Deliveries  []models.WebhookDelivery `json:"deliveries"`
+}
+
+const (
+	defaultJobPageSize = 20
+	maxJobPageSize     = 100
+)
+
+// JobSummary is the compact job representation used in listings
+type JobSummary struct {
+	ID           string     `json:"id"`
+	JobTitle     string     `json:"job_title"`
+	Status       string     `json:"status"`
+	CVMatchRate  *float64   `json:"cv_match_rate,omitempty"`
+	ProjectScore *float64   `json:"project_score,omitempty"`
+	Attempts     int        `json:"attempts"`
+	Error        string     `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+type JobListResponse struct {
+	Data       []JobSummary `json:"data"`
+	Pagination Pagination   `json:"pagination"`
+}
+
+// List handles GET /jobs with pagination, filters and sorting
+func (h *JobHandler) List(c *gin.Context) {
+	filter, err := parseJobFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := queryInt(c, "page", 1)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive integer"})
+		return
+	}
+	pageSize, err := queryInt(c, "page_size", defaultJobPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxJobPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("page_size must be between 1 and %d", maxJobPageSize)})
+		return
+	}
+
+	filter.SortBy = c.DefaultQuery("sort_by", "created_at")
+	if !slices.Contains(services.JobSortColumns, filter.SortBy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("sort_by must be one of %s", strings.Join(services.JobSortColumns, ", "))})
+		return
+	}
+	filter.SortOrder = strings.ToLower(c.DefaultQuery("order", "desc"))
+	if filter.SortOrder != "asc" && filter.SortOrder != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+		return
+	}
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	jobs, total, err := h.evaluationService.ListJobs(filter, requestScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	data := make([]JobSummary, 0, len(jobs))
+	for i := range jobs {
+		data = append(data, newJobSummary(&jobs[i]))
+	}
+
+	c.JSON(http.StatusOK, JobListResponse{
+		Data: data,
+		Pagination: Pagination{
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// Stats handles GET /jobs/stats; it accepts the same filters as List
+func (h *JobHandler) Stats(c *gin.Context) {
+	filter, err := parseJobFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := h.evaluationService.GetJobStats(filter, requestScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// parseJobFilter reads status, job_title, created_from/created_to and score
+// threshold query parameters. Dates accept RFC 3339 or YYYY-MM-DD; a date-only
+// created_to includes that whole day.
+func parseJobFilter(c *gin.Context) (services.JobFilter, error) {
+	var filter services.JobFilter
+
+	if raw := c.Query("status"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			status := models.JobStatus(strings.TrimSpace(part))
+			if !slices.Contains(models.AllJobStatuses, status) {
+				return filter, fmt.Errorf("unknown status %q", status)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+	filter.JobTitle = strings.TrimSpace(c.Query("job_title"))
+
+	var err error
+	if filter.CreatedFrom, _, err = queryTime(c, "created_from"); err != nil {
+		return filter, err
+	}
+	var dateOnly bool
+	if filter.CreatedTo, dateOnly, err = queryTime(c, "created_to"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo != nil && dateOnly {
+		endOfDay := filter.CreatedTo.AddDate(0, 0, 1)
+		filter.CreatedTo = &endOfDay
+	}
+
+	thresholds := []struct {
+		name   string
+		target **float64
+	}{
+		{"min_cv_match_rate", &filter.MinCVMatchRate},
+		{"max_cv_match_rate", &filter.MaxCVMatchRate},
+		{"min_project_score", &filter.MinProjectScore},
+		{"max_project_score", &filter.MaxProjectScore},
+	}
+	for _, t := range thresholds {
+		raw := c.Query(t.name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return filter, fmt.Errorf("%s must be a number", t.name)
+		}
+		*t.target = &value
+	}
+
+	return filter, nil
+}
+
+func queryInt(c *gin.Context, name string, fallback int) (int, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(raw)
+}
+
+// queryTime parses an optional time parameter and reports whether it was date-only
+func queryTime(c *gin.Context, name string) (*time.Time, bool, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, false, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s must be RFC 3339 or YYYY-MM-DD", name)
+	}
+	return &t, true, nil
+}
+
+func newJobSummary(job *models.EvaluationJob) JobSummary {
+	summary := JobSummary{
+		ID:        job.ID,
+		JobTitle:  job.JobTitleEvaluated,
+		Status:    string(job.Status),
+		Attempts:  job.Attempts,
+		Error:     job.ErrorMessage.String,
+		CreatedAt: job.CreatedAt,
+	}
+	if job.CVMatchRate.Valid {
+		summary.CVMatchRate = &job.CVMatchRate.Float64
+	}
+	if job.ProjectScore.Valid {
+		summary.ProjectScore = &job.ProjectScore.Float64
+	}
+	if job.StartedAt.Valid {
+		summary.StartedAt = &job.StartedAt.Time
+	}
+	if job.CompletedAt.Valid {
+		summary.CompletedAt = &job.CompletedAt.Time
+	}
+	return summary
+}
+
+// Cancel handles POST /jobs/:id/cancel. A queued job is simply never picked
+// up; an in-flight job is aborted at its current LLM stage.
+func (h *JobHandler) Cancel(c *gin.Context) {
+	jobID := c.Param("id")
+
+	previous, err := h.evaluationService.CancelJob(jobID, requestScope(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrJobNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errors.Is(err, services.ErrJobNotCancellable):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "status": string(previous)})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if previous == models.JobStatusProcessing {
+		h.workerPool.CancelJob(jobID)
+	}
+
+	c.JSON(http.StatusOK, CancelResponse{
+		ID:             jobID,
+		Status:         string(models.JobStatusCancelled),
+		PreviousStatus: string(previous),
+	})
+}
+
+// Webhooks handles GET /jobs/:id/webhooks, listing every callback delivery attempt
+func (h *JobHandler) Webhooks(c *gin.Context) {
+	jobID := c.Param("id")
+
+	job, err := h.evaluationService.GetJobInScope(jobID, requestScope(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebhookDeliveriesResponse{
+		JobID:       job.ID,
+		CallbackURL: job.CallbackURL.String,
+		Status:      job.WebhookStatus.String,
+		Attempts:    job.WebhookAttempts,
+		Deliveries:  deliveries,
+	})
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cv-ai-evaluator/internal/worker"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetricsHandler struct {
+	workerPool *worker.WorkerPool
+}
+
+func NewMetricsHandler(workerPool *worker.WorkerPool) *MetricsHandler {
+	return &MetricsHandler{
+		workerPool: workerPool,
+	}
+}
+
+// Metrics exposes worker pool gauges in Prometheus text exposition format
+func (h *MetricsHandler) Metrics(c *gin.Context) {
+	stats, err := h.workerPool.Stats()
+	if err != nil {
+		c.String(http.StatusInternalServerError, "# failed to collect metrics: %v\n", err)
+		return
+	}
+
+	stopped := 0
+	if stats.Stopped {
+		stopped = 1
+	}
+
+	var b strings.Builder
+	writeMetric(&b, "cv_evaluator_queue_depth", "gauge", "Evaluation jobs waiting in the queue.", stats.QueueDepth)
+	writeMetric(&b, "cv_evaluator_queue_capacity", "gauge", "Maximum queued jobs before submissions are rejected.", stats.QueueCapacity)
+	writeMetric(&b, "cv_evaluator_jobs_in_flight", "gauge", "Evaluation jobs currently being processed.", stats.InFlight)
+	writeMetric(&b, "cv_evaluator_workers", "gauge", "Number of workers in the pool.", stats.Workers)
+	writeMetric(&b, "cv_evaluator_submissions_rejected_total", "counter", "Submissions rejected because the queue was full or the pool stopped.", stats.Rejected)
+	writeMetric(&b, "cv_evaluator_pool_stopped", "gauge", "1 if the worker pool is stopped.", stopped)
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+func writeMetric(b *strings.Builder, name, kind, help string, value interface{}) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+}
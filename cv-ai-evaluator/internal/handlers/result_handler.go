@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"log"
 	"net/http"
+	"time"
 
 	"cv-ai-evaluator/internal/models"
 	"cv-ai-evaluator/internal/services"
@@ -9,58 +13,174 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// How often the event stream re-reads the job and how often an idle
+	// stream sends a comment so proxies keep the connection open
+	eventPollInterval = time.Second
+	eventKeepAlive    = 15 * time.Second
+)
+
 type ResultHandler struct {
 	evaluationService *services.EvaluationService
+	progressService   *services.ProgressService
 }
 
-func NewResultHandler(evaluationService *services.EvaluationService) *ResultHandler {
+func NewResultHandler(evaluationService *services.EvaluationService, progressService *services.ProgressService) *ResultHandler {
 	return &ResultHandler{
 		evaluationService: evaluationService,
+		progressService:   progressService,
 	}
 }
 
 type ResultResponse struct {
-	ID     string            `json:"id"`
-	Status string            `json:"status"`
-	Result *EvaluationResult `json:"result,omitempty"`
-	Error  string            `json:"error,omitempty"`
+	ID           string                `json:"id"`
+	Status       string                `json:"status"`
+	Result       *EvaluationResult     `json:"result,omitempty"`
+	Error        string                `json:"error,omitempty"`
+	Attempts     int                   `json:"attempts,omitempty"`
+	ErrorHistory models.AttemptHistory `json:"error_history,omitempty"`
+	NextRetryAt  *time.Time            `json:"next_retry_at,omitempty"`
+	Progress     *services.JobProgress `json:"progress,omitempty"`
+	// Job description and rubrics (with versions) the job is evaluated against
+	GroundTruth models.GroundTruthRefs `json:"ground_truth,omitempty"`
+	// Where the prompt context came from, without the text itself
+	RetrievedChunks models.RetrievedChunks `json:"retrieved_chunks,omitempty"`
+	RerunOf         string                 `json:"rerun_of,omitempty"`
 }
 
 type EvaluationResult struct {
-	CVMatchRate     float64 `json:"cv_match_rate"`
-	CVFeedback      string  `json:"cv_feedback"`
-	ProjectScore    float64 `json:"project_score"`
-	ProjectFeedback string  `json:"project_feedback"`
-	OverallSummary  string  `json:"overall_summary"`
+	CVMatchRate           float64               `json:"cv_match_rate"`
+	CVFeedback            string                `json:"cv_feedback"`
+	CVScoreBreakdown      models.ScoreBreakdown `json:"cv_score_breakdown,omitempty"`
+	ProjectScore          float64               `json:"project_score"`
+	ProjectFeedback       string                `json:"project_feedback"`
+	ProjectScoreBreakdown models.ScoreBreakdown `json:"project_score_breakdown,omitempty"`
+	OverallSummary        string                `json:"overall_summary"`
 }
 
 func (h *ResultHandler) GetResult(c *gin.Context) {
 	jobID := c.Param("id")
 
-	// Get job using service
-	job, err := h.evaluationService.GetJobByID(jobID)
+	// Get job using service; other clients' jobs are reported as not found
+	job, err := h.evaluationService.GetJobInScope(jobID, requestScope(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.buildResponse(job))
+}
+
+// StreamEvents streams the job state over Server-Sent Events. A "progress" event
+// is sent whenever the state changes and a final "result" event once the job
+// reaches a terminal status, after which the stream is closed.
+func (h *ResultHandler) StreamEvents(c *gin.Context) {
+	jobID := c.Param("id")
+	scope := requestScope(c)
+
+	job, err := h.evaluationService.GetJobInScope(jobID, scope)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 		return
 	}
 
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ticker := time.NewTicker(eventPollInterval)
+	defer ticker.Stop()
+
+	var lastPayload []byte
+	lastSent := time.Now()
+
+	for {
+		payload, err := json.Marshal(h.buildResponse(job))
+		if err != nil {
+			log.Printf("Warning: failed to encode event for job %s: %v", jobID, err)
+			return
+		}
+
+		switch {
+		case job.Status.IsTerminal():
+			c.SSEvent("result", string(payload))
+			c.Writer.Flush()
+			return
+		case !bytes.Equal(payload, lastPayload):
+			c.SSEvent("progress", string(payload))
+			c.Writer.Flush()
+			lastPayload = payload
+			lastSent = time.Now()
+		case time.Since(lastSent) >= eventKeepAlive:
+			c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+			lastSent = time.Now()
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		job, err = h.evaluationService.GetJobInScope(jobID, scope)
+		if err != nil {
+			c.SSEvent("error", gin.H{"error": "Job not found"})
+			c.Writer.Flush()
+			return
+		}
+	}
+}
+
+func (h *ResultHandler) buildResponse(job *models.EvaluationJob) ResultResponse {
+	response := BuildResultResponse(job)
+
+	progress, err := h.progressService.GetProgress(job)
+	if err != nil {
+		log.Printf("Warning: failed to compute progress for job %s: %v", job.ID, err)
+	}
+	response.Progress = progress
+
+	return response
+}
+
+// BuildResultResponse converts a job into the public result representation,
+// also used as the webhook callback payload
+func BuildResultResponse(job *models.EvaluationJob) ResultResponse {
 	response := ResultResponse{
-		ID:     job.ID,
-		Status: string(job.Status),
+		ID:           job.ID,
+		Status:       string(job.Status),
+		Attempts:     job.Attempts,
+		ErrorHistory: job.ErrorHistory,
+		GroundTruth:  job.GroundTruthRefs,
+		RerunOf:      job.RerunOfJobID.String,
+	}
+	for _, chunk := range job.RetrievedChunks {
+		chunk.Content = ""
+		response.RetrievedChunks = append(response.RetrievedChunks, chunk)
 	}
 
 	switch job.Status {
 	case models.JobStatusCompleted:
 		response.Result = &EvaluationResult{
-			CVMatchRate:     job.CVMatchRate.Float64,
-			CVFeedback:      job.CVFeedback.String,
-			ProjectScore:    job.ProjectScore.Float64,
-			ProjectFeedback: job.ProjectFeedback.String,
-			OverallSummary:  job.OverallSummary.String,
+			CVMatchRate:           job.CVMatchRate.Float64,
+			CVFeedback:            job.CVFeedback.String,
+			CVScoreBreakdown:      job.CVScoreBreakdown,
+			ProjectScore:          job.ProjectScore.Float64,
+			ProjectFeedback:       job.ProjectFeedback.String,
+			ProjectScoreBreakdown: job.ProjectScoreBreakdown,
+			OverallSummary:        job.OverallSummary.String,
+		}
+	case models.JobStatusQueued:
+		// Previous attempt failed and a retry is scheduled
+		if job.NextAttemptAt.Valid {
+			response.Error = job.ErrorMessage.String
+			response.NextRetryAt = &job.NextAttemptAt.Time
 		}
-	case models.JobStatusFailed:
+	case models.JobStatusFailed, models.JobStatusCancelled, models.JobStatusDeadLetter:
 		response.Error = job.ErrorMessage.String
 	}
 
-	c.JSON(http.StatusOK, response)
+	return response
 }
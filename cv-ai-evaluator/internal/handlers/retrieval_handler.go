@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RetrievalHandler struct {
+	retrievalService *services.RetrievalService
+}
+
+func NewRetrievalHandler(retrievalService *services.RetrievalService) *RetrievalHandler {
+	return &RetrievalHandler{retrievalService: retrievalService}
+}
+
+// RetrievalDebugResponse shows how a new job for job_title would rank the
+// caller's ground truth of one type: the pinned document and every candidate
+// chunk, best first, with the score of each retrieval stage
+type RetrievalDebugResponse struct {
+	Query         string                 `json:"query"`
+	DocumentType  models.GroundTruthType `json:"document_type"`
+	Document      models.GroundTruthRef  `json:"document"`
+	Hybrid        bool                   `json:"hybrid"`
+	RRFK          int                    `json:"rrf_k"`
+	Reranked      bool                   `json:"reranked"`
+	ContextTokens int                    `json:"context_tokens"`
+	// The document fits in context_tokens and is sent whole, without ranking
+	WholeDocument bool                  `json:"whole_document"`
+	Data          []RankedChunkResponse `json:"data"`
+}
+
+// RankedChunkResponse is one candidate chunk. Ranks start at 1; a missing rank
+// means the stage did not rank the chunk (no query term for BM25). Score is
+// the value the chunks are ordered by. Selected chunks fit in the context
+// token budget and would be sent to the LLM.
+type RankedChunkResponse struct {
+	Rank         int      `json:"rank"`
+	DocumentID   string   `json:"document_id"`
+	DocumentName string   `json:"document_name,omitempty"`
+	Version      string   `json:"version,omitempty"`
+	Section      string   `json:"section,omitempty"`
+	Chunk        int      `json:"chunk"`
+	Tokens       int      `json:"tokens"`
+	Selected     bool     `json:"selected"`
+	Score        float32  `json:"score"`
+	Similarity   float32  `json:"similarity"`
+	VectorRank   int      `json:"vector_rank,omitempty"`
+	BM25Score    float32  `json:"bm25_score"`
+	BM25Rank     int      `json:"bm25_rank,omitempty"`
+	RRFScore     float32  `json:"rrf_score"`
+	RerankScore  *float32 `json:"rerank_score,omitempty"`
+	Content      string   `json:"content"`
+}
+
+// Debug handles GET /debug/retrieval?job_title=...&document_type=...
+// [&job_description_id=...][&query=...]. The ground truth is resolved as for
+// POST /evaluate and ranked the way the evaluation worker ranks it for the job.
+func (h *RetrievalHandler) Debug(c *gin.Context) {
+	req := services.RetrievalQuery{
+		JobTitle:         strings.TrimSpace(c.Query("job_title")),
+		JobDescriptionID: strings.TrimSpace(c.Query("job_description_id")),
+		Query:            strings.TrimSpace(c.Query("query")),
+	}
+	if req.JobTitle == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'job_title' is required"})
+		return
+	}
+	gtType, err := services.ParseGroundTruthType(c.Query("document_type"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.DocumentType = gtType
+
+	result, err := h.retrievalService.Search(c.Request.Context(), req, requestScope(c))
+	switch {
+	case errors.Is(err, services.ErrGroundTruthNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrJobDescriptionUnresolved):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := RetrievalDebugResponse{
+		Query:         result.Query,
+		DocumentType:  result.DocumentType,
+		Document:      result.Document,
+		Hybrid:        result.Config.Hybrid,
+		RRFK:          result.Config.RRFK,
+		ContextTokens: result.ContextTokens,
+		WholeDocument: result.Whole,
+		Data:          make([]RankedChunkResponse, 0, len(result.Sections)),
+	}
+	for i, section := range result.Sections {
+		chunk := RankedChunkResponse{
+			Rank:         i + 1,
+			DocumentID:   section.DocumentID,
+			DocumentName: section.DocumentName,
+			Version:      section.Version,
+			Section:      section.Heading,
+			Chunk:        section.Index,
+			Tokens:       section.Tokens,
+			Selected:     section.Selected,
+			Score:        section.Score,
+			Similarity:   section.Ranking.Similarity,
+			VectorRank:   section.Ranking.VectorRank,
+			BM25Score:    section.Ranking.Keyword,
+			BM25Rank:     section.Ranking.KeywordRank,
+			RRFScore:     section.Ranking.Fused,
+			Content:      section.Content,
+		}
+		if section.Ranking.Reranked {
+			rerank := section.Ranking.Rerank
+			chunk.RerankScore = &rerank
+			response.Reranked = true
+		}
+		response.Data = append(response.Data, chunk)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
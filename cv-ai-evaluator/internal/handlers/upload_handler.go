@@ -109,7 +109,7 @@ func (h *UploadHandler) Upload(c *gin.Context) {
 		reportFile.Filename, reportFile.Size, reportFile.Header.Get("Content-Type"))
 
 	// Step 6: Process files
-	cvDoc, reportDoc, err := h.documentService.UploadDocuments(cvFile, reportFile)
+	cvDoc, reportDoc, err := h.documentService.UploadDocuments(cvFile, reportFile, requestScope(c))
 	if err != nil {
 		log.Printf("Service error during upload: %v", err)
 		if errors.Is(err, services.ErrInvalidFileType) {
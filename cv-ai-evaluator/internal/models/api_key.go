@@ -0,0 +1,39 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKey adalah kredensial satu client API. Key asli hanya ditampilkan sekali
+// saat dibuat; yang disimpan hanya hash SHA-256 dan prefix untuk identifikasi.
+type APIKey struct {
+	ID   string `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Name string `gorm:"type:varchar(255);not null" json:"name"`
+	// Organisasi tempat key ini bekerja; semua data yang dibuat key masuk ke organisasi ini
+	OrganizationID string       `gorm:"type:varchar(36);not null;index" json:"organization_id"`
+	Prefix         string       `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash        string       `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	LastUsedAt     sql.NullTime `json:"last_used_at,omitempty"`
+	RevokedAt      sql.NullTime `json:"revoked_at,omitempty"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Active melaporkan apakah key masih boleh dipakai
+func (k *APIKey) Active() bool {
+	return !k.RevokedAt.Valid
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		k.ID = uuid.New().String()
+	}
+	return nil
+}
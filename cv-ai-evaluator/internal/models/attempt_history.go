@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AttemptError mencatat kegagalan satu percobaan pemrosesan job
+type AttemptError struct {
+	Attempt   int       `json:"attempt"`
+	Stage     string    `json:"stage"`
+	Error     string    `json:"error"`
+	Retryable bool      `json:"retryable"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// AttemptHistory disimpan sebagai JSON di kolom text
+type AttemptHistory []AttemptError
+
+// Value implements driver.Valuer
+func (h AttemptHistory) Value() (driver.Value, error) {
+	if h == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (h *AttemptHistory) Scan(value interface{}) error {
+	if value == nil {
+		*h = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for AttemptHistory", value)
+	}
+
+	if len(data) == 0 {
+		*h = nil
+		return nil
+	}
+	return json.Unmarshal(data, h)
+}
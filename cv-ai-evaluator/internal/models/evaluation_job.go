@@ -15,23 +15,91 @@ const (
     JobStatusProcessing JobStatus = "processing"
     JobStatusCompleted  JobStatus = "completed"
     JobStatusFailed     JobStatus = "failed"
+    JobStatusCancelled  JobStatus = "cancelled"
+    // JobStatusDeadLetter: semua percobaan retry habis, perlu ditinjau manual
+    JobStatusDeadLetter JobStatus = "dead_letter"
 )
 
+// AllJobStatuses lists every status in lifecycle order
+var AllJobStatuses = []JobStatus{
+    JobStatusQueued,
+    JobStatusProcessing,
+    JobStatusCompleted,
+    JobStatusFailed,
+    JobStatusCancelled,
+    JobStatusDeadLetter,
+}
+
+// IsTerminal reports whether the job will never be processed again
+func (s JobStatus) IsTerminal() bool {
+    return s == JobStatusCompleted || s == JobStatusFailed || s == JobStatusCancelled || s == JobStatusDeadLetter
+}
+
 type EvaluationJob struct {
     ID                 string         `gorm:"type:varchar(36);primaryKey" json:"id"`
     CVDocumentID       string         `gorm:"type:varchar(36);not null" json:"cv_document_id"`
     ReportDocumentID   string         `gorm:"type:varchar(36);not null" json:"report_document_id"`
     JobTitleEvaluated  string         `gorm:"type:varchar(255);not null" json:"job_title_evaluated"`
-    Status             JobStatus      `gorm:"type:enum('queued','processing','completed','failed');default:'queued'" json:"status"`
+    Status             JobStatus      `gorm:"type:varchar(20);not null;default:'queued'" json:"status"`
     CreatedAt          time.Time      `gorm:"autoCreateTime" json:"created_at"`
     CompletedAt        sql.NullTime   `json:"completed_at,omitempty"`
     ErrorMessage       sql.NullString `gorm:"type:text" json:"error_message,omitempty"`
-    CVMatchRate        sql.NullFloat64 `gorm:"type:decimal(3,2)" json:"cv_match_rate,omitempty"`
+    CVMatchRate        sql.NullFloat64 `gorm:"column:cv_match_rate;type:decimal(3,2)" json:"cv_match_rate,omitempty"`
     CVFeedback         sql.NullString `gorm:"type:text" json:"cv_feedback,omitempty"`
     ProjectScore       sql.NullFloat64 `gorm:"type:decimal(3,2)" json:"project_score,omitempty"`
     ProjectFeedback    sql.NullString `gorm:"type:text" json:"project_feedback,omitempty"`
     OverallSummary     sql.NullString `gorm:"type:text" json:"overall_summary,omitempty"`
 
+    // Nilai per parameter rubric; skor akhir dihitung di server dari breakdown ini
+    CVScoreBreakdown      ScoreBreakdown `gorm:"type:text" json:"cv_score_breakdown,omitempty"`
+    ProjectScoreBreakdown ScoreBreakdown `gorm:"type:text" json:"project_score_breakdown,omitempty"`
+
+    // Queue lease: worker yang sedang memproses job dan kapan lease-nya habis
+    StartedAt          sql.NullTime   `json:"started_at,omitempty"`
+    LeaseOwner         sql.NullString `gorm:"type:varchar(100);index" json:"-"`
+    LeaseExpiresAt     sql.NullTime   `gorm:"index" json:"-"`
+
+    // Retry: jumlah percobaan, riwayat error per percobaan, dan jadwal percobaan berikutnya
+    Attempts           int            `gorm:"not null;default:0" json:"attempts"`
+    ErrorHistory       AttemptHistory `gorm:"type:text" json:"error_history,omitempty"`
+    NextAttemptAt      sql.NullTime   `json:"next_attempt_at,omitempty"`
+
+    // Checkpoint per stage: hasil stage yang sudah selesai disimpan agar job yang
+    // di-retry atau di-recover melanjutkan dari stage terakhir, bukan dari awal
+    CVText             sql.NullString `json:"-"` // tanpa size: LONGTEXT di MySQL, TEXT di PostgreSQL/SQLite
+    ReportText         sql.NullString `json:"-"`
+    TextExtractedAt    sql.NullTime   `json:"text_extracted_at,omitempty"`
+    CVEvaluatedAt      sql.NullTime   `json:"cv_evaluated_at,omitempty"`
+    ProjectEvaluatedAt sql.NullTime   `json:"project_evaluated_at,omitempty"`
+    SummarizedAt       sql.NullTime   `json:"summarized_at,omitempty"`
+
+    // Progress: stage yang sedang berjalan dan waktu mulai/selesai setiap stage
+    CurrentStage       sql.NullString `gorm:"type:varchar(32)" json:"current_stage,omitempty"`
+    StageTimeline      StageTimeline  `gorm:"type:text" json:"stage_timeline,omitempty"`
+
+    // Webhook: URL callback saat job selesai, status pengiriman, dan jadwal retry
+    CallbackURL          sql.NullString `gorm:"type:varchar(2048)" json:"callback_url,omitempty"`
+    WebhookStatus        sql.NullString `gorm:"type:varchar(20);index" json:"webhook_status,omitempty"`
+    WebhookAttempts      int            `gorm:"not null;default:0" json:"webhook_attempts"`
+    WebhookNextAttemptAt sql.NullTime   `json:"-"`
+
+    // Dokumen ground truth (ID dan versi) yang dipilih saat job dibuat; worker
+    // hanya memakai dokumen ini sehingga hasil penilaian bisa diaudit
+    GroundTruthRefs      GroundTruthRefs `gorm:"type:text" json:"ground_truth,omitempty"`
+    // Potongan ground truth yang benar-benar masuk ke prompt. Disimpan sebagai
+    // checkpoint: retry dan re-run memakai teks yang sama persis.
+    RetrievedChunks      RetrievedChunks `gorm:"type:text" json:"retrieved_chunks,omitempty"` // LONGTEXT di MySQL (migration 0014)
+    // Job asal jika job ini dibuat lewat POST /jobs/:id/rerun
+    RerunOfJobID         sql.NullString `gorm:"type:varchar(36);index" json:"rerun_of_job_id,omitempty"`
+
+    // Organisasi pemilik job; ground truth untuk RAG diambil dari organisasi ini
+    // dan organisasi lain tidak bisa melihat atau membatalkan job
+    OrganizationID       string         `gorm:"type:varchar(36);not null;default:'default';index" json:"organization_id"`
+    // API key yang membuat job (audit)
+    OwnerKeyID           sql.NullString `gorm:"type:varchar(36);index" json:"-"`
+    // Job dari sebelum organisasi ada (migration 0016): hanya terlihat oleh OwnerKeyID
+    OwnerOnly            bool           `gorm:"not null;default:false" json:"-"`
+
     // Relations
     CVDocument     UploadedDocument `gorm:"foreignKey:CVDocumentID" json:"-"`
     ReportDocument UploadedDocument `gorm:"foreignKey:ReportDocumentID" json:"-"`
@@ -41,9 +109,30 @@ func (EvaluationJob) TableName() string {
     return "evaluation_jobs"
 }
 
+// CVResult mengembalikan hasil penilaian CV yang tersimpan (checkpoint atau hasil akhir)
+func (e *EvaluationJob) CVResult() RubricResult {
+    return RubricResult{
+        Score:     e.CVMatchRate.Float64,
+        Feedback:  e.CVFeedback.String,
+        Breakdown: e.CVScoreBreakdown,
+    }
+}
+
+// ProjectResult mengembalikan hasil penilaian project yang tersimpan
+func (e *EvaluationJob) ProjectResult() RubricResult {
+    return RubricResult{
+        Score:     e.ProjectScore.Float64,
+        Feedback:  e.ProjectFeedback.String,
+        Breakdown: e.ProjectScoreBreakdown,
+    }
+}
+
 func (e *EvaluationJob) BeforeCreate(tx *gorm.DB) error {
     if e.ID == "" {
         e.ID = uuid.New().String()
     }
+    if e.OrganizationID == "" {
+        e.OrganizationID = DefaultOrganizationID
+    }
     return nil
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,19 +20,49 @@ const (
 type GroundTruthDocument struct {
     ID             string          `gorm:"type:varchar(36);primaryKey" json:"id"`
     DocumentName   string          `gorm:"type:varchar(255);not null" json:"document_name"`
-    DocumentType   GroundTruthType `gorm:"type:enum('job_description','case_study_brief','cv_rubric','project_rubric');not null" json:"document_type"`
+    DocumentType   GroundTruthType `gorm:"type:varchar(32);not null" json:"document_type"`
     SourceFilePath string          `gorm:"type:varchar(500);not null" json:"source_file_path"`
+    // SHA-256 (hex) isi file sumber; perintah ingest membandingkannya untuk
+    // melewati file yang tidak berubah. Kosong untuk dokumen sebelum migration 0015.
+    ContentHash    string          `gorm:"type:varchar(64);not null;default:''" json:"content_hash"`
     IngestedAt     time.Time       `gorm:"autoCreateTime" json:"ingested_at"`
     Version        string          `gorm:"type:varchar(50)" json:"version"`
+    // Semua versi satu dokumen berbagi LineageID (ID versi pertama). Upload baru
+    // membuat baris baru dengan versi dinaikkan dan me-retire versi sebelumnya.
+    LineageID      string          `gorm:"type:varchar(36);not null;default:''" json:"lineage_id"`
+    // Snapshot teks yang di-index; versi yang sudah di-retire tidak ada lagi di
+    // vector DB tetapi isinya tetap bisa dibaca untuk audit dan re-run job.
+    // Kosong untuk dokumen yang di-ingest sebelum versioning.
+    Content        sql.NullString  `json:"-"` // tanpa size: LONGTEXT di MySQL, TEXT di PostgreSQL/SQLite
+    // Dokumen yang di-retire tidak dipakai lagi untuk job baru dan sudah
+    // dihapus dari vector DB; record-nya tetap ada untuk audit job lama
+    RetiredAt      sql.NullTime    `json:"retired_at,omitempty"`
+    // Role yang dinilai dengan dokumen ini (contoh "backend-engineer"); kosong
+    // berarti berlaku untuk semua role di organisasi. Job description menentukan
+    // role job, rubric dan case study brief dipilih dari role yang sama.
+    Role           string          `gorm:"type:varchar(100);not null;default:''" json:"role"`
+    // Organisasi pemilik; retrieval hanya memakai ground truth organisasi job
+    OrganizationID string          `gorm:"type:varchar(36);not null;default:'default';index" json:"organization_id"`
 }
 
 func (GroundTruthDocument) TableName() string {
     return "ground_truth_documents"
 }
 
+// Active melaporkan apakah dokumen masih dipakai untuk job baru
+func (g *GroundTruthDocument) Active() bool {
+    return !g.RetiredAt.Valid
+}
+
 func (g *GroundTruthDocument) BeforeCreate(tx *gorm.DB) error {
     if g.ID == "" {
         g.ID = uuid.New().String()
     }
+    if g.LineageID == "" {
+        g.LineageID = g.ID
+    }
+    if g.OrganizationID == "" {
+        g.OrganizationID = DefaultOrganizationID
+    }
     return nil
 }
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GroundTruthRef menunjuk satu dokumen ground truth (beserta versinya) yang
+// dipakai untuk menilai sebuah job
+type GroundTruthRef struct {
+	ID      string          `json:"id"`
+	Type    GroundTruthType `json:"type"`
+	Name    string          `json:"name"`
+	Version string          `json:"version"`
+}
+
+// GroundTruthRefs disimpan sebagai JSON di kolom text. Nil berarti job dibuat
+// sebelum ground truth dipilih per job (retrieval lewat similarity search);
+// slice kosong berarti organisasi belum punya ground truth sama sekali.
+type GroundTruthRefs []GroundTruthRef
+
+// Find mengembalikan dokumen untuk tipe tersebut, jika ada
+func (r GroundTruthRefs) Find(gtType GroundTruthType) (GroundTruthRef, bool) {
+	for _, ref := range r {
+		if ref.Type == gtType {
+			return ref, true
+		}
+	}
+	return GroundTruthRef{}, false
+}
+
+// Value implements driver.Valuer
+func (r GroundTruthRefs) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (r *GroundTruthRefs) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for GroundTruthRefs", value)
+	}
+
+	if len(data) == 0 {
+		*r = nil
+		return nil
+	}
+	return json.Unmarshal(data, r)
+}
+
+// RetrievedChunk adalah satu potongan ground truth yang dipakai di prompt
+// evaluasi, beserta dokumen dan versi asalnya
+type RetrievedChunk struct {
+	Type       GroundTruthType `json:"type"`
+	DocumentID string          `json:"document_id"`
+	Version    string          `json:"version,omitempty"`
+	// Section adalah jalur heading chunk; kosong jika dokumen dipakai utuh
+	Section string `json:"section,omitempty"`
+	// Similarity dari vector search; 0 untuk dokumen yang dipilih langsung
+	Score   float32 `json:"score,omitempty"`
+	Content string  `json:"content,omitempty"`
+}
+
+// RetrievedChunks disimpan sebagai JSON. Nil berarti retrieval belum berjalan;
+// slice kosong berarti semua tipe memakai teks fallback.
+type RetrievedChunks []RetrievedChunk
+
+// Text menggabungkan isi chunk satu tipe sesuai urutan tersimpan (urutan di
+// dokumen untuk chunk per section)
+func (r RetrievedChunks) Text(gtType GroundTruthType) string {
+	var parts []string
+	for _, chunk := range r {
+		if chunk.Type == gtType {
+			parts = append(parts, chunk.Content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Value implements driver.Valuer
+func (r RetrievedChunks) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (r *RetrievedChunks) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for RetrievedChunks", value)
+	}
+
+	if len(data) == 0 {
+		*r = nil
+		return nil
+	}
+	return json.Unmarshal(data, r)
+}
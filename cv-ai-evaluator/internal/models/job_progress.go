@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JobStage adalah tahap pipeline evaluasi yang sedang dikerjakan worker
+type JobStage string
+
+const (
+	StageExtracting        JobStage = "extracting"
+	StageRetrievingContext JobStage = "retrieving_context"
+	StageScoringCV         JobStage = "scoring_cv"
+	StageScoringProject    JobStage = "scoring_project"
+	StageSummarising       JobStage = "summarising"
+)
+
+// PipelineStages adalah urutan stage dalam satu job
+var PipelineStages = []JobStage{
+	StageExtracting,
+	StageRetrievingContext,
+	StageScoringCV,
+	StageScoringProject,
+	StageSummarising,
+}
+
+// StageTiming mencatat kapan sebuah stage dimulai dan selesai pada satu percobaan.
+// FinishedAt kosong berarti stage masih berjalan atau percobaan itu gagal.
+type StageTiming struct {
+	Stage      JobStage   `json:"stage"`
+	Attempt    int        `json:"attempt"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Duration mengembalikan lama stage yang sudah selesai
+func (t StageTiming) Duration() (time.Duration, bool) {
+	if t.FinishedAt == nil {
+		return 0, false
+	}
+	return t.FinishedAt.Sub(t.StartedAt), true
+}
+
+// StageTimeline disimpan sebagai JSON di kolom text
+type StageTimeline []StageTiming
+
+// Enter menutup stage yang masih terbuka pada percobaan yang sama lalu
+// mencatat stage baru
+func (t StageTimeline) Enter(stage JobStage, attempt int, at time.Time) StageTimeline {
+	t = t.Finish(attempt, at)
+	return append(t, StageTiming{Stage: stage, Attempt: attempt, StartedAt: at})
+}
+
+// Finish menutup stage yang masih terbuka pada percobaan tersebut
+func (t StageTimeline) Finish(attempt int, at time.Time) StageTimeline {
+	for i := range t {
+		if t[i].Attempt == attempt && t[i].FinishedAt == nil {
+			finished := at
+			t[i].FinishedAt = &finished
+		}
+	}
+	return t
+}
+
+// Value implements driver.Valuer
+func (t StageTimeline) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (t *StageTimeline) Scan(value interface{}) error {
+	if value == nil {
+		*t = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for StageTimeline", value)
+	}
+
+	if len(data) == 0 {
+		*t = nil
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
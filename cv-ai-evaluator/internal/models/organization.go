@@ -0,0 +1,36 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DefaultOrganizationID adalah organisasi yang dibuat oleh migration 0011.
+// Data sebelum multi-tenant dan request saat AUTH_ENABLED=false masuk ke sini.
+const DefaultOrganizationID = "default"
+
+// Organization adalah tenant (perusahaan client). Dokumen, job, ground truth,
+// dan API key selalu milik satu organisasi dan tidak terlihat oleh organisasi lain.
+type Organization struct {
+	ID   string `gorm:"type:varchar(36);primaryKey" json:"id"`
+	Name string `gorm:"type:varchar(255);not null" json:"name"`
+	// Callback URL default untuk job organisasi ini jika request tidak mengirim callback_url
+	CallbackURL sql.NullString `gorm:"type:varchar(2048)" json:"callback_url,omitempty"`
+	// Secret HMAC untuk callback organisasi ini; NULL berarti WEBHOOK_SECRET global
+	WebhookSecret sql.NullString `gorm:"type:varchar(64)" json:"-"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// ParameterScore adalah nilai satu parameter rubric (skala 1-5) beserta alasannya
+type ParameterScore struct {
+	Parameter     string  `json:"parameter"`
+	Name          string  `json:"name"`
+	Weight        float64 `json:"weight"`
+	Score         float64 `json:"score"`
+	Justification string  `json:"justification"`
+}
+
+// ScoreBreakdown disimpan sebagai JSON di kolom text
+type ScoreBreakdown []ParameterScore
+
+// Value implements driver.Valuer
+func (b ScoreBreakdown) Value() (driver.Value, error) {
+	if b == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (b *ScoreBreakdown) Scan(value interface{}) error {
+	if value == nil {
+		*b = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for ScoreBreakdown", value)
+	}
+
+	if len(data) == 0 {
+		*b = nil
+		return nil
+	}
+	return json.Unmarshal(data, b)
+}
+
+// RubricResult adalah hasil akhir satu stage penilaian (CV atau project)
+type RubricResult struct {
+	Score     float64
+	Feedback  string
+	Breakdown ScoreBreakdown
+}
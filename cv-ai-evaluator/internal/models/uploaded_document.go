@@ -1,6 +1,7 @@
 package models
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,8 +19,15 @@ type UploadedDocument struct {
     ID               string       `gorm:"type:varchar(36);primaryKey" json:"id"`
     FilePath         string       `gorm:"type:varchar(500);not null" json:"file_path"`
     OriginalFilename string       `gorm:"type:varchar(255);not null" json:"original_filename"`
-    DocumentType     DocumentType `gorm:"type:enum('cv','project_report');not null" json:"document_type"`
+    DocumentType     DocumentType `gorm:"type:varchar(20);not null" json:"document_type"`
     UploadedAt       time.Time    `gorm:"autoCreateTime" json:"uploaded_at"`
+
+    // Organisasi pemilik; dokumen hanya bisa dipakai oleh API key organisasi yang sama
+    OrganizationID   string         `gorm:"type:varchar(36);not null;default:'default';index" json:"organization_id"`
+    // API key yang meng-upload (audit)
+    OwnerKeyID       sql.NullString `gorm:"type:varchar(36);index" json:"-"`
+    // Dokumen dari sebelum organisasi ada (migration 0016): hanya terlihat oleh OwnerKeyID
+    OwnerOnly        bool           `gorm:"not null;default:false" json:"-"`
 }
 
 func (UploadedDocument) TableName() string {
@@ -31,5 +39,8 @@ func (u *UploadedDocument) BeforeCreate(tx *gorm.DB) error {
     if u.ID == "" {
         u.ID = uuid.New().String()
     }
+    if u.OrganizationID == "" {
+        u.OrganizationID = DefaultOrganizationID
+    }
     return nil
 }
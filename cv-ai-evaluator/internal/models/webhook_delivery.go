@@ -0,0 +1,42 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status pengiriman webhook pada EvaluationJob
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed"
+)
+
+// WebhookDelivery mencatat satu percobaan pengiriman callback untuk sebuah job
+type WebhookDelivery struct {
+	ID           string         `gorm:"type:varchar(36);primaryKey" json:"id"`
+	JobID        string         `gorm:"type:varchar(36);not null;index" json:"job_id"`
+	URL          string         `gorm:"type:varchar(2048);not null" json:"url"`
+	Event        string         `gorm:"type:varchar(50);not null" json:"event"`
+	Attempt      int            `gorm:"not null" json:"attempt"`
+	StatusCode   int            `json:"status_code,omitempty"`
+	Success      bool           `gorm:"not null;default:false" json:"success"`
+	Error        sql.NullString `gorm:"type:text" json:"error,omitempty"`
+	ResponseBody sql.NullString `gorm:"type:text" json:"response_body,omitempty"`
+	DurationMs   int64          `json:"duration_ms"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
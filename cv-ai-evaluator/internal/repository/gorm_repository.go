@@ -0,0 +1,444 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// notFound maps GORM's not-found error to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// GormDocumentRepository implements DocumentRepository on a SQL database
+type GormDocumentRepository struct {
+	db *gorm.DB
+}
+
+func NewGormDocumentRepository(db *gorm.DB) *GormDocumentRepository {
+	return &GormDocumentRepository{db: db}
+}
+
+func (r *GormDocumentRepository) Create(doc *models.UploadedDocument) error {
+	return r.db.Create(doc).Error
+}
+
+func (r *GormDocumentRepository) FindByID(id string) (*models.UploadedDocument, error) {
+	var doc models.UploadedDocument
+	if err := r.db.First(&doc, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &doc, nil
+}
+
+func (r *GormDocumentRepository) Delete(id string) error {
+	return r.db.Delete(&models.UploadedDocument{}, "id = ?", id).Error
+}
+
+// GormJobRepository implements JobRepository on a SQL database
+type GormJobRepository struct {
+	db     *gorm.DB
+	schema *schema.Schema
+}
+
+func NewGormJobRepository(db *gorm.DB) (*GormJobRepository, error) {
+	jobSchema, err := schema.Parse(&models.EvaluationJob{}, &sync.Map{}, db.NamingStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse job schema: %w", err)
+	}
+	return &GormJobRepository{db: db, schema: jobSchema}, nil
+}
+
+func (r *GormJobRepository) Create(job *models.EvaluationJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *GormJobRepository) FindByID(id string) (*models.EvaluationJob, error) {
+	var job models.EvaluationJob
+	if err := r.db.First(&job, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &job, nil
+}
+
+func (r *GormJobRepository) FindByIDWithDocuments(id string) (*models.EvaluationJob, error) {
+	var job models.EvaluationJob
+	if err := r.db.Preload("CVDocument").
+		Preload("ReportDocument").
+		First(&job, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &job, nil
+}
+
+// Update locks the row for the duration of the transaction and writes only
+// the columns fn changed
+func (r *GormJobRepository) Update(id string, fn JobUpdateFunc) (bool, error) {
+	saved := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var job models.EvaluationJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&job, "id = ?", id).Error; err != nil {
+			return notFound(err)
+		}
+
+		before, err := r.columnValues(&job)
+		if err != nil {
+			return err
+		}
+		if !fn(&job) {
+			return nil
+		}
+		after, err := r.columnValues(&job)
+		if err != nil {
+			return err
+		}
+
+		changes := make(map[string]interface{})
+		for column, value := range after {
+			if !reflect.DeepEqual(before[column], value) {
+				changes[column] = value
+			}
+		}
+		saved = true
+		if len(changes) == 0 {
+			return nil
+		}
+		return tx.Model(&models.EvaluationJob{}).Where("id = ?", id).Updates(changes).Error
+	})
+	return saved, err
+}
+
+// columnValues snapshots every column as its driver value, so in-place changes
+// to slices (histories, timelines) are detected as well
+func (r *GormJobRepository) columnValues(job *models.EvaluationJob) (map[string]interface{}, error) {
+	rv := reflect.ValueOf(job).Elem()
+	values := make(map[string]interface{}, len(r.schema.DBNames))
+
+	for _, name := range r.schema.DBNames {
+		field := r.schema.FieldsByDBName[name]
+		value, _ := field.ValueOf(context.Background(), rv)
+		if valuer, ok := value.(driver.Valuer); ok {
+			v, err := valuer.Value()
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+			}
+			value = v
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+func (r *GormJobRepository) Delete(id string, fn func(job *models.EvaluationJob) bool) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var job models.EvaluationJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&job, "id = ?", id).Error; err != nil {
+			return notFound(err)
+		}
+		if !fn(&job) {
+			return nil
+		}
+		if err := tx.Delete(&models.EvaluationJob{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	})
+	return deleted, err
+}
+
+func (r *GormJobRepository) NextQueuedID(now time.Time) (string, error) {
+	return r.firstID(r.db.
+		Where("status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", models.JobStatusQueued, now).
+		Order("created_at ASC"))
+}
+
+func (r *GormJobRepository) ExpiredLeaseIDs(now time.Time) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&models.EvaluationJob{}).
+		Where("status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)", models.JobStatusProcessing, now).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *GormJobRepository) NextPendingWebhookID(now time.Time) (string, error) {
+	return r.firstID(r.db.
+		Where("webhook_status = ? AND (webhook_next_attempt_at IS NULL OR webhook_next_attempt_at <= ?)", models.WebhookStatusPending, now).
+		Order("completed_at ASC"))
+}
+
+// firstID returns the ID of the first matching job, or "" when none match.
+// Find instead of Take, so an empty queue on every idle poll is not logged as
+// a "record not found" error.
+func (r *GormJobRepository) firstID(query *gorm.DB) (string, error) {
+	var job models.EvaluationJob
+	result := query.Select("id").Limit(1).Find(&job)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return "", result.Error
+	}
+	return job.ID, nil
+}
+
+func (r *GormJobRepository) List(filter JobFilter) ([]models.EvaluationJob, int64, error) {
+	var total int64
+	if err := filter.apply(r.db.Model(&models.EvaluationJob{})).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// NULLs first ascending and last descending, spelled out because
+	// PostgreSQL defaults to the opposite of MySQL and SQLite
+	sortBy, desc := filter.sortColumn()
+	order := fmt.Sprintf("(%s IS NULL) DESC, %s ASC", sortBy, sortBy)
+	if desc {
+		order = fmt.Sprintf("(%s IS NULL) ASC, %s DESC", sortBy, sortBy)
+	}
+
+	query := filter.apply(r.db.Omit("cv_text", "report_text", "stage_timeline")).
+		Order(order).
+		Order("id ASC").
+		Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var jobs []models.EvaluationJob
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+func (r *GormJobRepository) CountByStatus(filter JobFilter) (map[models.JobStatus]int64, error) {
+	var rows []struct {
+		Status models.JobStatus
+		Count  int64
+	}
+	if err := filter.apply(r.db.Model(&models.EvaluationJob{})).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.JobStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *GormJobRepository) Timings(filter JobFilter) ([]JobTiming, error) {
+	var timings []JobTiming
+	err := filter.apply(r.db.Model(&models.EvaluationJob{})).
+		Select("cv_match_rate, project_score, created_at, started_at, completed_at").
+		Scan(&timings).Error
+	return timings, err
+}
+
+func (r *GormJobRepository) RecentStageTimelines(limit int) ([]models.StageTimeline, error) {
+	var jobs []models.EvaluationJob
+	if err := r.db.Select("stage_timeline").
+		Where("status = ? AND stage_timeline IS NOT NULL", models.JobStatusCompleted).
+		Order("completed_at DESC").
+		Limit(limit).
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+
+	timelines := make([]models.StageTimeline, 0, len(jobs))
+	for _, job := range jobs {
+		timelines = append(timelines, job.StageTimeline)
+	}
+	return timelines, nil
+}
+
+// GormGroundTruthRepository implements GroundTruthRepository on a SQL database
+type GormGroundTruthRepository struct {
+	db *gorm.DB
+}
+
+func NewGormGroundTruthRepository(db *gorm.DB) *GormGroundTruthRepository {
+	return &GormGroundTruthRepository{db: db}
+}
+
+func (r *GormGroundTruthRepository) Create(doc *models.GroundTruthDocument) error {
+	return r.db.Create(doc).Error
+}
+
+func (r *GormGroundTruthRepository) CreateVersion(doc *models.GroundTruthDocument, previousID string, retiredAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.GroundTruthDocument{}).
+			Where("id = ? AND retired_at IS NULL", previousID).
+			Update("retired_at", retiredAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return tx.Create(doc).Error
+	})
+}
+
+func (r *GormGroundTruthRepository) Update(doc *models.GroundTruthDocument) error {
+	var existing models.GroundTruthDocument
+	if err := r.db.Select("id").First(&existing, "id = ?", doc.ID).Error; err != nil {
+		return notFound(err)
+	}
+	return r.db.Model(&models.GroundTruthDocument{}).Where("id = ?", doc.ID).Select("*").Omit("id", "content").Updates(doc).Error
+}
+
+func (r *GormGroundTruthRepository) FindByID(id string) (*models.GroundTruthDocument, error) {
+	var doc models.GroundTruthDocument
+	if err := r.db.First(&doc, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &doc, nil
+}
+
+func (r *GormGroundTruthRepository) FindAll() ([]models.GroundTruthDocument, error) {
+	var docs []models.GroundTruthDocument
+	err := r.db.Omit("content").Order("ingested_at ASC, id ASC").Find(&docs).Error
+	return docs, err
+}
+
+func (r *GormGroundTruthRepository) ListByOrganization(organizationID string) ([]models.GroundTruthDocument, error) {
+	var docs []models.GroundTruthDocument
+	err := r.db.Omit("content").Where("organization_id = ?", organizationID).Order("ingested_at ASC, id ASC").Find(&docs).Error
+	return docs, err
+}
+
+func (r *GormGroundTruthRepository) ListVersions(lineageID string) ([]models.GroundTruthDocument, error) {
+	var docs []models.GroundTruthDocument
+	err := r.db.Omit("content").Where("lineage_id = ?", lineageID).Order("ingested_at ASC, id ASC").Find(&docs).Error
+	return docs, err
+}
+
+// GormOrganizationRepository implements OrganizationRepository on a SQL database
+type GormOrganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewGormOrganizationRepository(db *gorm.DB) *GormOrganizationRepository {
+	return &GormOrganizationRepository{db: db}
+}
+
+func (r *GormOrganizationRepository) Create(org *models.Organization) error {
+	return r.db.Create(org).Error
+}
+
+func (r *GormOrganizationRepository) FindByID(id string) (*models.Organization, error) {
+	var org models.Organization
+	if err := r.db.First(&org, "id = ?", id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &org, nil
+}
+
+func (r *GormOrganizationRepository) List() ([]models.Organization, error) {
+	var orgs []models.Organization
+	err := r.db.Order("created_at ASC").Find(&orgs).Error
+	return orgs, err
+}
+
+func (r *GormOrganizationRepository) SetCallbackURL(id string, callbackURL sql.NullString) error {
+	var org models.Organization
+	if err := r.db.Select("id").First(&org, "id = ?", id).Error; err != nil {
+		return notFound(err)
+	}
+	return r.db.Model(&models.Organization{}).Where("id = ?", id).Update("callback_url", callbackURL).Error
+}
+
+func (r *GormOrganizationRepository) SetWebhookSecret(id string, secret sql.NullString) error {
+	var org models.Organization
+	if err := r.db.Select("id").First(&org, "id = ?", id).Error; err != nil {
+		return notFound(err)
+	}
+	return r.db.Model(&models.Organization{}).Where("id = ?", id).Update("webhook_secret", secret).Error
+}
+
+// GormAPIKeyRepository implements APIKeyRepository on a SQL database
+type GormAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAPIKeyRepository(db *gorm.DB) *GormAPIKeyRepository {
+	return &GormAPIKeyRepository{db: db}
+}
+
+func (r *GormAPIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *GormAPIKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.First(&key, "key_hash = ?", keyHash).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &key, nil
+}
+
+func (r *GormAPIKeyRepository) List() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Order("created_at ASC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *GormAPIKeyRepository) Revoke(id string, at time.Time) error {
+	var key models.APIKey
+	if err := r.db.Select("id").First(&key, "id = ?", id).Error; err != nil {
+		return notFound(err)
+	}
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+func (r *GormAPIKeyRepository) TouchLastUsed(id string, at time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// GormWebhookDeliveryRepository implements WebhookDeliveryRepository on a SQL database
+type GormWebhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewGormWebhookDeliveryRepository(db *gorm.DB) *GormWebhookDeliveryRepository {
+	return &GormWebhookDeliveryRepository{db: db}
+}
+
+func (r *GormWebhookDeliveryRepository) Create(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *GormWebhookDeliveryRepository) ListByJob(jobID string) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Where("job_id = ?", jobID).Order("attempt ASC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Compile-time interface checks
+var (
+	_ DocumentRepository        = (*GormDocumentRepository)(nil)
+	_ JobRepository             = (*GormJobRepository)(nil)
+	_ GroundTruthRepository     = (*GormGroundTruthRepository)(nil)
+	_ OrganizationRepository    = (*GormOrganizationRepository)(nil)
+	_ APIKeyRepository          = (*GormAPIKeyRepository)(nil)
+	_ WebhookDeliveryRepository = (*GormWebhookDeliveryRepository)(nil)
+)
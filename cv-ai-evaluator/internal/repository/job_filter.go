@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"cmp"
+	"database/sql"
+	"slices"
+	"strings"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// JobFilter narrows job listings and statistics. Zero values mean "no filter".
+type JobFilter struct {
+	OrganizationID  string // only jobs of this organisation
+	OwnerKeyID      string // with OrganizationID: hides OwnerOnly jobs of other keys
+	Statuses        []models.JobStatus
+	JobTitle        string // case-insensitive substring match
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time // exclusive
+	MinCVMatchRate  *float64
+	MaxCVMatchRate  *float64
+	MinProjectScore *float64
+	MaxProjectScore *float64
+
+	SortBy    string // one of JobSortColumns, default created_at
+	SortOrder string // asc or desc, default desc
+	Limit     int    // 0 means no limit
+	Offset    int
+}
+
+// JobSortColumns lists the columns a job listing can be sorted by
+var JobSortColumns = []string{"created_at", "completed_at", "cv_match_rate", "project_score"}
+
+// sortColumn returns the validated sort column and whether the order is descending
+func (f JobFilter) sortColumn() (string, bool) {
+	sortBy := "created_at"
+	if slices.Contains(JobSortColumns, f.SortBy) {
+		sortBy = f.SortBy
+	}
+	return sortBy, !strings.EqualFold(f.SortOrder, "asc")
+}
+
+// apply adds the filter conditions to a GORM query
+func (f JobFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.OrganizationID != "" {
+		query = query.Where("organization_id = ?", f.OrganizationID).
+			Where("(owner_only = ? OR owner_key_id = ?)", false, f.OwnerKeyID)
+	}
+	if len(f.Statuses) > 0 {
+		query = query.Where("status IN ?", f.Statuses)
+	}
+	if f.JobTitle != "" {
+		query = query.Where("LOWER(job_title_evaluated) LIKE ?", "%"+strings.ToLower(f.JobTitle)+"%")
+	}
+	if f.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		query = query.Where("created_at < ?", *f.CreatedTo)
+	}
+	if f.MinCVMatchRate != nil {
+		query = query.Where("cv_match_rate >= ?", *f.MinCVMatchRate)
+	}
+	if f.MaxCVMatchRate != nil {
+		query = query.Where("cv_match_rate <= ?", *f.MaxCVMatchRate)
+	}
+	if f.MinProjectScore != nil {
+		query = query.Where("project_score >= ?", *f.MinProjectScore)
+	}
+	if f.MaxProjectScore != nil {
+		query = query.Where("project_score <= ?", *f.MaxProjectScore)
+	}
+	return query
+}
+
+// matches evaluates the filter in memory with the same semantics as apply
+// (a NULL score never satisfies a threshold)
+func (f JobFilter) matches(job *models.EvaluationJob) bool {
+	if f.OrganizationID != "" && (job.OrganizationID != f.OrganizationID || (job.OwnerOnly && job.OwnerKeyID.String != f.OwnerKeyID)) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, job.Status) {
+		return false
+	}
+	if f.JobTitle != "" && !strings.Contains(strings.ToLower(job.JobTitleEvaluated), strings.ToLower(f.JobTitle)) {
+		return false
+	}
+	if f.CreatedFrom != nil && job.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedTo != nil && !job.CreatedAt.Before(*f.CreatedTo) {
+		return false
+	}
+	return withinRange(job.CVMatchRate, f.MinCVMatchRate, f.MaxCVMatchRate) &&
+		withinRange(job.ProjectScore, f.MinProjectScore, f.MaxProjectScore)
+}
+
+func withinRange(value sql.NullFloat64, min, max *float64) bool {
+	if min == nil && max == nil {
+		return true
+	}
+	if !value.Valid {
+		return false
+	}
+	return (min == nil || value.Float64 >= *min) && (max == nil || value.Float64 <= *max)
+}
+
+// compare orders two jobs like the SQL ORDER BY used by List: NULLs sort
+// first ascending and last descending, ties are broken by ID
+func (f JobFilter) compare(a, b *models.EvaluationJob) int {
+	sortBy, desc := f.sortColumn()
+
+	var c int
+	switch sortBy {
+	case "completed_at":
+		c = compareNullable(a.CompletedAt.Valid, b.CompletedAt.Valid, func() int { return a.CompletedAt.Time.Compare(b.CompletedAt.Time) })
+	case "cv_match_rate":
+		c = compareNullable(a.CVMatchRate.Valid, b.CVMatchRate.Valid, func() int { return cmp.Compare(a.CVMatchRate.Float64, b.CVMatchRate.Float64) })
+	case "project_score":
+		c = compareNullable(a.ProjectScore.Valid, b.ProjectScore.Valid, func() int { return cmp.Compare(a.ProjectScore.Float64, b.ProjectScore.Float64) })
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if desc {
+		c = -c
+	}
+	if c == 0 {
+		c = strings.Compare(a.ID, b.ID)
+	}
+	return c
+}
+
+func compareNullable(aValid, bValid bool, compareValues func() int) int {
+	switch {
+	case !aValid && !bValid:
+		return 0
+	case !aValid:
+		return -1
+	case !bValid:
+		return 1
+	}
+	return compareValues()
+}
@@ -0,0 +1,583 @@
+package repository
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+)
+
+// The in-memory repositories keep everything in maps guarded by a mutex. They
+// mirror the GORM implementations closely enough to run the services and the
+// worker pool without a database, e.g. in tests.
+
+// MemoryDocumentRepository is an in-memory DocumentRepository
+type MemoryDocumentRepository struct {
+	mu   sync.RWMutex
+	docs map[string]models.UploadedDocument
+}
+
+func NewMemoryDocumentRepository() *MemoryDocumentRepository {
+	return &MemoryDocumentRepository{docs: make(map[string]models.UploadedDocument)}
+}
+
+func (r *MemoryDocumentRepository) Create(doc *models.UploadedDocument) error {
+	if err := doc.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if doc.UploadedAt.IsZero() {
+		doc.UploadedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.docs[doc.ID] = *doc
+	return nil
+}
+
+func (r *MemoryDocumentRepository) FindByID(id string) (*models.UploadedDocument, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	doc, ok := r.docs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &doc, nil
+}
+
+func (r *MemoryDocumentRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.docs, id)
+	return nil
+}
+
+// MemoryJobRepository is an in-memory JobRepository. Documents are resolved
+// from docs for FindByIDWithDocuments.
+type MemoryJobRepository struct {
+	mu   sync.Mutex
+	jobs map[string]*models.EvaluationJob
+	docs *MemoryDocumentRepository
+}
+
+func NewMemoryJobRepository(docs *MemoryDocumentRepository) *MemoryJobRepository {
+	return &MemoryJobRepository{
+		jobs: make(map[string]*models.EvaluationJob),
+		docs: docs,
+	}
+}
+
+// cloneJob copies a job including its slices so callers never share state
+// with the stored record
+func cloneJob(job *models.EvaluationJob) *models.EvaluationJob {
+	c := *job
+	c.CVScoreBreakdown = slices.Clone(job.CVScoreBreakdown)
+	c.ProjectScoreBreakdown = slices.Clone(job.ProjectScoreBreakdown)
+	c.ErrorHistory = slices.Clone(job.ErrorHistory)
+	c.StageTimeline = slices.Clone(job.StageTimeline)
+	c.GroundTruthRefs = slices.Clone(job.GroundTruthRefs)
+	c.RetrievedChunks = slices.Clone(job.RetrievedChunks)
+	c.CVDocument = models.UploadedDocument{}
+	c.ReportDocument = models.UploadedDocument{}
+	return &c
+}
+
+func (r *MemoryJobRepository) Create(job *models.EvaluationJob) error {
+	if err := job.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = cloneJob(job)
+	return nil
+}
+
+func (r *MemoryJobRepository) FindByID(id string) (*models.EvaluationJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneJob(job), nil
+}
+
+func (r *MemoryJobRepository) FindByIDWithDocuments(id string) (*models.EvaluationJob, error) {
+	job, err := r.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc, err := r.docs.FindByID(job.CVDocumentID); err == nil {
+		job.CVDocument = *doc
+	}
+	if doc, err := r.docs.FindByID(job.ReportDocumentID); err == nil {
+		job.ReportDocument = *doc
+	}
+	return job, nil
+}
+
+func (r *MemoryJobRepository) Update(id string, fn JobUpdateFunc) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[id]
+	if !ok {
+		return false, ErrNotFound
+	}
+
+	job := cloneJob(stored)
+	if !fn(job) {
+		return false, nil
+	}
+	r.jobs[id] = cloneJob(job)
+	return true, nil
+}
+
+func (r *MemoryJobRepository) Delete(id string, fn func(job *models.EvaluationJob) bool) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.jobs[id]
+	if !ok {
+		return false, ErrNotFound
+	}
+	if !fn(cloneJob(stored)) {
+		return false, nil
+	}
+	delete(r.jobs, id)
+	return true, nil
+}
+
+func (r *MemoryJobRepository) NextQueuedID(now time.Time) (string, error) {
+	return r.oldest(func(job *models.EvaluationJob) bool {
+		return job.Status == models.JobStatusQueued &&
+			(!job.NextAttemptAt.Valid || !job.NextAttemptAt.Time.After(now))
+	}, func(job *models.EvaluationJob) time.Time { return job.CreatedAt })
+}
+
+func (r *MemoryJobRepository) ExpiredLeaseIDs(now time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ids []string
+	for id, job := range r.jobs {
+		if job.Status == models.JobStatusProcessing &&
+			(!job.LeaseExpiresAt.Valid || job.LeaseExpiresAt.Time.Before(now)) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *MemoryJobRepository) NextPendingWebhookID(now time.Time) (string, error) {
+	return r.oldest(func(job *models.EvaluationJob) bool {
+		return job.WebhookStatus.String == models.WebhookStatusPending &&
+			(!job.WebhookNextAttemptAt.Valid || !job.WebhookNextAttemptAt.Time.After(now))
+	}, func(job *models.EvaluationJob) time.Time { return job.CompletedAt.Time })
+}
+
+// oldest returns the ID of the matching job with the earliest key, or ""
+func (r *MemoryJobRepository) oldest(match func(*models.EvaluationJob) bool, key func(*models.EvaluationJob) time.Time) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var best *models.EvaluationJob
+	for _, job := range r.jobs {
+		if !match(job) {
+			continue
+		}
+		if best == nil || key(job).Before(key(best)) || (key(job).Equal(key(best)) && job.ID < best.ID) {
+			best = job
+		}
+	}
+	if best == nil {
+		return "", nil
+	}
+	return best.ID, nil
+}
+
+// matching returns clones of every job that satisfies the filter
+func (r *MemoryJobRepository) matching(filter JobFilter) []*models.EvaluationJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var jobs []*models.EvaluationJob
+	for _, job := range r.jobs {
+		if filter.matches(job) {
+			jobs = append(jobs, cloneJob(job))
+		}
+	}
+	return jobs
+}
+
+func (r *MemoryJobRepository) List(filter JobFilter) ([]models.EvaluationJob, int64, error) {
+	jobs := r.matching(filter)
+	slices.SortFunc(jobs, filter.compare)
+
+	total := int64(len(jobs))
+	start := min(filter.Offset, len(jobs))
+	end := len(jobs)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, len(jobs))
+	}
+
+	page := make([]models.EvaluationJob, 0, end-start)
+	for _, job := range jobs[start:end] {
+		job.CVText.String, job.CVText.Valid = "", false
+		job.ReportText.String, job.ReportText.Valid = "", false
+		job.StageTimeline = nil
+		page = append(page, *job)
+	}
+	return page, total, nil
+}
+
+func (r *MemoryJobRepository) CountByStatus(filter JobFilter) (map[models.JobStatus]int64, error) {
+	counts := make(map[models.JobStatus]int64)
+	for _, job := range r.matching(filter) {
+		counts[job.Status]++
+	}
+	return counts, nil
+}
+
+func (r *MemoryJobRepository) Timings(filter JobFilter) ([]JobTiming, error) {
+	jobs := r.matching(filter)
+	timings := make([]JobTiming, 0, len(jobs))
+	for _, job := range jobs {
+		timings = append(timings, JobTiming{
+			CVMatchRate:  job.CVMatchRate,
+			ProjectScore: job.ProjectScore,
+			CreatedAt:    job.CreatedAt,
+			StartedAt:    job.StartedAt,
+			CompletedAt:  job.CompletedAt,
+		})
+	}
+	return timings, nil
+}
+
+func (r *MemoryJobRepository) RecentStageTimelines(limit int) ([]models.StageTimeline, error) {
+	jobs := r.matching(JobFilter{Statuses: []models.JobStatus{models.JobStatusCompleted}})
+	slices.SortFunc(jobs, func(a, b *models.EvaluationJob) int {
+		return cmp.Compare(b.CompletedAt.Time.UnixNano(), a.CompletedAt.Time.UnixNano())
+	})
+
+	var timelines []models.StageTimeline
+	for _, job := range jobs {
+		if len(timelines) >= limit {
+			break
+		}
+		if job.StageTimeline != nil {
+			timelines = append(timelines, job.StageTimeline)
+		}
+	}
+	return timelines, nil
+}
+
+// MemoryGroundTruthRepository is an in-memory GroundTruthRepository
+type MemoryGroundTruthRepository struct {
+	mu   sync.RWMutex
+	docs []models.GroundTruthDocument
+}
+
+func NewMemoryGroundTruthRepository() *MemoryGroundTruthRepository {
+	return &MemoryGroundTruthRepository{}
+}
+
+func (r *MemoryGroundTruthRepository) Create(doc *models.GroundTruthDocument) error {
+	if err := doc.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if doc.IngestedAt.IsZero() {
+		doc.IngestedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.docs = append(r.docs, *doc)
+	return nil
+}
+
+func (r *MemoryGroundTruthRepository) CreateVersion(doc *models.GroundTruthDocument, previousID string, retiredAt time.Time) error {
+	if err := doc.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if doc.IngestedAt.IsZero() {
+		doc.IngestedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.docs {
+		if r.docs[i].ID == previousID && r.docs[i].Active() {
+			r.docs[i].RetiredAt = sql.NullTime{Time: retiredAt, Valid: true}
+			r.docs = append(r.docs, *doc)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryGroundTruthRepository) Update(doc *models.GroundTruthDocument) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.docs {
+		if r.docs[i].ID == doc.ID {
+			content := r.docs[i].Content
+			r.docs[i] = *doc
+			r.docs[i].Content = content
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryGroundTruthRepository) FindByID(id string) (*models.GroundTruthDocument, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, doc := range r.docs {
+		if doc.ID == id {
+			return &doc, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryGroundTruthRepository) FindAll() ([]models.GroundTruthDocument, error) {
+	return r.list(func(*models.GroundTruthDocument) bool { return true }), nil
+}
+
+func (r *MemoryGroundTruthRepository) ListByOrganization(organizationID string) ([]models.GroundTruthDocument, error) {
+	return r.list(func(doc *models.GroundTruthDocument) bool { return doc.OrganizationID == organizationID }), nil
+}
+
+func (r *MemoryGroundTruthRepository) ListVersions(lineageID string) ([]models.GroundTruthDocument, error) {
+	return r.list(func(doc *models.GroundTruthDocument) bool { return doc.LineageID == lineageID }), nil
+}
+
+// list returns matching documents without content, like the GORM listings
+func (r *MemoryGroundTruthRepository) list(match func(doc *models.GroundTruthDocument) bool) []models.GroundTruthDocument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var docs []models.GroundTruthDocument
+	for _, doc := range r.docs {
+		if match(&doc) {
+			doc.Content = sql.NullString{}
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// MemoryOrganizationRepository is an in-memory OrganizationRepository. Like the
+// migrated database it starts with the default organisation.
+type MemoryOrganizationRepository struct {
+	mu   sync.RWMutex
+	orgs []models.Organization
+}
+
+func NewMemoryOrganizationRepository() *MemoryOrganizationRepository {
+	return &MemoryOrganizationRepository{orgs: []models.Organization{{
+		ID:        models.DefaultOrganizationID,
+		Name:      "Default",
+		CreatedAt: time.Now(),
+	}}}
+}
+
+func (r *MemoryOrganizationRepository) Create(org *models.Organization) error {
+	if err := org.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if org.CreatedAt.IsZero() {
+		org.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.orgs {
+		if existing.ID == org.ID {
+			return errors.New("duplicate organization id")
+		}
+	}
+	r.orgs = append(r.orgs, *org)
+	return nil
+}
+
+func (r *MemoryOrganizationRepository) FindByID(id string) (*models.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, org := range r.orgs {
+		if org.ID == id {
+			return &org, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryOrganizationRepository) List() ([]models.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.orgs), nil
+}
+
+func (r *MemoryOrganizationRepository) SetCallbackURL(id string, callbackURL sql.NullString) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.orgs {
+		if r.orgs[i].ID == id {
+			r.orgs[i].CallbackURL = callbackURL
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryOrganizationRepository) SetWebhookSecret(id string, secret sql.NullString) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.orgs {
+		if r.orgs[i].ID == id {
+			r.orgs[i].WebhookSecret = secret
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// MemoryAPIKeyRepository is an in-memory APIKeyRepository
+type MemoryAPIKeyRepository struct {
+	mu   sync.RWMutex
+	keys []models.APIKey
+}
+
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{}
+}
+
+func (r *MemoryAPIKeyRepository) Create(key *models.APIKey) error {
+	if err := key.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.keys {
+		if existing.KeyHash == key.KeyHash {
+			return errors.New("duplicate api key hash")
+		}
+	}
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *MemoryAPIKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash {
+			return &key, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryAPIKeyRepository) List() ([]models.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.keys), nil
+}
+
+func (r *MemoryAPIKeyRepository) Revoke(id string, at time.Time) error {
+	return r.update(id, func(key *models.APIKey) {
+		if !key.RevokedAt.Valid {
+			key.RevokedAt = sql.NullTime{Time: at, Valid: true}
+		}
+	})
+}
+
+func (r *MemoryAPIKeyRepository) TouchLastUsed(id string, at time.Time) error {
+	return r.update(id, func(key *models.APIKey) {
+		key.LastUsedAt = sql.NullTime{Time: at, Valid: true}
+	})
+}
+
+func (r *MemoryAPIKeyRepository) update(id string, fn func(key *models.APIKey)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.keys {
+		if r.keys[i].ID == id {
+			fn(&r.keys[i])
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+// MemoryWebhookDeliveryRepository is an in-memory WebhookDeliveryRepository
+type MemoryWebhookDeliveryRepository struct {
+	mu         sync.RWMutex
+	deliveries []models.WebhookDelivery
+}
+
+func NewMemoryWebhookDeliveryRepository() *MemoryWebhookDeliveryRepository {
+	return &MemoryWebhookDeliveryRepository{}
+}
+
+func (r *MemoryWebhookDeliveryRepository) Create(delivery *models.WebhookDelivery) error {
+	if err := delivery.BeforeCreate(nil); err != nil {
+		return err
+	}
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries = append(r.deliveries, *delivery)
+	return nil
+}
+
+func (r *MemoryWebhookDeliveryRepository) ListByJob(jobID string) ([]models.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []models.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.JobID == jobID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	slices.SortStableFunc(deliveries, func(a, b models.WebhookDelivery) int { return cmp.Compare(a.Attempt, b.Attempt) })
+	return deliveries, nil
+}
+
+// Compile-time interface checks
+var (
+	_ DocumentRepository        = (*MemoryDocumentRepository)(nil)
+	_ JobRepository             = (*MemoryJobRepository)(nil)
+	_ GroundTruthRepository     = (*MemoryGroundTruthRepository)(nil)
+	_ OrganizationRepository    = (*MemoryOrganizationRepository)(nil)
+	_ APIKeyRepository          = (*MemoryAPIKeyRepository)(nil)
+	_ WebhookDeliveryRepository = (*MemoryWebhookDeliveryRepository)(nil)
+)
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+)
+
+// ErrNotFound is returned when the requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
+// DocumentRepository stores uploaded CVs and project reports
+type DocumentRepository interface {
+	Create(doc *models.UploadedDocument) error
+	FindByID(id string) (*models.UploadedDocument, error)
+	Delete(id string) error
+}
+
+// JobUpdateFunc modifies a job in place and returns false to leave it unchanged
+type JobUpdateFunc func(job *models.EvaluationJob) bool
+
+// JobRepository stores evaluation jobs. State transitions go through Update so
+// that the check of the current state and the write happen atomically.
+type JobRepository interface {
+	Create(job *models.EvaluationJob) error
+	FindByID(id string) (*models.EvaluationJob, error)
+	// FindByIDWithDocuments also loads CVDocument and ReportDocument
+	FindByIDWithDocuments(id string) (*models.EvaluationJob, error)
+
+	// Update loads the job, passes it to fn and saves whatever fn changed,
+	// without any other writer interleaving. It reports whether the job was
+	// saved (fn returned true) and returns ErrNotFound for an unknown ID.
+	Update(id string, fn JobUpdateFunc) (bool, error)
+	// Delete removes the job if fn approves its current state
+	Delete(id string, fn func(job *models.EvaluationJob) bool) (bool, error)
+
+	// NextQueuedID returns the oldest queued job that may run at now, or ""
+	NextQueuedID(now time.Time) (string, error)
+	// ExpiredLeaseIDs returns processing jobs whose lease ended before now
+	ExpiredLeaseIDs(now time.Time) ([]string, error)
+	// NextPendingWebhookID returns the oldest job with a webhook due at now, or ""
+	NextPendingWebhookID(now time.Time) (string, error)
+
+	// List returns one page of jobs matching the filter and the total match count.
+	// Large text columns (extracted document text, stage timeline) are not loaded.
+	List(filter JobFilter) ([]models.EvaluationJob, int64, error)
+	CountByStatus(filter JobFilter) (map[models.JobStatus]int64, error)
+	// Timings returns score and timing columns of every job matching the filter
+	Timings(filter JobFilter) ([]JobTiming, error)
+	// RecentStageTimelines returns the timelines of the latest completed jobs
+	RecentStageTimelines(limit int) ([]models.StageTimeline, error)
+}
+
+// JobTiming is the subset of job columns needed for statistics
+type JobTiming struct {
+	CVMatchRate  sql.NullFloat64 `gorm:"column:cv_match_rate"`
+	ProjectScore sql.NullFloat64
+	CreatedAt    time.Time
+	StartedAt    sql.NullTime
+	CompletedAt  sql.NullTime
+}
+
+// GroundTruthRepository stores metadata of ingested ground truth documents
+type GroundTruthRepository interface {
+	Create(doc *models.GroundTruthDocument) error
+	// CreateVersion retires the active document previousID at retiredAt and
+	// creates doc in the same transaction; ErrNotFound if previousID is not
+	// an active document
+	CreateVersion(doc *models.GroundTruthDocument, previousID string, retiredAt time.Time) error
+	// Update writes every column of an existing document except its content,
+	// which never changes for a version
+	Update(doc *models.GroundTruthDocument) error
+	// FindByID returns a document including its content
+	FindByID(id string) (*models.GroundTruthDocument, error)
+	// The listings below leave Content empty and return documents oldest first.
+	// FindAll returns every document
+	FindAll() ([]models.GroundTruthDocument, error)
+	// ListByOrganization returns the documents of one organisation, retired
+	// ones included
+	ListByOrganization(organizationID string) ([]models.GroundTruthDocument, error)
+	// ListVersions returns every version of a document
+	ListVersions(lineageID string) ([]models.GroundTruthDocument, error)
+}
+
+// OrganizationRepository stores tenants
+type OrganizationRepository interface {
+	Create(org *models.Organization) error
+	FindByID(id string) (*models.Organization, error)
+	// List returns every organisation, oldest first
+	List() ([]models.Organization, error)
+	// SetCallbackURL replaces the default webhook URL; a NULL value clears it
+	SetCallbackURL(id string, callbackURL sql.NullString) error
+	// SetWebhookSecret replaces the secret that signs the organisation's callbacks
+	SetWebhookSecret(id string, secret sql.NullString) error
+}
+
+// APIKeyRepository stores hashed API keys
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByHash(keyHash string) (*models.APIKey, error)
+	// List returns every key, revoked ones included, oldest first
+	List() ([]models.APIKey, error)
+	// Revoke marks the key revoked; revoking twice keeps the first timestamp
+	Revoke(id string, at time.Time) error
+	TouchLastUsed(id string, at time.Time) error
+}
+
+// WebhookDeliveryRepository records webhook delivery attempts
+type WebhookDeliveryRepository interface {
+	Create(delivery *models.WebhookDelivery) error
+	// ListByJob returns the attempts for a job in attempt order
+	ListByJob(jobID string) ([]models.WebhookDelivery, error)
+}
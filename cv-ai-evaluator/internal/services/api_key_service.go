@@ -0,0 +1,124 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+)
+
+const (
+	apiKeyPrefix = "cve_"
+	// Characters of the key shown in listings to tell keys apart
+	apiKeyDisplayLength = 12
+	// last_used_at is only written when older than this, so authenticated
+	// requests do not each cost a database write
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid or revoked API key")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+type APIKeyService struct {
+	keys repository.APIKeyRepository
+	orgs repository.OrganizationRepository
+}
+
+func NewAPIKeyService(keys repository.APIKeyRepository, orgs repository.OrganizationRepository) *APIKeyService {
+	return &APIKeyService{keys: keys, orgs: orgs}
+}
+
+// HashAPIKey returns the hex SHA-256 of a key. Keys carry 256 bits of
+// randomness, so a fast hash is enough; there is nothing to brute-force.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateKey generates a new key for name in an organisation. The plaintext key
+// is returned only here; afterwards only its hash is known.
+func (s *APIKeyService) CreateKey(name, organizationID string) (string, *models.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errors.New("API key name is required")
+	}
+	if _, err := s.orgs.FindByID(organizationID); errors.Is(err, repository.ErrNotFound) {
+		return "", nil, fmt.Errorf("%w: %s", ErrOrganizationNotFound, organizationID)
+	} else if err != nil {
+		return "", nil, fmt.Errorf("failed to look up organization: %w", err)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	plaintext := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &models.APIKey{
+		Name:           name,
+		OrganizationID: organizationID,
+		Prefix:         plaintext[:apiKeyDisplayLength],
+		KeyHash:        HashAPIKey(plaintext),
+	}
+	if err := s.keys.Create(key); err != nil {
+		return "", nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+	return plaintext, key, nil
+}
+
+// Authenticate resolves a plaintext key to its active record
+func (s *APIKeyService) Authenticate(plaintext string) (*models.APIKey, error) {
+	if !strings.HasPrefix(plaintext, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.keys.FindByHash(HashAPIKey(plaintext))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if !key.Active() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !key.LastUsedAt.Valid || now.Sub(key.LastUsedAt.Time) >= apiKeyTouchInterval {
+		if err := s.keys.TouchLastUsed(key.ID, now); err != nil {
+			log.Printf("Warning: failed to record use of API key %s: %v", key.ID, err)
+		}
+	}
+	return key, nil
+}
+
+// ListKeys returns every key, revoked ones included
+func (s *APIKeyService) ListKeys() ([]models.APIKey, error) {
+	keys, err := s.keys.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey disables a key immediately
+func (s *APIKeyService) RevokeKey(id string) error {
+	err := s.keys.Revoke(id, time.Now())
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrAPIKeyNotFound, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrCallbackHostNotAllowed means a callback URL points at a host the server
+// must not call: an internal address, or a host outside WEBHOOK_ALLOWED_HOSTS
+var ErrCallbackHostNotAllowed = errors.New("callback host is not allowed")
+
+// reservedPrefixes are non-public IPv4 ranges not covered by the net/netip
+// predicates (this network, carrier-grade NAT, IETF protocol assignments,
+// benchmarking)
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// CallbackURLPolicy decides which webhook URLs the server may POST job results
+// to, so that a client-supplied callback_url cannot reach internal services
+// (SSRF). Without an allowlist any public host is allowed; loopback, private,
+// link-local and other non-public addresses are always refused. With an
+// allowlist only the listed hosts are allowed, at any address, so internal
+// receivers can be enabled explicitly.
+type CallbackURLPolicy struct {
+	// Exact host names, or ".example.com" for example.com and its subdomains
+	allowedHosts []string
+	resolver     *net.Resolver
+}
+
+func NewCallbackURLPolicy(allowedHosts []string) *CallbackURLPolicy {
+	policy := &CallbackURLPolicy{resolver: net.DefaultResolver}
+	for _, host := range allowedHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			policy.allowedHosts = append(policy.allowedHosts, host)
+		}
+	}
+	return policy
+}
+
+// Validate checks that raw is an http(s) URL whose host is allowed and, without
+// an allowlist, resolves only to public addresses. The addresses are checked
+// again when connecting, since DNS answers may change in between.
+func (p *CallbackURLPolicy) Validate(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidCallbackURL, raw)
+	}
+
+	host := u.Hostname()
+	if len(p.allowedHosts) > 0 {
+		if !p.allowlisted(host) {
+			return fmt.Errorf("%w: %s is not in WEBHOOK_ALLOWED_HOSTS", ErrCallbackHostNotAllowed, host)
+		}
+		return nil
+	}
+
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s: %v", ErrCallbackHostNotAllowed, host, err)
+	}
+	for _, addr := range addrs {
+		if !publicAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to non-public address %s", ErrCallbackHostNotAllowed, host, addr)
+		}
+	}
+	return nil
+}
+
+// HTTPClient returns a client for webhook deliveries that enforces the policy
+// on every connection and does not follow redirects (a redirect response is
+// returned as is and counts as a failed delivery)
+func (p *CallbackURLPolicy) HTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	publicDialer := &net.Dialer{
+		Timeout:   dialer.Timeout,
+		KeepAlive: dialer.KeepAlive,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrCallbackHostNotAllowed, err)
+			}
+			if !publicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: non-public address %s", ErrCallbackHostNotAllowed, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would make the checked address the proxy's, not the callback's
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if len(p.allowedHosts) > 0 {
+			if !p.allowlisted(host) {
+				return nil, fmt.Errorf("%w: %s is not in WEBHOOK_ALLOWED_HOSTS", ErrCallbackHostNotAllowed, host)
+			}
+			return dialer.DialContext(ctx, network, address)
+		}
+		return publicDialer.DialContext(ctx, network, address)
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (p *CallbackURLPolicy) allowlisted(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range p.allowedHosts {
+		if host == allowed || (strings.HasPrefix(allowed, ".") && (host == allowed[1:] || strings.HasSuffix(host, allowed))) {
+			return true
+		}
+	}
+	return false
+}
+
+// publicAddr reports whether addr is a globally routable unicast address
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
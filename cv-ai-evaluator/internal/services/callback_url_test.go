@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCallbackURLPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowedHosts []string
+		url          string
+		wantErr      error
+	}{
+		{"public address", nil, "https://93.184.216.34/hook", nil},
+		{"not http", nil, "ftp://93.184.216.34/hook", ErrInvalidCallbackURL},
+		{"no host", nil, "http:///hook", ErrInvalidCallbackURL},
+		{"loopback", nil, "http://127.0.0.1:9000/hook", ErrCallbackHostNotAllowed},
+		{"localhost", nil, "http://localhost:9000/hook", ErrCallbackHostNotAllowed},
+		{"private", nil, "http://10.1.2.3/hook", ErrCallbackHostNotAllowed},
+		{"link-local metadata", nil, "http://169.254.169.254/latest/meta-data", ErrCallbackHostNotAllowed},
+		{"carrier-grade NAT", nil, "http://100.100.100.200/", ErrCallbackHostNotAllowed},
+		{"unspecified", nil, "http://0.0.0.0:8080/", ErrCallbackHostNotAllowed},
+		{"IPv6 loopback", nil, "http://[::1]:9000/hook", ErrCallbackHostNotAllowed},
+		{"IPv4-mapped IPv6", nil, "http://[::ffff:127.0.0.1]/hook", ErrCallbackHostNotAllowed},
+		{"IPv6 unique local", nil, "http://[fd00::1]/hook", ErrCallbackHostNotAllowed},
+		{"allowlisted internal host", []string{"localhost"}, "http://localhost:9000/hook", nil},
+		{"allowlisted domain suffix", []string{".example.com"}, "https://hooks.example.com/hook", nil},
+		{"allowlisted domain itself", []string{".example.com"}, "https://example.com/hook", nil},
+		{"host outside allowlist", []string{"hooks.example.com"}, "https://93.184.216.34/hook", ErrCallbackHostNotAllowed},
+		{"suffix is not a substring match", []string{".example.com"}, "https://badexample.com/hook", ErrCallbackHostNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewCallbackURLPolicy(tt.allowedHosts).Validate(context.Background(), tt.url)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Validate(%q) = %v, want nil", tt.url, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate(%q) = %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallbackURLPolicyHTTPClient(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/target", http.StatusTemporaryRedirect)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// The test server listens on loopback, which only an allowlist permits
+	public := NewCallbackURLPolicy(nil).HTTPClient(time.Second)
+	if _, err := public.Post(server.URL+"/hook", "application/json", nil); !errors.Is(err, ErrCallbackHostNotAllowed) {
+		t.Fatalf("POST to loopback without allowlist: err = %v, want %v", err, ErrCallbackHostNotAllowed)
+	}
+	if hits != 0 {
+		t.Fatalf("server received %d request(s), want none", hits)
+	}
+
+	allowed := NewCallbackURLPolicy([]string{"127.0.0.1"}).HTTPClient(time.Second)
+	resp, err := allowed.Post(server.URL+"/redirect", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST to allowlisted host failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTemporaryRedirect || hits != 1 {
+		t.Fatalf("redirect: status %d after %d request(s), want %d after 1 (not followed)", resp.StatusCode, hits, http.StatusTemporaryRedirect)
+	}
+}
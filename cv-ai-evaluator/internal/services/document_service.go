@@ -9,8 +9,8 @@ import (
 	"os"
 	"path/filepath"
 
-	"cv-ai-evaluator/internal/database"
 	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
 
 	"github.com/google/uuid"
 )
@@ -25,16 +25,18 @@ var (
 
 type DocumentService struct {
 	uploadDir string
+	docs      repository.DocumentRepository
 }
 
-func NewDocumentService(uploadDir string) *DocumentService {
+func NewDocumentService(uploadDir string, docs repository.DocumentRepository) *DocumentService {
 	return &DocumentService{
 		uploadDir: uploadDir,
+		docs:      docs,
 	}
 }
 
 // UploadDocuments menangani upload file CV dan Report
-func (s *DocumentService) UploadDocuments(cvFile, reportFile *multipart.FileHeader) (*models.UploadedDocument, *models.UploadedDocument, error) {
+func (s *DocumentService) UploadDocuments(cvFile, reportFile *multipart.FileHeader, scope Scope) (*models.UploadedDocument, *models.UploadedDocument, error) {
 	// Validasi tipe file
 	if err := s.validateFileExtension(cvFile.Filename, ".pdf"); err != nil {
 		return nil, nil, fmt.Errorf("CV validation failed: %w", err)
@@ -50,19 +52,19 @@ func (s *DocumentService) UploadDocuments(cvFile, reportFile *multipart.FileHead
 	}
 
 	// Simpan CV
-	cvDoc, err := s.saveFile(cvFile, models.DocumentTypeCV)
+	cvDoc, err := s.saveFile(cvFile, models.DocumentTypeCV, scope)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to save CV: %w", err)
 	}
 
 	// Simpan Report
-	reportDoc, err := s.saveFile(reportFile, models.DocumentTypeProjectReport)
+	reportDoc, err := s.saveFile(reportFile, models.DocumentTypeProjectReport, scope)
 	if err != nil {
 		// Rollback (hapus file CV) jika file report gagal disimpan
 		if removeErr := os.Remove(cvDoc.FilePath); removeErr != nil {
 			log.Printf("Warning: failed to rollback file %s: %v", cvDoc.FilePath, removeErr)
 		}
-		if dbErr := database.DB.Delete(cvDoc).Error; dbErr != nil {
+		if dbErr := s.docs.Delete(cvDoc.ID); dbErr != nil {
 			log.Printf("Warning: failed to rollback db record %s: %v", cvDoc.ID, dbErr)
 		}
 		return nil, nil, fmt.Errorf("failed to save report: %w", err)
@@ -72,7 +74,7 @@ func (s *DocumentService) UploadDocuments(cvFile, reportFile *multipart.FileHead
 }
 
 // saveFile menyimpan satu file dan membuat record di database
-func (s *DocumentService) saveFile(file *multipart.FileHeader, docType models.DocumentType) (*models.UploadedDocument, error) {
+func (s *DocumentService) saveFile(file *multipart.FileHeader, docType models.DocumentType, scope Scope) (*models.UploadedDocument, error) {
 	// Buat ID unik dan nama file
 	docID := uuid.New().String()
 	// PERBAIKAN: Bersihkan nama file menggunakan filepath.Base untuk keamanan
@@ -105,9 +107,11 @@ func (s *DocumentService) saveFile(file *multipart.FileHeader, docType models.Do
 		FilePath:         filePath,
 		OriginalFilename: file.Filename,
 		DocumentType:     docType,
+		OrganizationID:   scope.organizationID(),
+		OwnerKeyID:       scope.ownerKeyID(),
 	}
 
-	if err := database.DB.Create(doc).Error; err != nil {
+	if err := s.docs.Create(doc); err != nil {
 		// Rollback (hapus file) jika insert ke DB gagal
 		if removeErr := os.Remove(filePath); removeErr != nil {
 			log.Printf("Warning: failed to rollback file %s on db error: %v", filePath, removeErr)
@@ -131,36 +135,45 @@ func (s *DocumentService) validateFileExtension(filename string, expectedExt str
 
 // GetDocumentByID mengambil dokumen berdasarkan ID
 func (s *DocumentService) GetDocumentByID(id string) (*models.UploadedDocument, error) {
-	var doc models.UploadedDocument
-	if err := database.DB.First(&doc, "id = ?", id).Error; err != nil {
+	doc, err := s.docs.FindByID(id)
+	if err != nil {
 		return nil, fmt.Errorf("document not found: %w", err)
 	}
-	return &doc, nil
+	return doc, nil
 }
 
-// ValidateDocumentsExist memeriksa apakah dokumen CV dan Report ada
-func (s *DocumentService) ValidateDocumentsExist(cvID, reportID string) error {
-	var count int64
-
+// ValidateDocumentsExist memeriksa apakah dokumen CV dan Report ada dan
+// di-upload oleh organisasi dalam scope yang sama
+func (s *DocumentService) ValidateDocumentsExist(cvID, reportID string, scope Scope) error {
 	// Cek CV
-	if err := database.DB.Model(&models.UploadedDocument{}).
-		Where("id = ? AND document_type = ?", cvID, models.DocumentTypeCV).
-		Count(&count).Error; err != nil {
+	exists, err := s.existsInScope(cvID, models.DocumentTypeCV, scope)
+	if err != nil {
 		return fmt.Errorf("failed to validate CV: %w", err)
 	}
-	if count == 0 {
+	if !exists {
 		return fmt.Errorf("CV document not found with id: %s", cvID)
 	}
 
 	// Cek Report
-	if err := database.DB.Model(&models.UploadedDocument{}).
-		Where("id = ? AND document_type = ?", reportID, models.DocumentTypeProjectReport).
-		Count(&count).Error; err != nil {
+	exists, err = s.existsInScope(reportID, models.DocumentTypeProjectReport, scope)
+	if err != nil {
 		return fmt.Errorf("failed to validate report: %w", err)
 	}
-	if count == 0 {
+	if !exists {
 		return fmt.Errorf("report document not found with id: %s", reportID)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// existsInScope: dokumen milik organisasi lain diperlakukan seperti tidak ada
+func (s *DocumentService) existsInScope(id string, docType models.DocumentType, scope Scope) (bool, error) {
+	doc, err := s.docs.FindByID(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return doc.DocumentType == docType && scope.ownsRecord(doc.OrganizationID, doc.OwnerKeyID, doc.OwnerOnly), nil
+}
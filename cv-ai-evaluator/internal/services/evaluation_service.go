@@ -1,29 +1,66 @@
 package services
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
-	"cv-ai-evaluator/internal/database"
 	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
 )
 
-type EvaluationService struct{}
+var (
+	ErrJobNotFound       = errors.New("job not found")
+	ErrJobNotCancellable = errors.New("job can no longer be cancelled")
+	// ErrJobNotReplayable means the job is still running, or predates ground
+	// truth pinning and so cannot be evaluated against the same context again
+	ErrJobNotReplayable = errors.New("job cannot be re-run")
+	// ErrLeaseLost means the worker no longer holds the lease on a job: it was
+	// cancelled, finished, or requeued after the lease expired and possibly
+	// claimed by another worker
+	ErrLeaseLost = errors.New("job lease lost")
+)
+
+// JobFilter narrows job listings and statistics (see repository.JobFilter)
+type JobFilter = repository.JobFilter
+
+// JobSortColumns lists the columns a job listing can be sorted by
+var JobSortColumns = repository.JobSortColumns
+
+type EvaluationService struct {
+	jobs repository.JobRepository
+}
 
-func NewEvaluationService() *EvaluationService {
-	return &EvaluationService{}
+func NewEvaluationService(jobs repository.JobRepository) *EvaluationService {
+	return &EvaluationService{jobs: jobs}
 }
 
-// CreateEvaluationJob creates a new evaluation job
-func (s *EvaluationService) CreateEvaluationJob(cvID, reportID, jobTitle string) (*models.EvaluationJob, error) {
+// NewJob describes an evaluation job to create
+type NewJob struct {
+	CVDocumentID     string
+	ReportDocumentID string
+	JobTitle         string
+	// Optional; when set, the final result is POSTed there
+	CallbackURL string
+	// Ground truth the job is evaluated against, see GroundTruthService.ResolveSet
+	GroundTruth models.GroundTruthRefs
+}
+
+// CreateEvaluationJob creates a new evaluation job owned by the scope's organisation
+func (s *EvaluationService) CreateEvaluationJob(req NewJob, scope Scope) (*models.EvaluationJob, error) {
 	job := &models.EvaluationJob{
-		CVDocumentID:      cvID,
-		ReportDocumentID:  reportID,
-		JobTitleEvaluated: jobTitle,
+		CVDocumentID:      req.CVDocumentID,
+		ReportDocumentID:  req.ReportDocumentID,
+		JobTitleEvaluated: req.JobTitle,
 		Status:            models.JobStatusQueued,
+		CallbackURL:       sql.NullString{String: req.CallbackURL, Valid: req.CallbackURL != ""},
+		GroundTruthRefs:   req.GroundTruth,
+		OrganizationID:    scope.organizationID(),
+		OwnerKeyID:        scope.ownerKeyID(),
 	}
 
-	if err := database.DB.Create(job).Error; err != nil {
+	if err := s.jobs.Create(job); err != nil {
 		return nil, fmt.Errorf("failed to create evaluation job: %w", err)
 	}
 
@@ -32,159 +69,537 @@ func (s *EvaluationService) CreateEvaluationJob(cvID, reportID, jobTitle string)
 
 // GetJobByID retrieves an evaluation job by ID
 func (s *EvaluationService) GetJobByID(jobID string) (*models.EvaluationJob, error) {
-	var job models.EvaluationJob
-	if err := database.DB.First(&job, "id = ?", jobID).Error; err != nil {
-		return nil, fmt.Errorf("job not found: %w", err)
+	job, err := s.jobs.FindByID(jobID)
+	if err != nil {
+		return nil, jobLookupError(jobID, err)
 	}
-	return &job, nil
+	return job, nil
+}
+
+// GetJobInScope retrieves a job only if it belongs to the scope; jobs of
+// other organisations are reported as not found
+func (s *EvaluationService) GetJobInScope(jobID string, scope Scope) (*models.EvaluationJob, error) {
+	job, err := s.GetJobByID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.ownsRecord(job.OrganizationID, job.OwnerKeyID, job.OwnerOnly) {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+	return job, nil
 }
 
 // GetJobWithDocuments retrieves a job with preloaded documents
 func (s *EvaluationService) GetJobWithDocuments(jobID string) (*models.EvaluationJob, error) {
-	var job models.EvaluationJob
-	if err := database.DB.Preload("CVDocument").
-		Preload("ReportDocument").
-		First(&job, "id = ?", jobID).Error; err != nil {
-		return nil, fmt.Errorf("job not found: %w", err)
+	job, err := s.jobs.FindByIDWithDocuments(jobID)
+	if err != nil {
+		return nil, jobLookupError(jobID, err)
+	}
+	return job, nil
+}
+
+func jobLookupError(jobID string, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
 	}
-	return &job, nil
+	return fmt.Errorf("failed to load job %s: %w", jobID, err)
 }
 
 // UpdateJobStatus updates the status of an evaluation job
 func (s *EvaluationService) UpdateJobStatus(jobID string, status models.JobStatus) error {
-	return database.DB.Model(&models.EvaluationJob{}).
-		Where("id = ?", jobID).
-		Update("status", status).Error
+	_, err := s.jobs.Update(jobID, func(job *models.EvaluationJob) bool {
+		job.Status = status
+		return true
+	})
+	return err
 }
 
-// CompleteJob marks a job as completed with results
-func (s *EvaluationService) CompleteJob(jobID string, cvMatchRate float64, cvFeedback string, projectScore float64, projectFeedback string, overallSummary string) error {
-	now := time.Now()
+// heldBy reports whether workerID still holds the lease on a processing job
+func heldBy(job *models.EvaluationJob, workerID string) bool {
+	return job.Status == models.JobStatusProcessing && job.LeaseOwner.String == workerID
+}
+
+// releaseLease clears the worker lease and the current stage
+func releaseLease(job *models.EvaluationJob) {
+	job.LeaseOwner = sql.NullString{}
+	job.LeaseExpiresAt = sql.NullTime{}
+	job.CurrentStage = sql.NullString{}
+}
 
-	updates := map[string]interface{}{
-		"status":           models.JobStatusCompleted,
-		"completed_at":     now,
-		"cv_match_rate":    cvMatchRate,
-		"cv_feedback":      cvFeedback,
-		"project_score":    projectScore,
-		"project_feedback": projectFeedback,
-		"overall_summary":  overallSummary,
+// finish moves a job to a terminal status and queues a webhook delivery when
+// the job has a callback URL
+func finish(job *models.EvaluationJob, status models.JobStatus, now time.Time) {
+	job.Status = status
+	job.CompletedAt = sql.NullTime{Time: now, Valid: true}
+	releaseLease(job)
+	if job.CallbackURL.String != "" {
+		job.WebhookStatus = sql.NullString{String: models.WebhookStatusPending, Valid: true}
 	}
+}
 
-	if err := database.DB.Model(&models.EvaluationJob{}).
-		Where("id = ?", jobID).
-		Updates(updates).Error; err != nil {
-		return fmt.Errorf("failed to update job results: %w", err)
+// ClaimNextJob atomically moves the oldest queued job to processing and leases
+// it to workerID. It returns nil without error when no job is waiting.
+func (s *EvaluationService) ClaimNextJob(workerID string, lease time.Duration) (*models.EvaluationJob, error) {
+	// Another worker may claim the same candidate first, so retry a few times
+	for attempt := 0; attempt < 5; attempt++ {
+		now := time.Now()
+
+		// Retried jobs wait until their backoff (next_attempt_at) has passed
+		candidateID, err := s.jobs.NextQueuedID(now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find queued job: %w", err)
+		}
+		if candidateID == "" {
+			return nil, nil
+		}
+
+		var claimed *models.EvaluationJob
+		ok, err := s.jobs.Update(candidateID, func(job *models.EvaluationJob) bool {
+			if job.Status != models.JobStatusQueued || (job.NextAttemptAt.Valid && job.NextAttemptAt.Time.After(now)) {
+				return false
+			}
+			job.Status = models.JobStatusProcessing
+			job.StartedAt = sql.NullTime{Time: now, Valid: true}
+			job.LeaseOwner = sql.NullString{String: workerID, Valid: true}
+			job.LeaseExpiresAt = sql.NullTime{Time: now.Add(lease), Valid: true}
+			job.NextAttemptAt = sql.NullTime{}
+			job.Attempts++
+			claimed = job
+			return true
+		})
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim job %s: %w", candidateID, err)
+		}
+		if ok {
+			return claimed, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// RenewLease extends the lease of a job still owned by workerID
+func (s *EvaluationService) RenewLease(jobID, workerID string, lease time.Duration) error {
+	ok, err := s.jobs.Update(jobID, func(job *models.EvaluationJob) bool {
+		if !heldBy(job, workerID) {
+			return false
+		}
+		job.LeaseExpiresAt = sql.NullTime{Time: time.Now().Add(lease), Valid: true}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to renew lease: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: job %s is no longer held by %s", ErrLeaseLost, jobID, workerID)
 	}
+	return nil
+}
 
+// ReleaseJob hands a job held by workerID back to the queue, e.g. when the
+// worker pool is shutting down mid-evaluation. The interrupted run does not
+// count as an attempt.
+func (s *EvaluationService) ReleaseJob(jobID, workerID string) error {
+	if _, err := s.jobs.Update(jobID, func(job *models.EvaluationJob) bool {
+		if !heldBy(job, workerID) {
+			return false
+		}
+		job.Status = models.JobStatusQueued
+		releaseLease(job)
+		if job.Attempts > 0 {
+			job.Attempts--
+		}
+		return true
+	}); err != nil {
+		return fmt.Errorf("failed to release job: %w", err)
+	}
 	return nil
 }
 
-// FailJob marks a job as failed with error message
-func (s *EvaluationService) FailJob(jobID string, errorMsg string) error {
+// RequeueExpiredJobs returns processing jobs whose lease expired (or that were
+// never leased) to the queue so another worker can pick them up
+func (s *EvaluationService) RequeueExpiredJobs() (int64, error) {
 	now := time.Now()
+	ids, err := s.jobs.ExpiredLeaseIDs(now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired jobs: %w", err)
+	}
 
-	updates := map[string]interface{}{
-		"status":        models.JobStatusFailed,
-		"completed_at":  now,
-		"error_message": errorMsg,
+	var requeued int64
+	for _, id := range ids {
+		ok, err := s.jobs.Update(id, func(job *models.EvaluationJob) bool {
+			// The lease may have been renewed since the lookup
+			if job.Status != models.JobStatusProcessing || (job.LeaseExpiresAt.Valid && !job.LeaseExpiresAt.Time.Before(now)) {
+				return false
+			}
+			job.Status = models.JobStatusQueued
+			releaseLease(job)
+			return true
+		})
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return requeued, fmt.Errorf("failed to requeue expired jobs: %w", err)
+		}
+		if ok {
+			requeued++
+		}
 	}
+	return requeued, nil
+}
 
-	if err := database.DB.Model(&models.EvaluationJob{}).
-		Where("id = ?", jobID).
-		Updates(updates).Error; err != nil {
-		return fmt.Errorf("failed to update job status: %w", err)
+// CountJobsByStatus counts jobs currently in the given status
+func (s *EvaluationService) CountJobsByStatus(status models.JobStatus) (int64, error) {
+	counts, err := s.jobs.CountByStatus(JobFilter{Statuses: []models.JobStatus{status}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count %s jobs: %w", status, err)
 	}
+	return counts[status], nil
+}
+
+// SaveExtractedText stores the cleaned document text once extraction succeeds
+func (s *EvaluationService) SaveExtractedText(jobID, workerID, cvText, reportText string) error {
+	return s.saveCheckpoint(jobID, workerID, func(job *models.EvaluationJob) {
+		job.CVText = sql.NullString{String: cvText, Valid: true}
+		job.ReportText = sql.NullString{String: reportText, Valid: true}
+		job.TextExtractedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	})
+}
+
+// SaveRetrievedChunks records the ground truth text used in the prompts, so
+// retries and re-runs evaluate against exactly the same context
+func (s *EvaluationService) SaveRetrievedChunks(jobID, workerID string, chunks models.RetrievedChunks) error {
+	return s.saveCheckpoint(jobID, workerID, func(job *models.EvaluationJob) {
+		job.RetrievedChunks = chunks
+	})
+}
 
+// SaveCVResult stores the CV evaluation so a retry can skip that stage
+func (s *EvaluationService) SaveCVResult(jobID, workerID string, result models.RubricResult) error {
+	return s.saveCheckpoint(jobID, workerID, func(job *models.EvaluationJob) {
+		setCVResult(job, result)
+		job.CVEvaluatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	})
+}
+
+// SaveProjectResult stores the project evaluation so a retry can skip that stage
+func (s *EvaluationService) SaveProjectResult(jobID, workerID string, result models.RubricResult) error {
+	return s.saveCheckpoint(jobID, workerID, func(job *models.EvaluationJob) {
+		setProjectResult(job, result)
+		job.ProjectEvaluatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	})
+}
+
+// SaveSummary stores the overall summary before the job is marked completed
+func (s *EvaluationService) SaveSummary(jobID, workerID, summary string) error {
+	return s.saveCheckpoint(jobID, workerID, func(job *models.EvaluationJob) {
+		job.OverallSummary = sql.NullString{String: summary, Valid: true}
+		job.SummarizedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	})
+}
+
+// UpdateProgress records the stage the worker is running and the stage timeline.
+// An empty stage means the pipeline has finished all stages.
+func (s *EvaluationService) UpdateProgress(jobID, workerID string, stage models.JobStage, timeline models.StageTimeline) error {
+	return s.saveCheckpoint(jobID, workerID, func(job *models.EvaluationJob) {
+		job.CurrentStage = sql.NullString{String: string(stage), Valid: stage != ""}
+		job.StageTimeline = timeline
+	})
+}
+
+// saveCheckpoint writes stage output only while the worker still holds the lease,
+// so a cancelled or reclaimed job is never overwritten by a stale worker
+func (s *EvaluationService) saveCheckpoint(jobID, workerID string, apply func(job *models.EvaluationJob)) error {
+	ok, err := s.jobs.Update(jobID, func(job *models.EvaluationJob) bool {
+		if !heldBy(job, workerID) {
+			return false
+		}
+		apply(job)
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("failed to save checkpoint: %w: job %s is no longer held by %s", ErrLeaseLost, jobID, workerID)
+	}
 	return nil
 }
 
-// GetJobsByStatus retrieves jobs by status with pagination
-func (s *EvaluationService) GetJobsByStatus(status models.JobStatus, limit, offset int) ([]models.EvaluationJob, error) {
-	var jobs []models.EvaluationJob
+func setCVResult(job *models.EvaluationJob, result models.RubricResult) {
+	job.CVMatchRate = sql.NullFloat64{Float64: result.Score, Valid: true}
+	job.CVFeedback = sql.NullString{String: result.Feedback, Valid: true}
+	job.CVScoreBreakdown = result.Breakdown
+}
+
+func setProjectResult(job *models.EvaluationJob, result models.RubricResult) {
+	job.ProjectScore = sql.NullFloat64{Float64: result.Score, Valid: true}
+	job.ProjectFeedback = sql.NullString{String: result.Feedback, Valid: true}
+	job.ProjectScoreBreakdown = result.Breakdown
+}
 
-	query := database.DB.Where("status = ?", status).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset)
+// CompleteJob marks a job held by workerID as completed with results. A job
+// that was cancelled, or requeued and possibly claimed by another worker,
+// yields ErrLeaseLost and is left untouched.
+func (s *EvaluationService) CompleteJob(jobID, workerID string, cvResult, projectResult models.RubricResult, overallSummary string) error {
+	ok, err := s.jobs.Update(jobID, func(job *models.EvaluationJob) bool {
+		if !heldBy(job, workerID) {
+			return false
+		}
+		setCVResult(job, cvResult)
+		setProjectResult(job, projectResult)
+		job.OverallSummary = sql.NullString{String: overallSummary, Valid: true}
+		finish(job, models.JobStatusCompleted, time.Now())
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update job results: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("failed to update job results: %w: job %s is no longer held by %s", ErrLeaseLost, jobID, workerID)
+	}
+
+	return nil
+}
+
+// FailJob marks a job as failed with error message. A non-nil history
+// replaces the stored per-attempt error history.
+func (s *EvaluationService) FailJob(jobID string, errorMsg string, history models.AttemptHistory) error {
+	return s.finishUnsuccessfully(jobID, models.JobStatusFailed, errorMsg, history)
+}
 
-	if err := query.Find(&jobs).Error; err != nil {
-		return nil, fmt.Errorf("failed to retrieve jobs: %w", err)
+// DeadLetterJob parks a job whose retries are exhausted for manual review
+func (s *EvaluationService) DeadLetterJob(jobID string, errorMsg string, history models.AttemptHistory) error {
+	return s.finishUnsuccessfully(jobID, models.JobStatusDeadLetter, errorMsg, history)
+}
+
+func (s *EvaluationService) finishUnsuccessfully(jobID string, status models.JobStatus, errorMsg string, history models.AttemptHistory) error {
+	if _, err := s.jobs.Update(jobID, func(job *models.EvaluationJob) bool {
+		if job.Status != models.JobStatusQueued && job.Status != models.JobStatusProcessing {
+			return false
+		}
+		job.ErrorMessage = sql.NullString{String: errorMsg, Valid: true}
+		if history != nil {
+			job.ErrorHistory = history
+		}
+		finish(job, status, time.Now())
+		return true
+	}); err != nil {
+		return fmt.Errorf("failed to update job status: %w", err)
 	}
 
-	return jobs, nil
+	return nil
 }
 
-// GetAllJobs retrieves all jobs with pagination
-func (s *EvaluationService) GetAllJobs(limit, offset int) ([]models.EvaluationJob, int64, error) {
-	var jobs []models.EvaluationJob
-	var total int64
+// RetryJob returns a failed attempt to the queue; it will not be claimed
+// again before nextAttemptAt
+func (s *EvaluationService) RetryJob(jobID string, errorMsg string, history models.AttemptHistory, nextAttemptAt time.Time) error {
+	if _, err := s.jobs.Update(jobID, func(job *models.EvaluationJob) bool {
+		if job.Status != models.JobStatusProcessing {
+			return false
+		}
+		job.Status = models.JobStatusQueued
+		job.ErrorMessage = sql.NullString{String: errorMsg, Valid: true}
+		job.ErrorHistory = history
+		job.NextAttemptAt = sql.NullTime{Time: nextAttemptAt, Valid: true}
+		releaseLease(job)
+		return true
+	}); err != nil {
+		return fmt.Errorf("failed to schedule retry: %w", err)
+	}
+	return nil
+}
 
-	// Count total
-	if err := database.DB.Model(&models.EvaluationJob{}).Count(&total).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to count jobs: %w", err)
+// CancelJob marks a queued or processing job as cancelled and returns the
+// status it had before. Terminal jobs yield ErrJobNotCancellable.
+func (s *EvaluationService) CancelJob(jobID string, scope Scope) (models.JobStatus, error) {
+	var previous models.JobStatus
+	owned := true
+	_, err := s.jobs.Update(jobID, func(job *models.EvaluationJob) bool {
+		if owned = scope.ownsRecord(job.OrganizationID, job.OwnerKeyID, job.OwnerOnly); !owned {
+			return false
+		}
+		previous = job.Status
+		if job.Status.IsTerminal() {
+			return false
+		}
+		job.ErrorMessage = sql.NullString{String: "cancelled by user", Valid: true}
+		finish(job, models.JobStatusCancelled, time.Now())
+		return true
+	})
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && !owned) {
+		return "", fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if previous.IsTerminal() {
+		return previous, fmt.Errorf("%w: status is %s", ErrJobNotCancellable, previous)
 	}
 
-	// Get jobs
-	query := database.DB.Order("created_at DESC").
-		Limit(limit).
-		Offset(offset)
+	return previous, nil
+}
 
-	if err := query.Find(&jobs).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to retrieve jobs: %w", err)
+// RerunJob queues a new job that evaluates the same CV and project report
+// against the ground truth the original job used: the recorded chunks when the
+// original got that far, otherwise the same pinned document versions. Re-runs
+// are for audit and never notify a callback URL.
+func (s *EvaluationService) RerunJob(jobID string, scope Scope) (*models.EvaluationJob, error) {
+	original, err := s.GetJobInScope(jobID, scope)
+	if err != nil {
+		return nil, err
+	}
+	if !original.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: status is %s", ErrJobNotReplayable, original.Status)
+	}
+	if original.GroundTruthRefs == nil && original.RetrievedChunks == nil {
+		return nil, fmt.Errorf("%w: job %s has no pinned ground truth", ErrJobNotReplayable, jobID)
+	}
+
+	job := &models.EvaluationJob{
+		CVDocumentID:      original.CVDocumentID,
+		ReportDocumentID:  original.ReportDocumentID,
+		JobTitleEvaluated: original.JobTitleEvaluated,
+		Status:            models.JobStatusQueued,
+		GroundTruthRefs:   original.GroundTruthRefs,
+		RetrievedChunks:   original.RetrievedChunks,
+		RerunOfJobID:      sql.NullString{String: original.ID, Valid: true},
+		OrganizationID:    original.OrganizationID,
+		OwnerKeyID:        scope.ownerKeyID(),
 	}
+	if err := s.jobs.Create(job); err != nil {
+		return nil, fmt.Errorf("failed to create evaluation job: %w", err)
+	}
+	return job, nil
+}
 
+// GetJobsByStatus retrieves jobs by status with pagination
+func (s *EvaluationService) GetJobsByStatus(status models.JobStatus, limit, offset int) ([]models.EvaluationJob, error) {
+	jobs, _, err := s.ListJobs(JobFilter{
+		Statuses: []models.JobStatus{status},
+		Limit:    limit,
+		Offset:   offset,
+	}, Scope{})
+	return jobs, err
+}
+
+// GetAllJobs retrieves all jobs with pagination
+func (s *EvaluationService) GetAllJobs(limit, offset int) ([]models.EvaluationJob, int64, error) {
+	return s.ListJobs(JobFilter{Limit: limit, Offset: offset}, Scope{})
+}
+
+// ListJobs returns one page of jobs matching the filter and the total match count
+func (s *EvaluationService) ListJobs(filter JobFilter, scope Scope) ([]models.EvaluationJob, int64, error) {
+	filter.OrganizationID, filter.OwnerKeyID = scope.OrganizationID, scope.APIKeyID
+	jobs, total, err := s.jobs.List(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve jobs: %w", err)
+	}
 	return jobs, total, nil
 }
 
-// GetJobStats returns statistics about evaluation jobs
-func (s *EvaluationService) GetJobStats() (map[string]int64, error) {
-	stats := make(map[string]int64)
+// JobStats summarises jobs matching a filter
+type JobStats struct {
+	Total    int64            `json:"total"`
+	ByStatus map[string]int64 `json:"by_status"`
+
+	// Averages over completed jobs only; nil when there are none
+	AverageCVMatchRate    *float64 `json:"average_cv_match_rate"`
+	AverageProjectScore   *float64 `json:"average_project_score"`
+	AverageProcessingSecs *float64 `json:"average_processing_seconds"`
+	AverageTurnaroundSecs *float64 `json:"average_turnaround_seconds"`
+}
+
+// GetJobStats returns counts per status, average scores and average processing
+// time (started_at → completed_at of the final attempt) and turnaround time
+// (created_at → completed_at, including queueing and retries)
+func (s *EvaluationService) GetJobStats(filter JobFilter, scope Scope) (*JobStats, error) {
+	stats := &JobStats{ByStatus: make(map[string]int64)}
+	filter.OrganizationID, filter.OwnerKeyID = scope.OrganizationID, scope.APIKeyID
 
-	statuses := []models.JobStatus{
-		models.JobStatusQueued,
-		models.JobStatusProcessing,
-		models.JobStatusCompleted,
-		models.JobStatusFailed,
+	counts, err := s.jobs.CountByStatus(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs by status: %w", err)
+	}
+	for _, status := range models.AllJobStatuses {
+		stats.ByStatus[string(status)] = counts[status]
+		stats.Total += counts[status]
+	}
+
+	completed := filter
+	completed.Statuses = []models.JobStatus{models.JobStatusCompleted}
+	timings, err := s.jobs.Timings(completed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job timings: %w", err)
 	}
 
-	for _, status := range statuses {
-		var count int64
-		if err := database.DB.Model(&models.EvaluationJob{}).
-			Where("status = ?", status).
-			Count(&count).Error; err != nil {
-			return nil, fmt.Errorf("failed to count status %s: %w", status, err)
+	var cvSum, projectSum float64
+	var cvCount, projectCount int
+	var processing, turnaround time.Duration
+	var processed, turned int
+	for _, t := range timings {
+		if t.CVMatchRate.Valid {
+			cvSum += t.CVMatchRate.Float64
+			cvCount++
+		}
+		if t.ProjectScore.Valid {
+			projectSum += t.ProjectScore.Float64
+			projectCount++
 		}
-		stats[string(status)] = count
+		if !t.CompletedAt.Valid {
+			continue
+		}
+		if t.StartedAt.Valid {
+			processing += t.CompletedAt.Time.Sub(t.StartedAt.Time)
+			processed++
+		}
+		turnaround += t.CompletedAt.Time.Sub(t.CreatedAt)
+		turned++
 	}
 
-	// Total count
-	var total int64
-	if err := database.DB.Model(&models.EvaluationJob{}).Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("failed to count total: %w", err)
+	stats.AverageCVMatchRate = average(cvSum, cvCount)
+	stats.AverageProjectScore = average(projectSum, projectCount)
+	if processed > 0 {
+		stats.AverageProcessingSecs = average(processing.Seconds(), processed)
+	}
+	if turned > 0 {
+		stats.AverageTurnaroundSecs = average(turnaround.Seconds(), turned)
 	}
-	stats["total"] = total
 
 	return stats, nil
 }
 
+func average(sum float64, count int) *float64 {
+	if count == 0 {
+		return nil
+	}
+	avg := sum / float64(count)
+	return &avg
+}
+
 // DeleteJob removes an evaluation job
 func (s *EvaluationService) DeleteJob(jobID string) error {
-	if err := database.DB.Delete(&models.EvaluationJob{}, "id = ?", jobID).Error; err != nil {
+	if _, err := s.jobs.Delete(jobID, func(*models.EvaluationJob) bool { return true }); err != nil {
 		return fmt.Errorf("failed to delete job: %w", err)
 	}
 	return nil
 }
 
-// GetRecentJobs retrieves the most recent jobs
-func (s *EvaluationService) GetRecentJobs(limit int) ([]models.EvaluationJob, error) {
-	var jobs []models.EvaluationJob
-
-	if err := database.DB.Order("created_at DESC").
-		Limit(limit).
-		Find(&jobs).Error; err != nil {
-		return nil, fmt.Errorf("failed to retrieve recent jobs: %w", err)
+// DeleteQueuedJob removes a job only if no worker has claimed it yet.
+// It reports whether the job was deleted.
+func (s *EvaluationService) DeleteQueuedJob(jobID string) (bool, error) {
+	deleted, err := s.jobs.Delete(jobID, func(job *models.EvaluationJob) bool {
+		return job.Status == models.JobStatusQueued
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete queued job: %w", err)
 	}
+	return deleted, nil
+}
 
-	return jobs, nil
+// GetRecentJobs retrieves the most recent jobs
+func (s *EvaluationService) GetRecentJobs(limit int) ([]models.EvaluationJob, error) {
+	jobs, _, err := s.ListJobs(JobFilter{Limit: limit}, Scope{})
+	return jobs, err
 }
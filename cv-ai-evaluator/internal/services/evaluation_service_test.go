@@ -0,0 +1,651 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"cv-ai-evaluator/config"
+	"cv-ai-evaluator/internal/database"
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// serviceEnv is an EvaluationService over one repository implementation, with
+// a CV and a project report jobs can refer to
+type serviceEnv struct {
+	service  *EvaluationService
+	jobs     repository.JobRepository
+	cvID     string
+	reportID string
+}
+
+// forEachBackend runs test against the in-memory and the SQLite repositories,
+// so that both implementations keep the same semantics
+func forEachBackend(t *testing.T, test func(t *testing.T, env *serviceEnv)) {
+	backends := []struct {
+		name string
+		open func(t *testing.T) (repository.DocumentRepository, repository.JobRepository)
+	}{
+		{"memory", func(t *testing.T) (repository.DocumentRepository, repository.JobRepository) {
+			docs := repository.NewMemoryDocumentRepository()
+			return docs, repository.NewMemoryJobRepository(docs)
+		}},
+		{"sqlite", openSQLite},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			docs, jobs := backend.open(t)
+			cv := &models.UploadedDocument{FilePath: "cv.pdf", OriginalFilename: "cv.pdf", DocumentType: models.DocumentTypeCV}
+			report := &models.UploadedDocument{FilePath: "report.pdf", OriginalFilename: "report.pdf", DocumentType: models.DocumentTypeProjectReport}
+			for _, doc := range []*models.UploadedDocument{cv, report} {
+				if err := docs.Create(doc); err != nil {
+					t.Fatalf("create document: %v", err)
+				}
+			}
+			env := &serviceEnv{service: NewEvaluationService(jobs), jobs: jobs, cvID: cv.ID, reportID: report.ID}
+			test(t, env)
+		})
+	}
+}
+
+func openSQLite(t *testing.T) (repository.DocumentRepository, repository.JobRepository) {
+	cfg := &config.Config{DBDriver: config.DBDriverSQLite, DBPath: filepath.Join(t.TempDir(), "test.db")}
+	db, err := gorm.Open(sqlite.Open(cfg.GetDSN()), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := database.MigrateUp(db, 0); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	jobs, err := repository.NewGormJobRepository(db)
+	if err != nil {
+		t.Fatalf("job repository: %v", err)
+	}
+	return repository.NewGormDocumentRepository(db), jobs
+}
+
+// createJob stores a queued job for the env's documents after applying edit
+func (e *serviceEnv) createJob(t *testing.T, edit func(job *models.EvaluationJob)) *models.EvaluationJob {
+	t.Helper()
+	job := &models.EvaluationJob{
+		CVDocumentID:      e.cvID,
+		ReportDocumentID:  e.reportID,
+		JobTitleEvaluated: "Backend Engineer",
+		Status:            models.JobStatusQueued,
+	}
+	if edit != nil {
+		edit(job)
+	}
+	if err := e.jobs.Create(job); err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	return job
+}
+
+func (e *serviceEnv) job(t *testing.T, id string) *models.EvaluationJob {
+	t.Helper()
+	job, err := e.jobs.FindByID(id)
+	if err != nil {
+		t.Fatalf("find job %s: %v", id, err)
+	}
+	return job
+}
+
+func TestHeldBy(t *testing.T) {
+	tests := []struct {
+		name   string
+		status models.JobStatus
+		owner  string
+		want   bool
+	}{
+		{"processing, same worker", models.JobStatusProcessing, "worker-1", true},
+		{"processing, other worker", models.JobStatusProcessing, "worker-2", false},
+		{"processing, no lease", models.JobStatusProcessing, "", false},
+		{"requeued", models.JobStatusQueued, "", false},
+		{"cancelled", models.JobStatusCancelled, "", false},
+		{"completed", models.JobStatusCompleted, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &models.EvaluationJob{Status: tt.status, LeaseOwner: sql.NullString{String: tt.owner, Valid: tt.owner != ""}}
+			if got := heldBy(job, "worker-1"); got != tt.want {
+				t.Fatalf("heldBy = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaimNextJob(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		jobs []func(job *models.EvaluationJob)
+		want int // index of the claimed job, -1 for none
+	}{
+		{
+			name: "oldest queued job first",
+			jobs: []func(job *models.EvaluationJob){
+				func(job *models.EvaluationJob) { job.CreatedAt = now.Add(-time.Minute) },
+				func(job *models.EvaluationJob) { job.CreatedAt = now.Add(-2 * time.Minute) },
+			},
+			want: 1,
+		},
+		{
+			name: "skips job waiting for its retry backoff",
+			jobs: []func(job *models.EvaluationJob){
+				func(job *models.EvaluationJob) {
+					job.CreatedAt = now.Add(-2 * time.Minute)
+					job.NextAttemptAt = sql.NullTime{Time: now.Add(time.Hour), Valid: true}
+				},
+				func(job *models.EvaluationJob) { job.CreatedAt = now.Add(-time.Minute) },
+			},
+			want: 1,
+		},
+		{
+			name: "retry whose backoff has passed",
+			jobs: []func(job *models.EvaluationJob){
+				func(job *models.EvaluationJob) {
+					job.Attempts = 1
+					job.NextAttemptAt = sql.NullTime{Time: now.Add(-time.Second), Valid: true}
+				},
+			},
+			want: 0,
+		},
+		{
+			name: "nothing queued",
+			jobs: []func(job *models.EvaluationJob){
+				func(job *models.EvaluationJob) { job.Status = models.JobStatusCompleted },
+				func(job *models.EvaluationJob) { job.Status = models.JobStatusProcessing },
+			},
+			want: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, env *serviceEnv) {
+				var created []*models.EvaluationJob
+				for _, edit := range tt.jobs {
+					created = append(created, env.createJob(t, edit))
+				}
+
+				claimed, err := env.service.ClaimNextJob("worker-1", time.Minute)
+				if err != nil {
+					t.Fatalf("ClaimNextJob: %v", err)
+				}
+				if tt.want < 0 {
+					if claimed != nil {
+						t.Fatalf("claimed job %s, want none", claimed.ID)
+					}
+					return
+				}
+				if claimed == nil || claimed.ID != created[tt.want].ID {
+					t.Fatalf("claimed %v, want job %s", claimed, created[tt.want].ID)
+				}
+
+				job := env.job(t, claimed.ID)
+				if job.Status != models.JobStatusProcessing || job.LeaseOwner.String != "worker-1" {
+					t.Fatalf("status %s, lease owner %q; want processing, worker-1", job.Status, job.LeaseOwner.String)
+				}
+				if !job.LeaseExpiresAt.Valid || !job.LeaseExpiresAt.Time.After(time.Now()) {
+					t.Fatalf("lease expires at %v, want a future time", job.LeaseExpiresAt)
+				}
+				if job.NextAttemptAt.Valid || job.Attempts != created[tt.want].Attempts+1 {
+					t.Fatalf("attempts %d, next attempt %v; want %d and no backoff", job.Attempts, job.NextAttemptAt, created[tt.want].Attempts+1)
+				}
+			})
+		})
+	}
+}
+
+// TestLeaseGuards checks that only the worker holding a job's lease can renew,
+// release or checkpoint it, and that expired leases are requeued
+func TestLeaseGuards(t *testing.T) {
+	tests := []struct {
+		name    string
+		lease   time.Duration
+		cancel  bool
+		op      func(s *EvaluationService, jobID string) error
+		wantErr error
+
+		wantStatus   models.JobStatus
+		wantOwner    string
+		wantAttempts int
+		wantCVText   string
+	}{
+		{
+			name:       "holder renews",
+			op:         func(s *EvaluationService, id string) error { return s.RenewLease(id, "worker-1", time.Hour) },
+			wantStatus: models.JobStatusProcessing, wantOwner: "worker-1", wantAttempts: 1,
+		},
+		{
+			name:       "other worker cannot renew",
+			op:         func(s *EvaluationService, id string) error { return s.RenewLease(id, "worker-2", time.Hour) },
+			wantErr:    ErrLeaseLost,
+			wantStatus: models.JobStatusProcessing, wantOwner: "worker-1", wantAttempts: 1,
+		},
+		{
+			name:       "cancelled job cannot be renewed",
+			cancel:     true,
+			op:         func(s *EvaluationService, id string) error { return s.RenewLease(id, "worker-1", time.Hour) },
+			wantErr:    ErrLeaseLost,
+			wantStatus: models.JobStatusCancelled, wantAttempts: 1,
+		},
+		{
+			name:       "holder releases without using an attempt",
+			op:         func(s *EvaluationService, id string) error { return s.ReleaseJob(id, "worker-1") },
+			wantStatus: models.JobStatusQueued, wantAttempts: 0,
+		},
+		{
+			name:       "release by other worker is a no-op",
+			op:         func(s *EvaluationService, id string) error { return s.ReleaseJob(id, "worker-2") },
+			wantStatus: models.JobStatusProcessing, wantOwner: "worker-1", wantAttempts: 1,
+		},
+		{
+			name:       "release of cancelled job is a no-op",
+			cancel:     true,
+			op:         func(s *EvaluationService, id string) error { return s.ReleaseJob(id, "worker-1") },
+			wantStatus: models.JobStatusCancelled, wantAttempts: 1,
+		},
+		{
+			name: "holder saves checkpoint",
+			op: func(s *EvaluationService, id string) error {
+				return s.SaveExtractedText(id, "worker-1", "cv", "report")
+			},
+			wantStatus: models.JobStatusProcessing, wantOwner: "worker-1", wantAttempts: 1, wantCVText: "cv",
+		},
+		{
+			name: "other worker cannot save checkpoint",
+			op: func(s *EvaluationService, id string) error {
+				return s.SaveExtractedText(id, "worker-2", "cv", "report")
+			},
+			wantErr:    ErrLeaseLost,
+			wantStatus: models.JobStatusProcessing, wantOwner: "worker-1", wantAttempts: 1,
+		},
+		{
+			name:   "checkpoint of cancelled job is dropped",
+			cancel: true,
+			op: func(s *EvaluationService, id string) error {
+				return s.SaveExtractedText(id, "worker-1", "cv", "report")
+			},
+			wantErr:    ErrLeaseLost,
+			wantStatus: models.JobStatusCancelled, wantAttempts: 1,
+		},
+		{
+			name: "holder completes",
+			op: func(s *EvaluationService, id string) error {
+				return s.CompleteJob(id, "worker-1", models.RubricResult{Score: 0.7}, models.RubricResult{Score: 3.5}, "hire")
+			},
+			wantStatus: models.JobStatusCompleted, wantAttempts: 1,
+		},
+		{
+			name: "other worker cannot complete",
+			op: func(s *EvaluationService, id string) error {
+				return s.CompleteJob(id, "worker-2", models.RubricResult{Score: 0.7}, models.RubricResult{Score: 3.5}, "hire")
+			},
+			wantErr:    ErrLeaseLost,
+			wantStatus: models.JobStatusProcessing, wantOwner: "worker-1", wantAttempts: 1,
+		},
+		{
+			name:   "cancelled job cannot be completed",
+			cancel: true,
+			op: func(s *EvaluationService, id string) error {
+				return s.CompleteJob(id, "worker-1", models.RubricResult{Score: 0.7}, models.RubricResult{Score: 3.5}, "hire")
+			},
+			wantErr:    ErrLeaseLost,
+			wantStatus: models.JobStatusCancelled, wantAttempts: 1,
+		},
+		{
+			name:  "stale worker cannot complete a reclaimed job",
+			lease: -time.Minute,
+			op: func(s *EvaluationService, id string) error {
+				if _, err := s.RequeueExpiredJobs(); err != nil {
+					return err
+				}
+				if claimed, err := s.ClaimNextJob("worker-2", time.Minute); err != nil || claimed == nil {
+					return fmt.Errorf("reclaim: %v, %v", claimed, err)
+				}
+				return s.CompleteJob(id, "worker-1", models.RubricResult{Score: 0.7}, models.RubricResult{Score: 3.5}, "hire")
+			},
+			wantErr:    ErrLeaseLost,
+			wantStatus: models.JobStatusProcessing, wantOwner: "worker-2", wantAttempts: 2,
+		},
+		{
+			name:       "expired lease is requeued",
+			lease:      -time.Minute,
+			op:         func(s *EvaluationService, _ string) error { _, err := s.RequeueExpiredJobs(); return err },
+			wantStatus: models.JobStatusQueued, wantAttempts: 1,
+		},
+		{
+			name:       "live lease is not requeued",
+			op:         func(s *EvaluationService, _ string) error { _, err := s.RequeueExpiredJobs(); return err },
+			wantStatus: models.JobStatusProcessing, wantOwner: "worker-1", wantAttempts: 1,
+		},
+		{
+			name:  "expired lease cannot be checkpointed after requeue",
+			lease: -time.Minute,
+			op: func(s *EvaluationService, id string) error {
+				if _, err := s.RequeueExpiredJobs(); err != nil {
+					return err
+				}
+				return s.SaveExtractedText(id, "worker-1", "cv", "report")
+			},
+			wantErr:    ErrLeaseLost,
+			wantStatus: models.JobStatusQueued, wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, env *serviceEnv) {
+				lease := tt.lease
+				if lease == 0 {
+					lease = time.Minute
+				}
+				env.createJob(t, nil)
+				claimed, err := env.service.ClaimNextJob("worker-1", lease)
+				if err != nil || claimed == nil {
+					t.Fatalf("ClaimNextJob = %v, %v", claimed, err)
+				}
+				if tt.cancel {
+					if _, err := env.service.CancelJob(claimed.ID, Scope{}); err != nil {
+						t.Fatalf("CancelJob: %v", err)
+					}
+				}
+
+				err = tt.op(env.service, claimed.ID)
+				if tt.wantErr == nil && err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+
+				job := env.job(t, claimed.ID)
+				if job.Status != tt.wantStatus || job.LeaseOwner.String != tt.wantOwner || job.Attempts != tt.wantAttempts {
+					t.Fatalf("status %s, lease owner %q, attempts %d; want %s, %q, %d",
+						job.Status, job.LeaseOwner.String, job.Attempts, tt.wantStatus, tt.wantOwner, tt.wantAttempts)
+				}
+				if job.CVText.String != tt.wantCVText {
+					t.Fatalf("cv text %q, want %q", job.CVText.String, tt.wantCVText)
+				}
+			})
+		})
+	}
+}
+
+func TestSaveCheckpoints(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *serviceEnv) {
+		env.createJob(t, nil)
+		claimed, err := env.service.ClaimNextJob("worker-1", time.Minute)
+		if err != nil || claimed == nil {
+			t.Fatalf("ClaimNextJob = %v, %v", claimed, err)
+		}
+
+		chunks := models.RetrievedChunks{{Type: models.GroundTruthTypeJobDescription, Content: "Go, SQL"}}
+		cv := models.RubricResult{Score: 0.7, Feedback: "solid backend", Breakdown: models.ScoreBreakdown{{Parameter: "technical_skills", Score: 4}}}
+		project := models.RubricResult{Score: 3.5, Feedback: "works"}
+		for name, save := range map[string]func() error{
+			"chunks":  func() error { return env.service.SaveRetrievedChunks(claimed.ID, "worker-1", chunks) },
+			"cv":      func() error { return env.service.SaveCVResult(claimed.ID, "worker-1", cv) },
+			"project": func() error { return env.service.SaveProjectResult(claimed.ID, "worker-1", project) },
+			"summary": func() error { return env.service.SaveSummary(claimed.ID, "worker-1", "hire") },
+		} {
+			if err := save(); err != nil {
+				t.Fatalf("save %s: %v", name, err)
+			}
+		}
+
+		job := env.job(t, claimed.ID)
+		if len(job.RetrievedChunks) != 1 || job.RetrievedChunks[0].Content != "Go, SQL" {
+			t.Fatalf("retrieved chunks = %+v", job.RetrievedChunks)
+		}
+		if job.CVMatchRate.Float64 != 0.7 || job.CVFeedback.String != "solid backend" || !job.CVEvaluatedAt.Valid || len(job.CVScoreBreakdown) != 1 {
+			t.Fatalf("cv result = %v, %q, %v, %+v", job.CVMatchRate, job.CVFeedback.String, job.CVEvaluatedAt, job.CVScoreBreakdown)
+		}
+		if job.ProjectScore.Float64 != 3.5 || !job.ProjectEvaluatedAt.Valid {
+			t.Fatalf("project result = %v, %v", job.ProjectScore, job.ProjectEvaluatedAt)
+		}
+		if job.OverallSummary.String != "hire" || !job.SummarizedAt.Valid {
+			t.Fatalf("summary = %q, %v", job.OverallSummary.String, job.SummarizedAt)
+		}
+	})
+}
+
+func TestCancelJob(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       models.JobStatus
+		scope        Scope
+		unknown      bool
+		wantPrevious models.JobStatus
+		wantErr      error
+		wantStatus   models.JobStatus
+	}{
+		{"queued", models.JobStatusQueued, Scope{}, false, models.JobStatusQueued, nil, models.JobStatusCancelled},
+		{"processing", models.JobStatusProcessing, Scope{}, false, models.JobStatusProcessing, nil, models.JobStatusCancelled},
+		{"own organisation", models.JobStatusQueued, Scope{OrganizationID: models.DefaultOrganizationID}, false, models.JobStatusQueued, nil, models.JobStatusCancelled},
+		{"completed", models.JobStatusCompleted, Scope{}, false, models.JobStatusCompleted, ErrJobNotCancellable, models.JobStatusCompleted},
+		{"already cancelled", models.JobStatusCancelled, Scope{}, false, models.JobStatusCancelled, ErrJobNotCancellable, models.JobStatusCancelled},
+		{"other organisation", models.JobStatusQueued, Scope{OrganizationID: "acme"}, false, "", ErrJobNotFound, models.JobStatusQueued},
+		{"unknown job", models.JobStatusQueued, Scope{}, true, "", ErrJobNotFound, models.JobStatusQueued},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, env *serviceEnv) {
+				created := env.createJob(t, func(job *models.EvaluationJob) { job.Status = tt.status })
+				id := created.ID
+				if tt.unknown {
+					id = "00000000-0000-0000-0000-000000000000"
+				}
+
+				previous, err := env.service.CancelJob(id, tt.scope)
+				if tt.wantErr == nil && err != nil {
+					t.Fatalf("CancelJob: %v", err)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CancelJob err = %v, want %v", err, tt.wantErr)
+				}
+				if previous != tt.wantPrevious {
+					t.Fatalf("previous status %q, want %q", previous, tt.wantPrevious)
+				}
+				if job := env.job(t, created.ID); job.Status != tt.wantStatus {
+					t.Fatalf("status %s, want %s", job.Status, tt.wantStatus)
+				}
+			})
+		})
+	}
+}
+
+func TestRerunJob(t *testing.T) {
+	refs := models.GroundTruthRefs{{ID: "gt-1", Type: models.GroundTruthTypeJobDescription, Name: "Backend", Version: "v1"}}
+	chunks := models.RetrievedChunks{{Type: models.GroundTruthTypeJobDescription, Content: "Go, SQL"}}
+	tests := []struct {
+		name    string
+		status  models.JobStatus
+		refs    models.GroundTruthRefs
+		chunks  models.RetrievedChunks
+		scope   Scope
+		wantErr error
+	}{
+		{"completed with pinned ground truth", models.JobStatusCompleted, refs, nil, Scope{}, nil},
+		{"failed with recorded chunks", models.JobStatusFailed, nil, chunks, Scope{}, nil},
+		{"own organisation", models.JobStatusCompleted, refs, chunks, Scope{OrganizationID: models.DefaultOrganizationID, APIKeyID: "key-2"}, nil},
+		{"still running", models.JobStatusProcessing, refs, nil, Scope{}, ErrJobNotReplayable},
+		{"no pinned ground truth", models.JobStatusCompleted, nil, nil, Scope{}, ErrJobNotReplayable},
+		{"other organisation", models.JobStatusCompleted, refs, nil, Scope{OrganizationID: "acme"}, ErrJobNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, env *serviceEnv) {
+				original := env.createJob(t, func(job *models.EvaluationJob) {
+					job.Status = tt.status
+					job.GroundTruthRefs = tt.refs
+					job.RetrievedChunks = tt.chunks
+					job.CallbackURL = sql.NullString{String: "https://hooks.example.com/cv", Valid: true}
+				})
+
+				rerun, err := env.service.RerunJob(original.ID, tt.scope)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("RerunJob err = %v, want %v", err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("RerunJob: %v", err)
+				}
+
+				job := env.job(t, rerun.ID)
+				if job.Status != models.JobStatusQueued || job.RerunOfJobID.String != original.ID {
+					t.Fatalf("rerun status %s of %q, want queued of %s", job.Status, job.RerunOfJobID.String, original.ID)
+				}
+				if job.CVDocumentID != original.CVDocumentID || job.OrganizationID != original.OrganizationID {
+					t.Fatalf("rerun documents/organisation differ from the original")
+				}
+				if len(job.GroundTruthRefs) != len(tt.refs) || len(job.RetrievedChunks) != len(tt.chunks) {
+					t.Fatalf("rerun pinned %d refs and %d chunks, want %d and %d", len(job.GroundTruthRefs), len(job.RetrievedChunks), len(tt.refs), len(tt.chunks))
+				}
+				if job.CallbackURL.Valid {
+					t.Fatalf("rerun kept callback URL %q", job.CallbackURL.String)
+				}
+			})
+		})
+	}
+}
+
+func TestListJobs(t *testing.T) {
+	base := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return base.Add(time.Duration(hours) * time.Hour) }
+	score := func(v float64) sql.NullFloat64 { return sql.NullFloat64{Float64: v, Valid: true} }
+	fixtures := []models.EvaluationJob{
+		{ID: "job-a", JobTitleEvaluated: "Backend Engineer", Status: models.JobStatusCompleted, CreatedAt: at(0),
+			CompletedAt: sql.NullTime{Time: at(5), Valid: true}, CVMatchRate: score(0.8), ProjectScore: score(4.5)},
+		{ID: "job-b", JobTitleEvaluated: "Senior Backend Engineer", Status: models.JobStatusCompleted, CreatedAt: at(1),
+			CompletedAt: sql.NullTime{Time: at(2), Valid: true}, CVMatchRate: score(0.5), ProjectScore: score(3)},
+		{ID: "job-c", JobTitleEvaluated: "Frontend Engineer", Status: models.JobStatusFailed, CreatedAt: at(2),
+			CompletedAt: sql.NullTime{Time: at(3), Valid: true}},
+		{ID: "job-d", JobTitleEvaluated: "Data Engineer", Status: models.JobStatusQueued, CreatedAt: at(3), OrganizationID: "acme"},
+		{ID: "job-e", JobTitleEvaluated: "backend intern", Status: models.JobStatusProcessing, CreatedAt: at(4), CVMatchRate: score(0.8)},
+	}
+	ptr := func(v float64) *float64 { return &v }
+	timePtr := func(v time.Time) *time.Time { return &v }
+
+	tests := []struct {
+		name      string
+		filter    JobFilter
+		scope     Scope
+		want      []string
+		wantTotal int64
+	}{
+		{"everything, newest first", JobFilter{}, Scope{}, []string{"job-e", "job-d", "job-c", "job-b", "job-a"}, 5},
+		{"organisation scope", JobFilter{}, Scope{OrganizationID: models.DefaultOrganizationID}, []string{"job-e", "job-c", "job-b", "job-a"}, 4},
+		{"scope overrides filter organisation", JobFilter{OrganizationID: models.DefaultOrganizationID}, Scope{OrganizationID: "acme"}, []string{"job-d"}, 1},
+		{"statuses", JobFilter{Statuses: []models.JobStatus{models.JobStatusCompleted, models.JobStatusFailed}}, Scope{}, []string{"job-c", "job-b", "job-a"}, 3},
+		{"title ignores case", JobFilter{JobTitle: "BACKEND"}, Scope{}, []string{"job-e", "job-b", "job-a"}, 3},
+		{"created range excludes the end", JobFilter{CreatedFrom: timePtr(at(1)), CreatedTo: timePtr(at(3))}, Scope{}, []string{"job-c", "job-b"}, 2},
+		{"minimum CV match rate skips NULL", JobFilter{MinCVMatchRate: ptr(0.6)}, Scope{}, []string{"job-e", "job-a"}, 2},
+		{"maximum project score skips NULL", JobFilter{MaxProjectScore: ptr(4)}, Scope{}, []string{"job-b"}, 1},
+		{"score range", JobFilter{MinProjectScore: ptr(3), MaxCVMatchRate: ptr(0.8)}, Scope{}, []string{"job-b", "job-a"}, 2},
+		{"sort ascending puts NULL first", JobFilter{SortBy: "project_score", SortOrder: "asc"}, Scope{}, []string{"job-c", "job-d", "job-e", "job-b", "job-a"}, 5},
+		{"sort descending puts NULL last", JobFilter{SortBy: "completed_at"}, Scope{}, []string{"job-a", "job-c", "job-b", "job-d", "job-e"}, 5},
+		{"ties broken by ID", JobFilter{SortBy: "cv_match_rate", Statuses: []models.JobStatus{models.JobStatusCompleted, models.JobStatusProcessing}}, Scope{}, []string{"job-a", "job-e", "job-b"}, 3},
+		{"unknown sort column falls back to created_at", JobFilter{SortBy: "id; DROP TABLE", SortOrder: "asc"}, Scope{}, []string{"job-a", "job-b", "job-c", "job-d", "job-e"}, 5},
+		{"page", JobFilter{Limit: 2, Offset: 1}, Scope{}, []string{"job-d", "job-c"}, 5},
+		{"page past the end", JobFilter{Limit: 2, Offset: 10}, Scope{}, nil, 5},
+	}
+
+	forEachBackend(t, func(t *testing.T, env *serviceEnv) {
+		for _, fixture := range fixtures {
+			env.createJob(t, func(job *models.EvaluationJob) {
+				fixture.CVDocumentID, fixture.ReportDocumentID = job.CVDocumentID, job.ReportDocumentID
+				*job = fixture
+			})
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				jobs, total, err := env.service.ListJobs(tt.filter, tt.scope)
+				if err != nil {
+					t.Fatalf("ListJobs: %v", err)
+				}
+				var got []string
+				for _, job := range jobs {
+					got = append(got, job.ID)
+				}
+				if !slices.Equal(got, tt.want) || total != tt.wantTotal {
+					t.Fatalf("ListJobs = %v (total %d), want %v (total %d)", got, total, tt.want, tt.wantTotal)
+				}
+			})
+		}
+	})
+}
+
+// TestOwnerOnlyJobs checks that jobs from before organisations existed stay
+// visible only to the API key that created them
+func TestOwnerOnlyJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   Scope
+		visible bool
+	}{
+		{"creating key", Scope{OrganizationID: models.DefaultOrganizationID, APIKeyID: "key-1"}, true},
+		{"other key of the organisation", Scope{OrganizationID: models.DefaultOrganizationID, APIKeyID: "key-2"}, false},
+		{"other organisation", Scope{OrganizationID: "acme", APIKeyID: "key-1"}, false},
+		{"authentication disabled", Scope{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, env *serviceEnv) {
+				legacy := env.createJob(t, func(job *models.EvaluationJob) {
+					job.OwnerKeyID = sql.NullString{String: "key-1", Valid: true}
+					job.OwnerOnly = true
+				})
+				shared := env.createJob(t, func(job *models.EvaluationJob) {
+					job.OwnerKeyID = sql.NullString{String: "key-1", Valid: true}
+				})
+
+				_, err := env.service.GetJobInScope(legacy.ID, tt.scope)
+				if visible := err == nil; visible != tt.visible {
+					t.Fatalf("GetJobInScope err = %v, want visible %v", err, tt.visible)
+				}
+
+				jobs, total, err := env.service.ListJobs(JobFilter{}, tt.scope)
+				if err != nil {
+					t.Fatalf("ListJobs: %v", err)
+				}
+				listed := slices.ContainsFunc(jobs, func(job models.EvaluationJob) bool { return job.ID == legacy.ID })
+				if listed != tt.visible || int(total) != len(jobs) {
+					t.Fatalf("ListJobs listed legacy job %v (total %d of %d), want %v", listed, total, len(jobs), tt.visible)
+				}
+				if tt.scope.owns(models.DefaultOrganizationID) && !slices.ContainsFunc(jobs, func(job models.EvaluationJob) bool { return job.ID == shared.ID }) {
+					t.Fatalf("ListJobs hid job %s shared with the organisation", shared.ID)
+				}
+
+				_, err = env.service.CancelJob(legacy.ID, tt.scope)
+				if cancelled := err == nil; cancelled != tt.visible {
+					t.Fatalf("CancelJob err = %v, want cancelled %v", err, tt.visible)
+				}
+			})
+		})
+	}
+}
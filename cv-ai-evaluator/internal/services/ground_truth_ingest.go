@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cv-ai-evaluator/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// GroundTruthSource is a file the ingest command keeps in sync with an
+// organisation's ground truth. An empty DocumentName or Role keeps the value
+// of the document already ingested from Path; a new document is named after
+// the file.
+type GroundTruthSource struct {
+	Path         string
+	DocumentType models.GroundTruthType
+	Role         string
+	DocumentName string
+}
+
+// IngestAction is what a sync does, or would do, with one document
+type IngestAction string
+
+const (
+	IngestCreate    IngestAction = "create"
+	IngestUpdate    IngestAction = "update"
+	IngestUnchanged IngestAction = "unchanged"
+	IngestRemove    IngestAction = "remove"
+)
+
+// IngestChange is one line of an ingest report. Document is the version the
+// source maps to after the sync (in a dry run, the version it would get) and
+// Previous the active version it replaces or removes. Err is set when the
+// source could not be synced; the other sources are still processed.
+type IngestChange struct {
+	Action   IngestAction
+	Path     string
+	Document *models.GroundTruthDocument
+	Previous *models.GroundTruthDocument
+	Err      error
+}
+
+// IngestReport lists the changes of a sync in source order, removals last
+type IngestReport struct {
+	DryRun  bool
+	Changes []IngestChange
+}
+
+// Count returns the number of successful changes with the action
+func (r *IngestReport) Count(action IngestAction) int {
+	n := 0
+	for _, change := range r.Changes {
+		if change.Action == action && change.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the number of changes that could not be applied
+func (r *IngestReport) Failed() int {
+	n := 0
+	for _, change := range r.Changes {
+		if change.Err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// SyncSources makes the organisation's active ground truth under root match
+// sources. Documents are identified by their source path: a new file starts a
+// document, a file whose content hash, type, role or name changed becomes the
+// next version of its document (a type change retires the old document and
+// starts a new one), and active documents under root whose file is no longer
+// listed are retired. Unchanged files are not re-read or re-indexed. With
+// dryRun nothing is written and the report shows what would happen.
+func (s *GroundTruthService) SyncSources(ctx context.Context, organizationID, root string, sources []GroundTruthSource, dryRun bool) (*IngestReport, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ground truth directory: %w", err)
+	}
+
+	seen := make(map[string]bool, len(sources))
+	for i := range sources {
+		if _, err := ParseGroundTruthType(string(sources[i].DocumentType)); err != nil {
+			return nil, fmt.Errorf("%s: %w", sources[i].Path, err)
+		}
+		if err := checkGroundTruthExtension(sources[i].Path); err != nil {
+			return nil, fmt.Errorf("%s: %w", sources[i].Path, err)
+		}
+		if sources[i].Path, err = filepath.Abs(sources[i].Path); err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", sources[i].Path, err)
+		}
+		if seen[sources[i].Path] {
+			return nil, fmt.Errorf("%s is listed more than once", sources[i].Path)
+		}
+		seen[sources[i].Path] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	docs, err := s.ListDocuments(GroundTruthFilter{}, Scope{OrganizationID: organizationID})
+	if err != nil {
+		return nil, err
+	}
+
+	// the legacy ingestion script indexed the same files again on every run;
+	// the newest copy is kept and the older ones are removed as orphans
+	ingested := make(map[string]*models.GroundTruthDocument)
+	var duplicates []*models.GroundTruthDocument
+	for i := range docs {
+		path := filepath.Clean(docs[i].SourceFilePath)
+		if !withinDir(root, path) {
+			continue
+		}
+		if previous, ok := ingested[path]; ok {
+			duplicates = append(duplicates, previous)
+		}
+		ingested[path] = &docs[i] // docs are ordered oldest first
+	}
+
+	report := &IngestReport{DryRun: dryRun}
+	for _, source := range sources {
+		existing := ingested[source.Path]
+		delete(ingested, source.Path)
+		report.Changes = append(report.Changes, s.syncSource(ctx, organizationID, source, existing, dryRun))
+	}
+
+	orphans := duplicates
+	for i := range docs {
+		if ingested[filepath.Clean(docs[i].SourceFilePath)] == &docs[i] {
+			orphans = append(orphans, &docs[i])
+		}
+	}
+	for _, doc := range orphans {
+		change := IngestChange{Action: IngestRemove, Path: doc.SourceFilePath, Previous: doc}
+		if !dryRun {
+			change.Err = s.retire(ctx, doc)
+		}
+		report.Changes = append(report.Changes, change)
+	}
+	return report, nil
+}
+
+// syncSource brings the document ingested from one file up to date
+func (s *GroundTruthService) syncSource(ctx context.Context, organizationID string, source GroundTruthSource, existing *models.GroundTruthDocument, dryRun bool) IngestChange {
+	change := IngestChange{Action: IngestCreate, Path: source.Path, Previous: existing}
+
+	hash, err := hashFile(source.Path)
+	if err != nil {
+		change.Err = err
+		return change
+	}
+
+	doc := &models.GroundTruthDocument{
+		ID:             uuid.New().String(),
+		DocumentName:   strings.TrimSpace(source.DocumentName),
+		DocumentType:   source.DocumentType,
+		Role:           strings.TrimSpace(source.Role),
+		OrganizationID: organizationID,
+	}
+	if existing != nil {
+		if doc.DocumentName == "" {
+			doc.DocumentName = existing.DocumentName
+		}
+		if doc.Role == "" {
+			doc.Role = existing.Role
+		}
+		if existing.ContentHash == hash && existing.DocumentType == doc.DocumentType &&
+			existing.DocumentName == doc.DocumentName && existing.Role == doc.Role {
+			change.Action = IngestUnchanged
+			change.Document = existing
+			return change
+		}
+		change.Action = IngestUpdate
+	}
+	if doc.DocumentName == "" {
+		doc.DocumentName = strings.TrimSuffix(filepath.Base(source.Path), filepath.Ext(source.Path))
+	}
+
+	// read before a dry run reports the change, so unreadable files show up there
+	text, err := s.extractText(source.Path)
+	if err != nil {
+		change.Err = err
+		return change
+	}
+	file := groundTruthFile{path: source.Path, text: text, hash: hash}
+
+	sameDocument := existing != nil && existing.DocumentType == doc.DocumentType
+	if dryRun {
+		doc.Version = "1.0"
+		if sameDocument {
+			doc.Version, change.Err = s.resolveVersion(existing, "")
+		}
+		file.apply(doc)
+		change.Document = doc
+		return change
+	}
+
+	if sameDocument {
+		change.Document, change.Err = s.createVersion(ctx, existing, doc, file)
+		return change
+	}
+	// a document cannot change type; the old one is retired in favour of a new one
+	if existing != nil {
+		if err := s.retire(ctx, existing); err != nil {
+			change.Err = err
+			return change
+		}
+	}
+	change.Document, change.Err = s.createLineage(ctx, doc, file)
+	return change
+}
+
+// hashFile returns the hex SHA-256 of a file's content
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFileReadError, err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFileReadError, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,711 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+	"cv-ai-evaluator/pkg/utils"
+	"cv-ai-evaluator/pkg/vectordb"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrGroundTruthNotFound = errors.New("ground truth document not found")
+	// ErrJobDescriptionUnresolved means the job title matches no job
+	// description, or several; the client has to pass job_description_id
+	ErrJobDescriptionUnresolved = errors.New("job title does not identify a single job description")
+	ErrInvalidGroundTruthType   = errors.New("invalid ground truth document type")
+	ErrEmptyGroundTruth         = errors.New("ground truth document contains no text")
+	// ErrGroundTruthRetired means the version was superseded or retired; only
+	// the active version of a document can be replaced or retired
+	ErrGroundTruthRetired       = errors.New("ground truth document version is no longer active")
+	ErrGroundTruthVersionExists = errors.New("ground truth document version already exists")
+)
+
+// groundTruthExtensions are the file formats the document reader can index
+var groundTruthExtensions = []string{".md", ".txt", ".pdf"}
+
+type GroundTruthService struct {
+	groundTruth repository.GroundTruthRepository
+	index       *vectordb.ChromaClient
+	storageDir  string
+	reader      *utils.DocumentReader
+
+	// writes touch the vector store, the database and the disk; serialising
+	// them keeps a replace or retire from interleaving with another one
+	mu sync.Mutex
+}
+
+// NewGroundTruthService creates the service. Uploaded source files are kept in
+// storageDir; index receives the text used for retrieval.
+func NewGroundTruthService(groundTruth repository.GroundTruthRepository, index *vectordb.ChromaClient, storageDir string) *GroundTruthService {
+	return &GroundTruthService{
+		groundTruth: groundTruth,
+		index:       index,
+		storageDir:  storageDir,
+		reader:      utils.NewDocumentReader(),
+	}
+}
+
+// GroundTruthUpload is a ground truth file submitted for indexing. An empty
+// Version bumps the major version of the document it supersedes.
+type GroundTruthUpload struct {
+	File         *multipart.FileHeader
+	DocumentType models.GroundTruthType
+	DocumentName string
+	Role         string
+	Version      string
+}
+
+// GroundTruthFilter narrows ListDocuments
+type GroundTruthFilter struct {
+	DocumentType   models.GroundTruthType
+	Role           string
+	IncludeRetired bool
+}
+
+// ParseGroundTruthType validates a document type sent by a client
+func ParseGroundTruthType(raw string) (models.GroundTruthType, error) {
+	for _, gtType := range AllGroundTruthTypes() {
+		if string(gtType) == raw {
+			return gtType, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q (expected one of %s)", ErrInvalidGroundTruthType, raw, groundTruthTypeList())
+}
+
+// GetAllDocuments retrieves every ground truth document record
+func (s *GroundTruthService) GetAllDocuments() ([]models.GroundTruthDocument, error) {
+	docs, err := s.groundTruth.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ground truth documents: %w", err)
+	}
+	return docs, nil
+}
+
+// ListDocuments returns the scope's ground truth documents, oldest first
+func (s *GroundTruthService) ListDocuments(filter GroundTruthFilter, scope Scope) ([]models.GroundTruthDocument, error) {
+	var (
+		docs []models.GroundTruthDocument
+		err  error
+	)
+	if scope.OrganizationID == "" {
+		docs, err = s.groundTruth.FindAll()
+	} else {
+		docs, err = s.groundTruth.ListByOrganization(scope.OrganizationID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ground truth documents: %w", err)
+	}
+
+	filtered := make([]models.GroundTruthDocument, 0, len(docs))
+	for _, doc := range docs {
+		if filter.DocumentType != "" && doc.DocumentType != filter.DocumentType {
+			continue
+		}
+		if filter.Role != "" && doc.Role != filter.Role {
+			continue
+		}
+		if !filter.IncludeRetired && !doc.Active() {
+			continue
+		}
+		filtered = append(filtered, doc)
+	}
+	return filtered, nil
+}
+
+// GetDocument retrieves a ground truth document visible in the scope
+func (s *GroundTruthService) GetDocument(id string, scope Scope) (*models.GroundTruthDocument, error) {
+	doc, err := s.groundTruth.FindByID(id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && !scope.owns(doc.OrganizationID)) {
+		return nil, fmt.Errorf("%w: %s", ErrGroundTruthNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ground truth document: %w", err)
+	}
+	return doc, nil
+}
+
+// GetDocumentContent returns the indexed text of a document version. Versions
+// ingested before versioning only have their text in the vector store, so a
+// retired one of those has no content.
+func (s *GroundTruthService) GetDocumentContent(ctx context.Context, doc *models.GroundTruthDocument) (string, error) {
+	if doc.Content.Valid {
+		return doc.Content.String, nil
+	}
+	if !doc.Active() {
+		return "", nil
+	}
+	return s.index.GetDocumentContent(ctx, doc.OrganizationID, doc.ID)
+}
+
+// ChunkLegacyDocuments re-indexes active documents that are still stored in the
+// vector store as a single vector, from before documents were chunked per
+// section. It returns how many documents were re-indexed.
+func (s *GroundTruthService) ChunkLegacyDocuments(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	docs, err := s.ListDocuments(GroundTruthFilter{}, Scope{})
+	if err != nil {
+		return 0, err
+	}
+
+	chunked := 0
+	for i := range docs {
+		doc := &docs[i]
+		if s.index.IsChunked(ctx, doc.OrganizationID, doc.ID) {
+			continue
+		}
+		// listings carry no content
+		if doc, err = s.groundTruth.FindByID(doc.ID); err != nil {
+			return chunked, fmt.Errorf("failed to retrieve ground truth document: %w", err)
+		}
+		content, err := s.GetDocumentContent(ctx, doc)
+		if err != nil {
+			log.Printf("Warning: ground truth document %s is not in the vector store, skipping: %v", doc.ID, err)
+			continue
+		}
+		if err := s.index.AddDocument(ctx, doc.OrganizationID, doc.ID, content, groundTruthMetadata(doc)); err != nil {
+			return chunked, fmt.Errorf("failed to re-index ground truth document %s: %w", doc.ID, err)
+		}
+		chunked++
+	}
+	return chunked, nil
+}
+
+// ListVersions returns every version of the document id belongs to, oldest first
+func (s *GroundTruthService) ListVersions(id string, scope Scope) ([]models.GroundTruthDocument, error) {
+	doc, err := s.GetDocument(id, scope)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.groundTruth.ListVersions(doc.LineageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve ground truth versions: %w", err)
+	}
+	return versions, nil
+}
+
+// PinnedContent returns the text of the exact version a job was pinned to,
+// whether or not it is still active
+func (s *GroundTruthService) PinnedContent(ctx context.Context, organizationID, id string) (string, error) {
+	doc, err := s.GetDocument(id, Scope{OrganizationID: organizationID})
+	if err != nil {
+		return "", err
+	}
+	content, err := s.GetDocumentContent(ctx, doc)
+	if err != nil {
+		return "", err
+	}
+	if content == "" {
+		return "", fmt.Errorf("content of ground truth document %s (version %s) is no longer available", doc.ID, doc.Version)
+	}
+	return content, nil
+}
+
+// CreateDocument stores, indexes and records a ground truth document for the
+// scope's organisation. An upload with the type, role and name of an active
+// document becomes that document's next version; otherwise it starts a new
+// document at version 1.0.
+func (s *GroundTruthService) CreateDocument(ctx context.Context, upload GroundTruthUpload, scope Scope) (*models.GroundTruthDocument, error) {
+	if _, err := ParseGroundTruthType(string(upload.DocumentType)); err != nil {
+		return nil, err
+	}
+	doc := &models.GroundTruthDocument{
+		ID:             uuid.New().String(),
+		DocumentName:   strings.TrimSpace(upload.DocumentName),
+		DocumentType:   upload.DocumentType,
+		Version:        strings.TrimSpace(upload.Version),
+		Role:           strings.TrimSpace(upload.Role),
+		OrganizationID: scope.organizationID(),
+	}
+	if doc.DocumentName == "" {
+		doc.DocumentName = strings.TrimSuffix(filepath.Base(upload.File.Filename), filepath.Ext(upload.File.Filename))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	docs, err := s.ListDocuments(GroundTruthFilter{DocumentType: doc.DocumentType, Role: doc.Role}, Scope{OrganizationID: doc.OrganizationID})
+	if err != nil {
+		return nil, err
+	}
+	file, err := s.storeFile(doc.ID, upload.File)
+	if err != nil {
+		return nil, err
+	}
+	for i := range docs {
+		if docs[i].Role == doc.Role && strings.EqualFold(docs[i].DocumentName, doc.DocumentName) {
+			return s.createVersion(ctx, &docs[i], doc, file)
+		}
+	}
+	return s.createLineage(ctx, doc, file)
+}
+
+// ReplaceDocument uploads the next version of the active document id. Empty
+// DocumentName and Role keep the current values.
+func (s *GroundTruthService) ReplaceDocument(ctx context.Context, id string, upload GroundTruthUpload, scope Scope) (*models.GroundTruthDocument, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.activeDocument(id, scope)
+	if err != nil {
+		return nil, err
+	}
+	if upload.DocumentType != "" && upload.DocumentType != previous.DocumentType {
+		return nil, fmt.Errorf("%w: document %s is a %s and cannot become a %s",
+			ErrInvalidGroundTruthType, previous.ID, previous.DocumentType, upload.DocumentType)
+	}
+
+	doc := &models.GroundTruthDocument{
+		ID:             uuid.New().String(),
+		DocumentName:   strings.TrimSpace(upload.DocumentName),
+		DocumentType:   previous.DocumentType,
+		Version:        strings.TrimSpace(upload.Version),
+		Role:           strings.TrimSpace(upload.Role),
+		OrganizationID: previous.OrganizationID,
+	}
+	if doc.DocumentName == "" {
+		doc.DocumentName = previous.DocumentName
+	}
+	if doc.Role == "" {
+		doc.Role = previous.Role
+	}
+	file, err := s.storeFile(doc.ID, upload.File)
+	if err != nil {
+		return nil, err
+	}
+	return s.createVersion(ctx, previous, doc, file)
+}
+
+// groundTruthFile is the source of one document version. Uploads are copied
+// into the storage directory and owned by the service, so a version that
+// cannot be recorded removes its copy; ingested files stay where they are.
+type groundTruthFile struct {
+	path  string
+	text  string
+	hash  string
+	owned bool
+}
+
+// createLineage indexes and records doc as the first version of a new
+// document, at version 1.0 unless doc names one
+func (s *GroundTruthService) createLineage(ctx context.Context, doc *models.GroundTruthDocument, file groundTruthFile) (*models.GroundTruthDocument, error) {
+	if doc.Version == "" {
+		doc.Version = "1.0"
+	}
+	doc.LineageID = doc.ID
+	file.apply(doc)
+
+	if err := s.index.AddDocument(ctx, doc.OrganizationID, doc.ID, file.text, groundTruthMetadata(doc)); err != nil {
+		s.discard(file)
+		return nil, fmt.Errorf("failed to index ground truth document: %w", err)
+	}
+
+	if err := s.groundTruth.Create(doc); err != nil {
+		s.unindex(doc)
+		s.discard(file)
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return doc, nil
+}
+
+// createVersion makes doc the active version of previous's document. The new
+// text is indexed before the previous version leaves the vector store, and
+// both steps are undone if the database rejects the new version, so retrieval
+// always sees exactly one version. The previous source file is kept.
+func (s *GroundTruthService) createVersion(ctx context.Context, previous, doc *models.GroundTruthDocument, file groundTruthFile) (*models.GroundTruthDocument, error) {
+	version, err := s.resolveVersion(previous, doc.Version)
+	if err != nil {
+		s.discard(file)
+		return nil, err
+	}
+	doc.Version = version
+	doc.LineageID = previous.LineageID
+	file.apply(doc)
+
+	// listings carry no content; the previous text is needed to undo
+	if previous, err = s.groundTruth.FindByID(previous.ID); err != nil {
+		s.discard(file)
+		return nil, fmt.Errorf("failed to retrieve ground truth document: %w", err)
+	}
+	previousContent, contentErr := s.GetDocumentContent(ctx, previous)
+
+	if err := s.index.AddDocument(ctx, doc.OrganizationID, doc.ID, file.text, groundTruthMetadata(doc)); err != nil {
+		s.discard(file)
+		return nil, fmt.Errorf("failed to index ground truth document: %w", err)
+	}
+	if err := s.index.DeleteDocument(ctx, previous.OrganizationID, previous.ID); err != nil {
+		s.unindex(doc)
+		s.discard(file)
+		return nil, fmt.Errorf("failed to remove previous version from index: %w", err)
+	}
+
+	if err := s.groundTruth.CreateVersion(doc, previous.ID, time.Now()); err != nil {
+		if contentErr == nil && previousContent != "" {
+			s.reindex(previous, previousContent)
+		}
+		s.unindex(doc)
+		s.discard(file)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrGroundTruthRetired, previous.ID)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return doc, nil
+}
+
+// resolveVersion returns the version a successor of previous gets: requested
+// if no version of the document has it yet, or else the next free major version
+func (s *GroundTruthService) resolveVersion(previous *models.GroundTruthDocument, requested string) (string, error) {
+	versions, err := s.groundTruth.ListVersions(previous.LineageID)
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve ground truth versions: %w", err)
+	}
+	taken := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		taken[v.Version] = true
+	}
+	if requested != "" {
+		if taken[requested] {
+			return "", fmt.Errorf("%w: %s already has version %s", ErrGroundTruthVersionExists, previous.DocumentName, requested)
+		}
+		return requested, nil
+	}
+	version := nextVersion(previous.Version)
+	for taken[version] {
+		version = nextVersion(version)
+	}
+	return version, nil
+}
+
+// RetireDocument takes the active version of a document out of retrieval
+// without a successor. Its record and content stay for the jobs that were
+// evaluated against it.
+func (s *GroundTruthService) RetireDocument(ctx context.Context, id string, scope Scope) (*models.GroundTruthDocument, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.activeDocument(id, scope)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.retire(ctx, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (s *GroundTruthService) retire(ctx context.Context, doc *models.GroundTruthDocument) error {
+	// a document missing from the vector store is retired all the same
+	previousContent, contentErr := s.GetDocumentContent(ctx, doc)
+	if err := s.index.DeleteDocument(ctx, doc.OrganizationID, doc.ID); err != nil {
+		return fmt.Errorf("failed to remove ground truth document from index: %w", err)
+	}
+
+	doc.RetiredAt = sql.NullTime{Time: time.Now(), Valid: true}
+	if err := s.groundTruth.Update(doc); err != nil {
+		if contentErr == nil && previousContent != "" {
+			doc.RetiredAt = sql.NullTime{}
+			s.reindex(doc, previousContent)
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseError, err)
+	}
+	return nil
+}
+
+// activeDocument retrieves the current version of a document, the only one
+// that can be replaced or retired
+func (s *GroundTruthService) activeDocument(id string, scope Scope) (*models.GroundTruthDocument, error) {
+	doc, err := s.GetDocument(id, scope)
+	if err != nil {
+		return nil, err
+	}
+	if !doc.Active() {
+		return nil, fmt.Errorf("%w: %s (version %s)", ErrGroundTruthRetired, id, doc.Version)
+	}
+	return doc, nil
+}
+
+// storeFile validates an uploaded file, saves it under the storage directory
+// and extracts its text
+func (s *GroundTruthService) storeFile(docID string, file *multipart.FileHeader) (groundTruthFile, error) {
+	if err := checkGroundTruthExtension(file.Filename); err != nil {
+		return groundTruthFile{}, err
+	}
+	if err := os.MkdirAll(s.storageDir, 0755); err != nil {
+		return groundTruthFile{}, fmt.Errorf("failed to create ground truth directory: %w", err)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return groundTruthFile{}, fmt.Errorf("%w: %v", ErrFileReadError, err)
+	}
+	defer src.Close()
+
+	// every version has its own ID, so its source file is never overwritten
+	path := filepath.Join(s.storageDir, fmt.Sprintf("%s_%s", docID, filepath.Base(file.Filename)))
+	dst, err := os.Create(path)
+	if err != nil {
+		return groundTruthFile{}, fmt.Errorf("%w: %v", ErrFileSaveError, err)
+	}
+	hash := sha256.New()
+	_, err = io.Copy(dst, io.TeeReader(src, hash))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		s.removeFile(path)
+		return groundTruthFile{}, fmt.Errorf("%w: %v", ErrFileSaveError, err)
+	}
+
+	text, err := s.extractText(path)
+	if err != nil {
+		s.removeFile(path)
+		return groundTruthFile{}, err
+	}
+	return groundTruthFile{path: path, text: text, hash: hex.EncodeToString(hash.Sum(nil)), owned: true}, nil
+}
+
+// extractText reads the text of a ground truth file, keeping its markdown
+// structure for chunking; a file without any is rejected because it would
+// only add noise to retrieval
+func (s *GroundTruthService) extractText(path string) (string, error) {
+	text, err := s.reader.ReadRawDocument(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrFileReadError, err)
+	}
+	if text == "" {
+		return "", fmt.Errorf("%w: %s", ErrEmptyGroundTruth, filepath.Base(path))
+	}
+	return text, nil
+}
+
+func checkGroundTruthExtension(filename string) error {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, allowed := range groundTruthExtensions {
+		if ext == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: expected one of %s, got %q", ErrInvalidFileType, strings.Join(groundTruthExtensions, ", "), ext)
+}
+
+func (f groundTruthFile) apply(doc *models.GroundTruthDocument) {
+	doc.SourceFilePath = f.path
+	doc.ContentHash = f.hash
+	doc.Content = sql.NullString{String: f.text, Valid: true}
+}
+
+// discard removes the stored copy of a version that was not recorded
+func (s *GroundTruthService) discard(file groundTruthFile) {
+	if file.owned {
+		s.removeFile(file.path)
+	}
+}
+
+// reindex puts a document's previous content back after a failed write
+func (s *GroundTruthService) reindex(doc *models.GroundTruthDocument, content string) {
+	if err := s.index.AddDocument(context.Background(), doc.OrganizationID, doc.ID, content, groundTruthMetadata(doc)); err != nil {
+		log.Printf("Warning: failed to restore ground truth document %s in index: %v", doc.ID, err)
+	}
+}
+
+// unindex removes a document that could not be recorded
+func (s *GroundTruthService) unindex(doc *models.GroundTruthDocument) {
+	if err := s.index.DeleteDocument(context.Background(), doc.OrganizationID, doc.ID); err != nil {
+		log.Printf("Warning: failed to remove ground truth document %s from index: %v", doc.ID, err)
+	}
+}
+
+func (s *GroundTruthService) removeFile(path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: failed to remove ground truth file %s: %v", path, err)
+	}
+}
+
+// groundTruthMetadata is stored with the indexed text; retrieval filters on "type"
+func groundTruthMetadata(doc *models.GroundTruthDocument) map[string]string {
+	return map[string]string{
+		"type":     string(doc.DocumentType),
+		"name":     doc.DocumentName,
+		"role":     doc.Role,
+		"version":  doc.Version,
+		"lineage":  doc.LineageID,
+		"filename": filepath.Base(doc.SourceFilePath),
+	}
+}
+
+// nextVersion bumps the major part of a version such as "1.0" or "3"
+func nextVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return version + ".1"
+	}
+	return strconv.Itoa(n+1) + ".0"
+}
+
+// ResolveSet picks the active ground truth a new job is evaluated against: the job
+// description named by jobDescriptionID, or else the one jobTitle resolves to,
+// plus the latest case study brief and rubrics of the same role (falling back
+// to documents without a role). An organisation without job descriptions gets
+// no job description ref and the worker uses its generic fallback text.
+func (s *GroundTruthService) ResolveSet(jobTitle, jobDescriptionID string, scope Scope) (models.GroundTruthRefs, error) {
+	docs, err := s.ListDocuments(GroundTruthFilter{}, Scope{OrganizationID: scope.organizationID()})
+	if err != nil {
+		return nil, err
+	}
+
+	var jobDescription *models.GroundTruthDocument
+	if jobDescriptionID != "" {
+		jobDescription = findGroundTruth(docs, func(doc *models.GroundTruthDocument) bool {
+			return doc.ID == jobDescriptionID && doc.DocumentType == models.GroundTruthTypeJobDescription
+		})
+		if jobDescription == nil {
+			return nil, fmt.Errorf("%w: no job description with id %s", ErrGroundTruthNotFound, jobDescriptionID)
+		}
+	} else if jobDescription, err = matchJobDescription(docs, jobTitle); err != nil {
+		return nil, err
+	}
+
+	refs := models.GroundTruthRefs{}
+	role := ""
+	if jobDescription != nil {
+		refs = append(refs, groundTruthRef(jobDescription))
+		role = jobDescription.Role
+	}
+	for _, gtType := range AllGroundTruthTypes() {
+		if gtType == models.GroundTruthTypeJobDescription {
+			continue
+		}
+		if doc := latestForRole(docs, gtType, role); doc != nil {
+			refs = append(refs, groundTruthRef(doc))
+		}
+	}
+	return refs, nil
+}
+
+// matchJobDescription resolves a job title deterministically: the only job
+// description of the organisation, else the one whose role or name equals the
+// title, else the one whose name contains it. Anything else is ambiguous.
+func matchJobDescription(docs []models.GroundTruthDocument, jobTitle string) (*models.GroundTruthDocument, error) {
+	var candidates []*models.GroundTruthDocument
+	for i := range docs {
+		if docs[i].DocumentType == models.GroundTruthTypeJobDescription {
+			candidates = append(candidates, &docs[i])
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return candidates[0], nil
+	}
+
+	title := strings.ToLower(strings.TrimSpace(jobTitle))
+	matchers := []func(doc *models.GroundTruthDocument) bool{
+		func(doc *models.GroundTruthDocument) bool {
+			return strings.ToLower(doc.Role) == title || strings.ToLower(doc.DocumentName) == title
+		},
+		func(doc *models.GroundTruthDocument) bool {
+			return strings.Contains(strings.ToLower(doc.DocumentName), title)
+		},
+	}
+	for _, match := range matchers {
+		var matched []*models.GroundTruthDocument
+		for _, doc := range candidates {
+			if match(doc) {
+				matched = append(matched, doc)
+			}
+		}
+		if len(matched) == 1 {
+			return matched[0], nil
+		}
+		if len(matched) > 1 {
+			candidates = matched
+			break
+		}
+	}
+
+	names := make([]string, 0, len(candidates))
+	for _, doc := range candidates {
+		names = append(names, fmt.Sprintf("%s (%s)", doc.DocumentName, doc.ID))
+	}
+	return nil, fmt.Errorf("%w: %q could be any of %s; pass job_description_id",
+		ErrJobDescriptionUnresolved, jobTitle, strings.Join(names, ", "))
+}
+
+// latestForRole returns the most recently ingested document of a type for the
+// role, or the latest one without a role
+func latestForRole(docs []models.GroundTruthDocument, gtType models.GroundTruthType, role string) *models.GroundTruthDocument {
+	roles := []string{""}
+	if role != "" {
+		roles = []string{role, ""}
+	}
+	for _, r := range roles {
+		var latest *models.GroundTruthDocument
+		for i := range docs {
+			if docs[i].DocumentType == gtType && docs[i].Role == r {
+				latest = &docs[i] // docs are ordered oldest first
+			}
+		}
+		if latest != nil {
+			return latest
+		}
+	}
+	return nil
+}
+
+func findGroundTruth(docs []models.GroundTruthDocument, match func(doc *models.GroundTruthDocument) bool) *models.GroundTruthDocument {
+	for i := range docs {
+		if match(&docs[i]) {
+			return &docs[i]
+		}
+	}
+	return nil
+}
+
+func groundTruthRef(doc *models.GroundTruthDocument) models.GroundTruthRef {
+	return models.GroundTruthRef{
+		ID:      doc.ID,
+		Type:    doc.DocumentType,
+		Name:    doc.DocumentName,
+		Version: doc.Version,
+	}
+}
+
+// AllGroundTruthTypes lists every ground truth type used by the evaluation pipeline
+func AllGroundTruthTypes() []models.GroundTruthType {
+	return []models.GroundTruthType{
+		models.GroundTruthTypeJobDescription,
+		models.GroundTruthTypeCaseStudyBrief,
+		models.GroundTruthTypeCVRubric,
+		models.GroundTruthTypeProjectRubric,
+	}
+}
+
+func groundTruthTypeList() string {
+	names := make([]string, 0, len(AllGroundTruthTypes()))
+	for _, gtType := range AllGroundTruthTypes() {
+		names = append(names, string(gtType))
+	}
+	return strings.Join(names, ", ")
+}
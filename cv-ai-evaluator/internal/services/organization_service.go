@@ -0,0 +1,162 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrInvalidCallbackURL   = errors.New("callback URL must be an http or https URL")
+)
+
+// Prefix of generated webhook secrets, so they are not mistaken for API keys
+const webhookSecretPrefix = "whsec_"
+
+type OrganizationService struct {
+	orgs repository.OrganizationRepository
+}
+
+func NewOrganizationService(orgs repository.OrganizationRepository) *OrganizationService {
+	return &OrganizationService{orgs: orgs}
+}
+
+// CreateOrganization adds a tenant with its own webhook secret, returned in
+// org.WebhookSecret. callbackURL is optional and becomes the default webhook
+// URL of the organisation's jobs.
+func (s *OrganizationService) CreateOrganization(name, callbackURL string) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("organization name is required")
+	}
+	callback, err := parseCallbackURL(callbackURL)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	org := &models.Organization{Name: name, CallbackURL: callback, WebhookSecret: sql.NullString{String: secret, Valid: true}}
+	if err := s.orgs.Create(org); err != nil {
+		return nil, fmt.Errorf("failed to store organization: %w", err)
+	}
+	return org, nil
+}
+
+// GetOrganization retrieves an organisation by ID
+func (s *OrganizationService) GetOrganization(id string) (*models.Organization, error) {
+	org, err := s.orgs.FindByID(id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrOrganizationNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve organization: %w", err)
+	}
+	return org, nil
+}
+
+// ListOrganizations returns every organisation, oldest first
+func (s *OrganizationService) ListOrganizations() ([]models.Organization, error) {
+	orgs, err := s.orgs.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+// SetCallbackURL replaces the organisation's default webhook URL; an empty
+// URL removes it
+func (s *OrganizationService) SetCallbackURL(id, callbackURL string) error {
+	callback, err := parseCallbackURL(callbackURL)
+	if err != nil {
+		return err
+	}
+
+	err = s.orgs.SetCallbackURL(id, callback)
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrOrganizationNotFound, id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update organization: %w", err)
+	}
+	return nil
+}
+
+// DefaultCallbackURL returns the webhook URL used for jobs of the scope's
+// organisation that do not name their own, or "" when it has none
+func (s *OrganizationService) DefaultCallbackURL(scope Scope) (string, error) {
+	org, err := s.GetOrganization(scope.organizationID())
+	if err != nil {
+		return "", err
+	}
+	return org.CallbackURL.String, nil
+}
+
+// RotateWebhookSecret gives the organisation a new webhook secret and returns
+// it; callbacks sent afterwards are signed with it
+func (s *OrganizationService) RotateWebhookSecret(id string) (string, error) {
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return "", err
+	}
+
+	err = s.orgs.SetWebhookSecret(id, sql.NullString{String: secret, Valid: true})
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", fmt.Errorf("%w: %s", ErrOrganizationNotFound, id)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update organization: %w", err)
+	}
+	return secret, nil
+}
+
+// WebhookSecret returns the secret that signs callbacks of the organisation's
+// jobs, or "" when it has none and the global WEBHOOK_SECRET applies
+func (s *OrganizationService) WebhookSecret(organizationID string) (string, error) {
+	org, err := s.GetOrganization(organizationID)
+	if err != nil {
+		return "", err
+	}
+	return org.WebhookSecret.String, nil
+}
+
+// CanSignCallbacks reports whether callbacks of jobs created in the scope can
+// be signed, with the organisation's secret or else globalSecret
+func (s *OrganizationService) CanSignCallbacks(scope Scope, globalSecret string) (bool, error) {
+	if globalSecret != "" {
+		return true, nil
+	}
+	secret, err := s.WebhookSecret(scope.organizationID())
+	return secret != "", err
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func parseCallbackURL(raw string) (sql.NullString, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return sql.NullString{}, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return sql.NullString{}, fmt.Errorf("%w: %q", ErrInvalidCallbackURL, raw)
+	}
+	return sql.NullString{String: raw, Valid: true}, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+)
+
+const (
+	// Number of recently completed jobs used to average stage durations
+	stageAverageSample = 50
+	stageAverageTTL    = time.Minute
+)
+
+// Used until enough jobs have completed to measure real stage durations
+var defaultStageDurations = map[models.JobStage]time.Duration{
+	models.StageExtracting:        5 * time.Second,
+	models.StageRetrievingContext: 5 * time.Second,
+	models.StageScoringCV:         60 * time.Second,
+	models.StageScoringProject:    60 * time.Second,
+	models.StageSummarising:       30 * time.Second,
+}
+
+// JobProgress describes where a job is in the pipeline and when it should finish
+type JobProgress struct {
+	Stage               models.JobStage      `json:"stage,omitempty"`
+	StageStartedAt      *time.Time           `json:"stage_started_at,omitempty"`
+	Stages              models.StageTimeline `json:"stages,omitempty"`
+	EstimatedCompletion *time.Time           `json:"estimated_completion,omitempty"`
+}
+
+type ProgressService struct {
+	jobs repository.JobRepository
+
+	mu          sync.Mutex
+	averages    map[models.JobStage]time.Duration
+	refreshedAt time.Time
+}
+
+func NewProgressService(jobs repository.JobRepository) *ProgressService {
+	return &ProgressService{jobs: jobs}
+}
+
+// GetProgress returns the progress of a queued or processing job, or nil once
+// the job is terminal. The estimate for a queued job assumes a worker picks it
+// up immediately (or when its retry backoff ends).
+func (s *ProgressService) GetProgress(job *models.EvaluationJob) (*JobProgress, error) {
+	if job.Status != models.JobStatusQueued && job.Status != models.JobStatusProcessing {
+		return nil, nil
+	}
+
+	averages, err := s.stageAverages()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	progress := &JobProgress{Stages: job.StageTimeline}
+	start := now
+	if job.Status == models.JobStatusQueued && job.NextAttemptAt.Valid && job.NextAttemptAt.Time.After(now) {
+		start = job.NextAttemptAt.Time
+	}
+
+	var remaining time.Duration
+	reached := true
+	if job.Status == models.JobStatusProcessing && job.CurrentStage.Valid {
+		progress.Stage = models.JobStage(job.CurrentStage.String)
+		reached = false
+
+		for i := len(job.StageTimeline) - 1; i >= 0; i-- {
+			timing := job.StageTimeline[i]
+			if timing.Stage == progress.Stage && timing.Attempt == job.Attempts && timing.FinishedAt == nil {
+				startedAt := timing.StartedAt
+				progress.StageStartedAt = &startedAt
+				if left := averages[progress.Stage] - now.Sub(startedAt); left > 0 {
+					remaining += left
+				}
+				break
+			}
+		}
+	}
+
+	// Stages after the current one, skipping those already checkpointed
+	for _, stage := range models.PipelineStages {
+		if !reached {
+			reached = stage == progress.Stage
+			continue
+		}
+		if !stageCheckpointed(job, stage) {
+			remaining += averages[stage]
+		}
+	}
+
+	eta := start.Add(remaining).Round(time.Second)
+	progress.EstimatedCompletion = &eta
+
+	return progress, nil
+}
+
+// stageCheckpointed reports whether a retried job will skip the stage
+func stageCheckpointed(job *models.EvaluationJob, stage models.JobStage) bool {
+	switch stage {
+	case models.StageExtracting:
+		return job.TextExtractedAt.Valid
+	case models.StageRetrievingContext:
+		return job.CVEvaluatedAt.Valid && job.ProjectEvaluatedAt.Valid
+	case models.StageScoringCV:
+		return job.CVEvaluatedAt.Valid
+	case models.StageScoringProject:
+		return job.ProjectEvaluatedAt.Valid
+	case models.StageSummarising:
+		return job.SummarizedAt.Valid
+	}
+	return false
+}
+
+// stageAverages returns the mean duration of each stage over recently completed
+// jobs, cached for stageAverageTTL
+func (s *ProgressService) stageAverages() (map[models.JobStage]time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.averages != nil && time.Since(s.refreshedAt) < stageAverageTTL {
+		return s.averages, nil
+	}
+
+	timelines, err := s.jobs.RecentStageTimelines(stageAverageSample)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stage timings: %w", err)
+	}
+
+	totals := make(map[models.JobStage]time.Duration)
+	counts := make(map[models.JobStage]int)
+	for _, timeline := range timelines {
+		for _, timing := range timeline {
+			if d, ok := timing.Duration(); ok {
+				totals[timing.Stage] += d
+				counts[timing.Stage]++
+			}
+		}
+	}
+
+	averages := make(map[models.JobStage]time.Duration, len(defaultStageDurations))
+	for stage, fallback := range defaultStageDurations {
+		averages[stage] = fallback
+		if counts[stage] > 0 {
+			averages[stage] = totals[stage] / time.Duration(counts[stage])
+		}
+	}
+
+	s.averages = averages
+	s.refreshedAt = time.Now()
+	return averages, nil
+}
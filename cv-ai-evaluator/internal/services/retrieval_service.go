@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/pkg/vectordb"
+)
+
+// GroundTruthRetriever ranks ground truth sections. It is implemented by
+// *vectordb.Retriever; tests may run one over a vector DB with a stub embedding.
+type GroundTruthRetriever interface {
+	Retrieve(ctx context.Context, tenant, query string, whereFilter map[string]string) ([]vectordb.Section, error)
+	Rank(ctx context.Context, query string, sections []vectordb.Section) []vectordb.Section
+	Sections(id, version, content string) []vectordb.Section
+	Config() vectordb.RetrieverConfig
+}
+
+var _ GroundTruthRetriever = (*vectordb.Retriever)(nil)
+
+// RetrievalService selects the ground truth sections an evaluation puts in its
+// prompts. The evaluation worker and the retrieval debug endpoint both use it,
+// so the endpoint shows exactly what a job would retrieve.
+type RetrievalService struct {
+	retriever     GroundTruthRetriever
+	groundTruth   *GroundTruthService
+	contextTokens int
+}
+
+func NewRetrievalService(retriever GroundTruthRetriever, groundTruth *GroundTruthService, contextTokens int) *RetrievalService {
+	return &RetrievalService{retriever: retriever, groundTruth: groundTruth, contextTokens: contextTokens}
+}
+
+// GroundTruthQuery is the query an evaluation ranks the sections of a ground
+// truth type by
+func GroundTruthQuery(gtType models.GroundTruthType, jobTitle string) string {
+	switch gtType {
+	case models.GroundTruthTypeJobDescription:
+		return fmt.Sprintf("%s job description requirements", jobTitle)
+	case models.GroundTruthTypeCVRubric:
+		return "CV evaluation rubric scoring criteria"
+	case models.GroundTruthTypeCaseStudyBrief:
+		return "case study brief requirements specifications"
+	default:
+		return "project evaluation rubric scoring criteria"
+	}
+}
+
+// Candidates ranks the ground truth of one type an evaluation draws its
+// context from, best first. A job pinned to refs only uses the pinned
+// document: one within the context token budget is returned whole as a single
+// section (whole is true), a longer one is ranked in the vector DB or, for a
+// retired version no longer indexed there, re-chunked and ranked without
+// embeddings. Jobs without refs (created before ground truth was pinned) rank
+// every active document of the type. It returns ErrGroundTruthNotFound when
+// refs pins no document of the type.
+func (s *RetrievalService) Candidates(ctx context.Context, organizationID string, refs models.GroundTruthRefs, gtType models.GroundTruthType, query string) (sections []vectordb.Section, whole bool, err error) {
+	if refs == nil {
+		sections, err := s.retriever.Retrieve(ctx, organizationID, query, map[string]string{"type": string(gtType)})
+		if err != nil {
+			return nil, false, fmt.Errorf("retrieval failed: %w", err)
+		}
+		return sections, false, nil
+	}
+
+	ref, ok := refs.Find(gtType)
+	if !ok {
+		return nil, false, fmt.Errorf("%w: no %s document is pinned", ErrGroundTruthNotFound, gtType)
+	}
+	content, err := s.groundTruth.PinnedContent(ctx, organizationID, ref.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if vectordb.EstimateTokens(content) <= s.contextTokens {
+		return []vectordb.Section{{DocumentID: ref.ID, Version: ref.Version, Content: content}}, true, nil
+	}
+
+	sections, err = s.retriever.Retrieve(ctx, organizationID, query, map[string]string{"document_id": ref.ID})
+	if err != nil {
+		return nil, false, fmt.Errorf("retrieval failed: %w", err)
+	}
+	if len(sections) == 0 {
+		sections = s.retriever.Rank(ctx, query, s.retriever.Sections(ref.ID, ref.Version, content))
+	}
+	return sections, false, nil
+}
+
+// Select returns the sections an evaluation sends to the LLM, see
+// vectordb.SelectSections
+func (s *RetrievalService) Select(sections []vectordb.Section) []vectordb.Section {
+	return vectordb.SelectSections(sections, s.contextTokens)
+}
+
+// RetrievalQuery describes the evaluation whose retrieval is inspected: the
+// ground truth is resolved from JobTitle and JobDescriptionID as for a new job
+type RetrievalQuery struct {
+	JobTitle         string
+	JobDescriptionID string
+	DocumentType     models.GroundTruthType
+	// Optional; defaults to the query the worker uses for DocumentType
+	Query string
+}
+
+// RankedSection is one retrieved chunk with its estimated size. Selected
+// reports whether an evaluation would put it in the prompt, within the
+// context token budget.
+type RankedSection struct {
+	vectordb.Section
+	DocumentName string
+	Tokens       int
+	Selected     bool
+}
+
+// RetrievalResult lists the candidate chunks of a query, best first
+type RetrievalResult struct {
+	Query         string
+	DocumentType  models.GroundTruthType
+	Document      models.GroundTruthRef
+	Config        vectordb.RetrieverConfig
+	ContextTokens int
+	// The document fits in ContextTokens and is sent whole, without ranking
+	Whole    bool
+	Sections []RankedSection
+}
+
+// Search shows the retrieval a new job would run for one ground truth type:
+// the document ResolveSet pins for the job title is ranked (or sent whole) by
+// the same Candidates and Select calls the evaluation worker makes
+func (s *RetrievalService) Search(ctx context.Context, req RetrievalQuery, scope Scope) (*RetrievalResult, error) {
+	refs, err := s.groundTruth.ResolveSet(req.JobTitle, req.JobDescriptionID, scope)
+	if err != nil {
+		return nil, err
+	}
+	ref, ok := refs.Find(req.DocumentType)
+	if !ok {
+		return nil, fmt.Errorf("%w: no %s document applies to job title %q, evaluations use the fallback text",
+			ErrGroundTruthNotFound, req.DocumentType, req.JobTitle)
+	}
+
+	query := req.Query
+	if query == "" {
+		query = GroundTruthQuery(req.DocumentType, req.JobTitle)
+	}
+	sections, whole, err := s.Candidates(ctx, scope.organizationID(), refs, req.DocumentType, query)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool)
+	for _, section := range s.Select(sections) {
+		selected[sectionKey(section)] = true
+	}
+
+	result := &RetrievalResult{
+		Query:         query,
+		DocumentType:  req.DocumentType,
+		Document:      ref,
+		Config:        s.retriever.Config(),
+		ContextTokens: s.contextTokens,
+		Whole:         whole,
+		Sections:      make([]RankedSection, 0, len(sections)),
+	}
+	for _, section := range sections {
+		result.Sections = append(result.Sections, RankedSection{
+			Section:      section,
+			DocumentName: ref.Name,
+			Tokens:       vectordb.EstimateTokens(section.Content),
+			Selected:     selected[sectionKey(section)],
+		})
+	}
+	return result, nil
+}
+
+func sectionKey(section vectordb.Section) string {
+	return fmt.Sprintf("%s#%d", section.DocumentID, section.Index)
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"database/sql"
+
+	"cv-ai-evaluator/internal/models"
+)
+
+// Scope limits data access to one organisation (tenant): every API key of an
+// organisation sees the documents and jobs created with any of its keys, except
+// OwnerOnly records from before organisations existed (see ownsRecord). The
+// zero value is unrestricted; it is used when authentication is disabled and by
+// internal callers such as the worker pool.
+type Scope struct {
+	OrganizationID string
+	APIKeyID       string
+}
+
+// owns reports whether a record of the given organisation is visible in the scope
+func (s Scope) owns(organizationID string) bool {
+	return s.OrganizationID == "" || organizationID == s.OrganizationID
+}
+
+// ownsRecord is owns for documents and jobs, which record the API key that
+// created them. Records from before organisations existed are marked OwnerOnly
+// (migration 0016) and stay visible only to that key, as they were then.
+func (s Scope) ownsRecord(organizationID string, ownerKeyID sql.NullString, ownerOnly bool) bool {
+	if !s.owns(organizationID) {
+		return false
+	}
+	return s.OrganizationID == "" || !ownerOnly || ownerKeyID.String == s.APIKeyID
+}
+
+// organizationID is the organisation recorded on records created in the scope
+func (s Scope) organizationID() string {
+	if s.OrganizationID == "" {
+		return models.DefaultOrganizationID
+	}
+	return s.OrganizationID
+}
+
+// ownerKeyID is the API key recorded on records created in the scope
+func (s Scope) ownerKeyID() sql.NullString {
+	return sql.NullString{String: s.APIKeyID, Valid: s.APIKeyID != ""}
+}
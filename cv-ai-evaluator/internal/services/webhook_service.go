@@ -0,0 +1,118 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+)
+
+type WebhookService struct {
+	jobs       repository.JobRepository
+	deliveries repository.WebhookDeliveryRepository
+}
+
+func NewWebhookService(jobs repository.JobRepository, deliveries repository.WebhookDeliveryRepository) *WebhookService {
+	return &WebhookService{jobs: jobs, deliveries: deliveries}
+}
+
+// webhookDue reports whether a job has a pending webhook whose retry time has passed
+func webhookDue(job *models.EvaluationJob, now time.Time) bool {
+	return job.WebhookStatus.String == models.WebhookStatusPending &&
+		(!job.WebhookNextAttemptAt.Valid || !job.WebhookNextAttemptAt.Time.After(now))
+}
+
+// ClaimNextDelivery reserves the oldest job with a pending webhook whose retry
+// time has passed. The reservation pushes next_attempt_at forward, so another
+// dispatcher skips the job and a crashed dispatcher's delivery is retried later.
+func (s *WebhookService) ClaimNextDelivery(reservation time.Duration) (*models.EvaluationJob, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		now := time.Now()
+
+		candidateID, err := s.jobs.NextPendingWebhookID(now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find pending webhook: %w", err)
+		}
+		if candidateID == "" {
+			return nil, nil
+		}
+
+		var claimed *models.EvaluationJob
+		ok, err := s.jobs.Update(candidateID, func(job *models.EvaluationJob) bool {
+			if !webhookDue(job, now) {
+				return false
+			}
+			job.WebhookNextAttemptAt = sql.NullTime{Time: now.Add(reservation), Valid: true}
+			job.WebhookAttempts++
+			claimed = job
+			return true
+		})
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim webhook for job %s: %w", candidateID, err)
+		}
+		if ok {
+			return claimed, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// RecordAttempt stores the outcome of one delivery attempt
+func (s *WebhookService) RecordAttempt(delivery *models.WebhookDelivery) error {
+	if err := s.deliveries.Create(delivery); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// MarkDelivered stops further deliveries for the job
+func (s *WebhookService) MarkDelivered(jobID string) error {
+	return s.updateStatus(jobID, func(job *models.EvaluationJob) {
+		job.WebhookStatus = sql.NullString{String: models.WebhookStatusDelivered, Valid: true}
+		job.WebhookNextAttemptAt = sql.NullTime{}
+	})
+}
+
+// MarkFailed gives up on the webhook after the last attempt
+func (s *WebhookService) MarkFailed(jobID string) error {
+	return s.updateStatus(jobID, func(job *models.EvaluationJob) {
+		job.WebhookStatus = sql.NullString{String: models.WebhookStatusFailed, Valid: true}
+		job.WebhookNextAttemptAt = sql.NullTime{}
+	})
+}
+
+// ScheduleRetry sets when the next delivery attempt may run
+func (s *WebhookService) ScheduleRetry(jobID string, nextAttemptAt time.Time) error {
+	return s.updateStatus(jobID, func(job *models.EvaluationJob) {
+		job.WebhookNextAttemptAt = sql.NullTime{Time: nextAttemptAt, Valid: true}
+	})
+}
+
+func (s *WebhookService) updateStatus(jobID string, apply func(job *models.EvaluationJob)) error {
+	if _, err := s.jobs.Update(jobID, func(job *models.EvaluationJob) bool {
+		if job.WebhookStatus.String != models.WebhookStatusPending {
+			return false
+		}
+		apply(job)
+		return true
+	}); err != nil {
+		return fmt.Errorf("failed to update webhook status: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveries returns every delivery attempt for a job, oldest first
+func (s *WebhookService) GetDeliveries(jobID string) ([]models.WebhookDelivery, error) {
+	deliveries, err := s.deliveries.ListByJob(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
@@ -2,186 +2,660 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"regexp"
-	"strconv"
+	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"cv-ai-evaluator/internal/models"
 	"cv-ai-evaluator/internal/services"
 	"cv-ai-evaluator/pkg/llm"
 	"cv-ai-evaluator/pkg/utils"
 	"cv-ai-evaluator/pkg/vectordb"
+
+	"github.com/google/uuid"
+)
+
+// PoolConfig mengatur ukuran pool dan perilaku queue berbasis database
+type PoolConfig struct {
+	WorkerCount   int
+	LeaseDuration time.Duration // lama lease job sebelum dianggap ditinggalkan
+	PollInterval  time.Duration // interval polling tabel evaluation_jobs
+	MaxQueueDepth int           // batas job queued sebelum submit ditolak
+	RetryAfter    time.Duration // saran jeda bagi client saat submit ditolak
+
+	// MaxRepairAttempts adalah jumlah re-prompt maksimum saat output JSON LLM
+	// tidak lolos validasi schema (0 = langsung gagal)
+	MaxRepairAttempts int
+
+	// JobTimeout adalah deadline total pemrosesan satu job (semua stage LLM)
+	JobTimeout time.Duration
+
+	// ContextTokens membatasi ground truth per tipe yang masuk ke prompt
+	// (token perkiraan); dokumen yang lebih panjang diwakili section teratasnya
+	ContextTokens int
+
+	// Retry mengatur percobaan ulang job yang gagal karena error sementara
+	Retry RetryPolicy
+
+	// DocumentReader (opsional) membaca teks CV dan project report; default
+	// utils.DocumentReader (PDF lewat unipdf, MD, TXT)
+	DocumentReader DocumentReader
+}
+
+func (c PoolConfig) withDefaults() PoolConfig {
+	if c.WorkerCount <= 0 {
+		c.WorkerCount = 3
+	}
+	if c.LeaseDuration <= 0 {
+		c.LeaseDuration = 2 * time.Minute
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = 2 * time.Second
+	}
+	if c.MaxQueueDepth <= 0 {
+		c.MaxQueueDepth = 100
+	}
+	if c.RetryAfter <= 0 {
+		c.RetryAfter = 30 * time.Second
+	}
+	if c.JobTimeout <= 0 {
+		c.JobTimeout = 15 * time.Minute
+	}
+	if c.ContextTokens <= 0 {
+		c.ContextTokens = 1500
+	}
+	c.Retry = c.Retry.withDefaults()
+	if c.DocumentReader == nil {
+		c.DocumentReader = utils.NewDocumentReader()
+	}
+	return c
+}
+
+// DocumentReader mengekstrak teks dokumen yang di-upload
+type DocumentReader interface {
+	ReadDocument(filePath string) (string, error)
+	CleanText(text string) string
+}
+
+var _ DocumentReader = (*utils.DocumentReader)(nil)
+
+// Error yang dikembalikan SubmitJob saat job tidak bisa diterima
+var (
+	ErrQueueFull   = errors.New("evaluation queue is full")
+	ErrPoolStopped = errors.New("worker pool is stopped")
 )
 
+var (
+	// ErrJobCancelled adalah cause context job yang dibatalkan lewat API
+	ErrJobCancelled = errors.New("job cancelled")
+	// ErrLeaseLost adalah cause context job yang lease-nya tidak lagi dipegang
+	// worker ini (lease habis lalu job di-requeue, atau job sudah selesai)
+	ErrLeaseLost = services.ErrLeaseLost
+)
+
+// PoolStats adalah snapshot kondisi pool untuk monitoring
+type PoolStats struct {
+	QueueDepth    int64 `json:"queue_depth"`
+	QueueCapacity int   `json:"queue_capacity"`
+	InFlight      int64 `json:"in_flight"`
+	Workers       int   `json:"workers"`
+	Rejected      int64 `json:"rejected_total"`
+	Stopped       bool  `json:"stopped"`
+}
+
+// WorkerPool memproses evaluation job. Queue-nya adalah tabel evaluation_jobs:
+// worker meng-claim baris berstatus queued dengan lease yang diperpanjang lewat
+// heartbeat, sehingga job tidak hilang saat proses restart.
 type WorkerPool struct {
-	jobQueue          chan string
-	workerCount       int
-	wg                sync.WaitGroup
-	ctx               context.Context
-	cancel            context.CancelFunc
-	ollamaClient      *llm.OllamaClient
-	chromaClient      *vectordb.ChromaClient
-	docReader         *utils.DocumentReader
-	evaluationService *services.EvaluationService
+	cfg                PoolConfig
+	instanceID         string
+	wakeup             chan struct{}
+	wg                 sync.WaitGroup
+	ctx                context.Context
+	cancel             context.CancelFunc
+	llmProvider        llm.Provider
+	retrieval          *services.RetrievalService
+	docReader          DocumentReader
+	evaluationService  *services.EvaluationService
+
+	inFlight atomic.Int64
+	rejected atomic.Int64
+
+	// running memetakan job ID ke cancel func job yang sedang diproses di instance ini
+	runningMu sync.Mutex
+	running   map[string]context.CancelCauseFunc
 }
 
 func NewWorkerPool(
-	workerCount int,
-	ollamaClient *llm.OllamaClient,
-	chromaClient *vectordb.ChromaClient,
+	cfg PoolConfig,
+	llmProvider llm.Provider,
+	retriever services.GroundTruthRetriever,
 	evaluationService *services.EvaluationService,
+	groundTruthService *services.GroundTruthService,
 ) *WorkerPool {
 	ctx, cancel := context.WithCancel(context.Background())
+	cfg = cfg.withDefaults()
+
+	hostname, _ := os.Hostname()
 
 	return &WorkerPool{
-		jobQueue:          make(chan string, 100),
-		workerCount:       workerCount,
-		ctx:               ctx,
-		cancel:            cancel,
-		ollamaClient:      ollamaClient,
-		chromaClient:      chromaClient,
-		docReader:         utils.NewDocumentReader(),
-		evaluationService: evaluationService,
+		cfg:                cfg,
+		instanceID:         fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8]),
+		wakeup:             make(chan struct{}, cfg.WorkerCount),
+		ctx:                ctx,
+		cancel:             cancel,
+		llmProvider:        llmProvider,
+		retrieval:          services.NewRetrievalService(retriever, groundTruthService, cfg.ContextTokens),
+		docReader:          cfg.DocumentReader,
+		evaluationService:  evaluationService,
+		running:            make(map[string]context.CancelCauseFunc),
 	}
 }
 
 // Start memulai worker pool
 func (wp *WorkerPool) Start() {
-	for i := 1; i <= wp.workerCount; i++ {
+	// Recovery: job processing yang lease-nya habis dikembalikan ke queue
+	if n, err := wp.evaluationService.RequeueExpiredJobs(); err != nil {
+		log.Printf("Warning: failed to recover abandoned jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("Recovered %d abandoned job(s) back to the queue", n)
+	}
+	if n, err := wp.evaluationService.CountJobsByStatus(models.JobStatusQueued); err == nil && n > 0 {
+		log.Printf("Resuming %d queued job(s) from database", n)
+	}
+
+	for i := 1; i <= wp.cfg.WorkerCount; i++ {
 		wp.wg.Add(1)
 		go wp.worker(i)
 	}
-	log.Printf("Started %d workers", wp.workerCount)
+
+	wp.wg.Add(1)
+	go wp.reaper()
+
+	log.Printf("Started %d workers (instance %s)", wp.cfg.WorkerCount, wp.instanceID)
 }
 
 // Stop menghentikan worker pool
 func (wp *WorkerPool) Stop() {
 	log.Println("Stopping worker pool...")
-	close(wp.jobQueue)
 	wp.cancel()
 	wp.wg.Wait()
 	log.Println("Worker pool stopped")
 }
 
-// SubmitJob menambahkan job ke queue
-func (wp *WorkerPool) SubmitJob(jobID string) {
-	wp.jobQueue <- jobID
+// SubmitJob memberi tahu worker bahwa ada job baru di tabel evaluation_jobs.
+// Job-nya sendiri sudah tersimpan (status queued) sebelum fungsi ini dipanggil.
+// Tidak pernah blocking: mengembalikan ErrPoolStopped atau ErrQueueFull jika
+// job tidak bisa diterima, dan caller bertanggung jawab menarik job tersebut.
+func (wp *WorkerPool) SubmitJob(jobID string) error {
+	if wp.ctx.Err() != nil {
+		wp.rejected.Add(1)
+		return ErrPoolStopped
+	}
+
+	depth, err := wp.evaluationService.CountJobsByStatus(models.JobStatusQueued)
+	if err != nil {
+		return fmt.Errorf("failed to check queue depth: %w", err)
+	}
+	// depth sudah termasuk job ini
+	if depth > int64(wp.cfg.MaxQueueDepth) {
+		wp.rejected.Add(1)
+		return fmt.Errorf("%w (%d/%d queued)", ErrQueueFull, depth-1, wp.cfg.MaxQueueDepth)
+	}
+
+	wp.notify()
+	return nil
+}
+
+// CancelJob menghentikan job yang sedang diproses di instance ini. Status di
+// database harus sudah diubah ke cancelled oleh caller; job yang diproses
+// instance lain akan berhenti saat heartbeat-nya gagal memperpanjang lease.
+func (wp *WorkerPool) CancelJob(jobID string) bool {
+	wp.runningMu.Lock()
+	defer wp.runningMu.Unlock()
+
+	cancel, ok := wp.running[jobID]
+	if ok {
+		cancel(ErrJobCancelled)
+	}
+	return ok
+}
+
+// RetryAfter adalah saran jeda sebelum client mencoba submit lagi
+func (wp *WorkerPool) RetryAfter() time.Duration {
+	return wp.cfg.RetryAfter
+}
+
+// Stats mengembalikan snapshot queue depth dan job yang sedang diproses
+func (wp *WorkerPool) Stats() (PoolStats, error) {
+	depth, err := wp.evaluationService.CountJobsByStatus(models.JobStatusQueued)
+	if err != nil {
+		return PoolStats{}, err
+	}
+
+	return PoolStats{
+		QueueDepth:    depth,
+		QueueCapacity: wp.cfg.MaxQueueDepth,
+		InFlight:      wp.inFlight.Load(),
+		Workers:       wp.cfg.WorkerCount,
+		Rejected:      wp.rejected.Load(),
+		Stopped:       wp.ctx.Err() != nil,
+	}, nil
+}
+
+// notify membangunkan satu worker yang sedang idle tanpa blocking
+func (wp *WorkerPool) notify() {
+	select {
+	case wp.wakeup <- struct{}{}:
+	default:
+		// Semua worker sudah punya sinyal tertunda; job akan diambil lewat polling
+	}
 }
 
 // worker adalah goroutine yang memproses jobs
 func (wp *WorkerPool) worker(id int) {
 	defer wp.wg.Done()
 
+	workerID := fmt.Sprintf("%s-w%d", wp.instanceID, id)
 	log.Printf("Worker %d started", id)
 
 	for {
+		if wp.ctx.Err() != nil {
+			log.Printf("Worker %d stopping due to context cancellation", id)
+			return
+		}
+
+		job, err := wp.evaluationService.ClaimNextJob(workerID, wp.cfg.LeaseDuration)
+		if err != nil {
+			log.Printf("Worker %d failed to claim job: %v", id, err)
+		}
+
+		if job != nil {
+			log.Printf("Worker %d processing job: %s", id, job.ID)
+			if err := wp.runWithHeartbeat(job.ID, workerID); err != nil {
+				log.Printf("Worker %d failed to process job %s: %v", id, job.ID, err)
+			} else {
+				log.Printf("Worker %d completed job: %s", id, job.ID)
+			}
+			continue
+		}
+
+		// Queue kosong: tunggu sinyal job baru atau interval polling berikutnya
 		select {
 		case <-wp.ctx.Done():
 			log.Printf("Worker %d stopping due to context cancellation", id)
 			return
-		case jobID, ok := <-wp.jobQueue:
-			if !ok {
-				log.Printf("Worker %d stopping: job queue closed", id)
+		case <-wp.wakeup:
+		case <-time.After(wp.cfg.PollInterval):
+		}
+	}
+}
+
+// runWithHeartbeat memproses job sambil memperpanjang lease secara berkala.
+// Context job diturunkan dari context pool (dibatalkan saat Stop) dan dibatasi
+// deadline JobTimeout.
+func (wp *WorkerPool) runWithHeartbeat(jobID, workerID string) error {
+	wp.inFlight.Add(1)
+	defer wp.inFlight.Add(-1)
+
+	timeoutCtx, cancelTimeout := context.WithTimeout(wp.ctx, wp.cfg.JobTimeout)
+	defer cancelTimeout()
+	ctx, cancel := context.WithCancelCause(timeoutCtx)
+	defer cancel(nil)
+
+	wp.runningMu.Lock()
+	wp.running[jobID] = cancel
+	wp.runningMu.Unlock()
+	defer func() {
+		wp.runningMu.Lock()
+		delete(wp.running, jobID)
+		wp.runningMu.Unlock()
+	}()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(wp.cfg.LeaseDuration / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := wp.evaluationService.RenewLease(jobID, workerID, wp.cfg.LeaseDuration)
+				if err == nil {
+					continue
+				}
+				log.Printf("Warning: heartbeat for job %s failed: %v", jobID, err)
+				if !errors.Is(err, ErrLeaseLost) {
+					// error database sementara: lease masih berlaku, dicoba lagi di tick berikutnya
+					continue
+				}
+				// Job dibatalkan atau diambil alih di tempat lain: hentikan
+				cause := ErrLeaseLost
+				if job, getErr := wp.evaluationService.GetJobByID(jobID); getErr == nil && job.Status == models.JobStatusCancelled {
+					cause = ErrJobCancelled
+				}
+				cancel(cause)
 				return
 			}
+		}
+	}()
 
-			log.Printf("Worker %d processing job: %s", id, jobID)
-			if err := wp.processJob(jobID); err != nil {
-				log.Printf("Worker %d failed to process job %s: %v", id, jobID, err)
-			} else {
-				log.Printf("Worker %d completed job: %s", id, jobID)
+	return wp.processJob(ctx, jobID, workerID)
+}
+
+// reaper secara berkala mengembalikan job yang lease-nya habis ke queue
+func (wp *WorkerPool) reaper() {
+	defer wp.wg.Done()
+
+	ticker := time.NewTicker(wp.cfg.LeaseDuration / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wp.ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := wp.evaluationService.RequeueExpiredJobs()
+			if err != nil {
+				log.Printf("Warning: failed to requeue expired jobs: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Requeued %d job(s) with expired lease", n)
+				for i := int64(0); i < n; i++ {
+					wp.notify()
+				}
 			}
 		}
 	}
 }
 
-// processJob memproses satu evaluation job
-func (wp *WorkerPool) processJob(jobID string) error {
-	// 1. Update status ke processing using service
-	if err := wp.evaluationService.UpdateJobStatus(jobID, "processing"); err != nil {
-		return fmt.Errorf("failed to update job status: %w", err)
-	}
-
-	// 2. Load job data with documents using service
+// processJob memproses satu evaluation job yang sudah di-claim
+func (wp *WorkerPool) processJob(ctx context.Context, jobID, workerID string) error {
+	// 1. Load job data with documents using service
 	job, err := wp.evaluationService.GetJobWithDocuments(jobID)
 	if err != nil {
-		return wp.evaluationService.FailJob(jobID, fmt.Sprintf("failed to load job: %v", err))
+		return wp.evaluationService.FailJob(jobID, fmt.Sprintf("failed to load job: %v", err), nil)
 	}
 
-	// 3. Extract document text (support PDF, MD, TXT)
-	cvText, err := wp.docReader.ReadDocument(job.CVDocument.FilePath)
-	if err != nil {
-		return wp.evaluationService.FailJob(jobID, fmt.Sprintf("failed to extract CV text: %v", err))
+	// Job yang berulang kali membuat proses crash (lease habis) tidak diulang terus
+	if job.Attempts > wp.cfg.Retry.MaxAttempts {
+		return wp.evaluationService.DeadLetterJob(jobID,
+			fmt.Sprintf("exceeded %d attempts (abandoned by crashed workers)", wp.cfg.Retry.MaxAttempts), job.ErrorHistory)
 	}
-	cvText = wp.docReader.CleanText(cvText)
 
-	reportText, err := wp.docReader.ReadDocument(job.ReportDocument.FilePath)
-	if err != nil {
-		return wp.evaluationService.FailJob(jobID, fmt.Sprintf("failed to extract report text: %v", err))
+	// Stage yang sudah punya checkpoint (dari percobaan sebelumnya) dilewati
+	if job.TextExtractedAt.Valid {
+		log.Printf("Job %s resuming from checkpoint (attempt %d)", jobID, job.Attempts)
+	}
+
+	// 2. Extract document text (support PDF, MD, TXT)
+	cvText, reportText := job.CVText.String, job.ReportText.String
+	if !job.TextExtractedAt.Valid {
+		wp.reportStage(job, workerID, models.StageExtracting)
+
+		cvText, err = wp.docReader.ReadDocument(job.CVDocument.FilePath)
+		if err != nil {
+			return wp.abortJob(ctx, job, workerID, "failed to extract CV text", permanent(err))
+		}
+		cvText = wp.docReader.CleanText(cvText)
+
+		reportText, err = wp.docReader.ReadDocument(job.ReportDocument.FilePath)
+		if err != nil {
+			return wp.abortJob(ctx, job, workerID, "failed to extract report text", permanent(err))
+		}
+		reportText = wp.docReader.CleanText(reportText)
+
+		if err := wp.evaluationService.SaveExtractedText(jobID, workerID, cvText, reportText); err != nil {
+			return wp.abortJob(ctx, job, workerID, "failed to save extracted text", err)
+		}
+	}
+
+	// 3. Retrieve ground truth context (RAG) untuk stage penilaian yang belum
+	// selesai. Chunk yang sudah tercatat (retry atau re-run) dipakai ulang apa adanya.
+	var evalCtx evaluationContext
+	if !job.CVEvaluatedAt.Valid || !job.ProjectEvaluatedAt.Valid {
+		chunks := job.RetrievedChunks
+		if chunks == nil {
+			wp.reportStage(job, workerID, models.StageRetrievingContext)
+			chunks = wp.retrieveChunks(ctx, job)
+			if err := wp.evaluationService.SaveRetrievedChunks(jobID, workerID, chunks); err != nil {
+				return wp.abortJob(ctx, job, workerID, "failed to save retrieved context", err)
+			}
+		}
+		evalCtx = newEvaluationContext(job, chunks)
 	}
-	reportText = wp.docReader.CleanText(reportText)
 
 	// 4. Evaluate CV
-	cvMatchRate, cvFeedback, err := wp.evaluateCV(cvText, job.JobTitleEvaluated)
-	if err != nil {
-		return wp.evaluationService.FailJob(jobID, fmt.Sprintf("CV evaluation failed: %v", err))
+	cvResult := job.CVResult()
+	if !job.CVEvaluatedAt.Valid {
+		wp.reportStage(job, workerID, models.StageScoringCV)
+		cvResult, err = wp.evaluateCV(ctx, cvText, job.JobTitleEvaluated, evalCtx)
+		if err != nil {
+			return wp.abortJob(ctx, job, workerID, "CV evaluation failed", err)
+		}
+		if err := wp.evaluationService.SaveCVResult(jobID, workerID, cvResult); err != nil {
+			return wp.abortJob(ctx, job, workerID, "failed to save CV evaluation", err)
+		}
 	}
 
 	// 5. Evaluate Project Report
-	projectScore, projectFeedback, err := wp.evaluateProject(reportText)
-	if err != nil {
-		return wp.evaluationService.FailJob(jobID, fmt.Sprintf("Project evaluation failed: %v", err))
+	projectResult := job.ProjectResult()
+	if !job.ProjectEvaluatedAt.Valid {
+		wp.reportStage(job, workerID, models.StageScoringProject)
+		projectResult, err = wp.evaluateProject(ctx, reportText, evalCtx)
+		if err != nil {
+			return wp.abortJob(ctx, job, workerID, "Project evaluation failed", err)
+		}
+		if err := wp.evaluationService.SaveProjectResult(jobID, workerID, projectResult); err != nil {
+			return wp.abortJob(ctx, job, workerID, "failed to save project evaluation", err)
+		}
 	}
 
 	// 6. Generate Overall Summary
-	overallSummary, err := wp.generateOverallSummary(cvMatchRate, cvFeedback, projectScore, projectFeedback, job.JobTitleEvaluated)
-	if err != nil {
-		return wp.evaluationService.FailJob(jobID, fmt.Sprintf("Summary generation failed: %v", err))
+	overallSummary := job.OverallSummary.String
+	if !job.SummarizedAt.Valid {
+		wp.reportStage(job, workerID, models.StageSummarising)
+		overallSummary, err = wp.generateOverallSummary(ctx, cvResult, projectResult, job.JobTitleEvaluated)
+		if err != nil {
+			return wp.abortJob(ctx, job, workerID, "Summary generation failed", err)
+		}
+		if err := wp.evaluationService.SaveSummary(jobID, workerID, overallSummary); err != nil {
+			return wp.abortJob(ctx, job, workerID, "failed to save summary", err)
+		}
 	}
 
 	// 7. Complete job with results using service
-	if err := wp.evaluationService.CompleteJob(
-		jobID,
-		cvMatchRate,
-		cvFeedback,
-		projectScore,
-		projectFeedback,
-		overallSummary,
-	); err != nil {
-		return fmt.Errorf("failed to save results: %w", err)
+	job.StageTimeline = job.StageTimeline.Finish(job.Attempts, time.Now())
+	if err := wp.evaluationService.UpdateProgress(jobID, workerID, "", job.StageTimeline); err != nil {
+		log.Printf("Warning: failed to record progress for job %s: %v", jobID, err)
+	}
+	if err := wp.evaluationService.CompleteJob(jobID, workerID, cvResult, projectResult, overallSummary); err != nil {
+		return wp.abortJob(ctx, job, workerID, "failed to save results", err)
 	}
 
 	return nil
 }
 
-// evaluateCV melakukan evaluasi CV dengan RAG dan LLM
-func (wp *WorkerPool) evaluateCV(cvText, jobTitle string) (float64, string, error) {
-	// Query vector DB untuk job description dan rubric
-	jdContext, err := wp.chromaClient.GetRelevantContext(
-		wp.ctx,
-		fmt.Sprintf("%s job description requirements", jobTitle),
-		"job_description",
-		2,
-	)
-	if err != nil {
-		log.Printf("Warning: failed to get job description context: %v", err)
-		jdContext = "No specific job description available."
+// abortJob menangani error stage, termasuk checkpoint yang gagal disimpan. Job
+// yang dibatalkan user dibiarkan berstatus cancelled, dan job yang lease-nya
+// hilang tidak disentuh sama sekali karena bisa jadi sedang diproses worker
+// lain. Saat pool dihentikan, job dikembalikan ke queue agar dilanjutkan
+// setelah restart. Selain itu error dicatat di riwayat percobaan, lalu job
+// dijadwalkan ulang (error sementara), dipindah ke dead letter (retry habis),
+// atau ditandai failed (error permanen).
+func (wp *WorkerPool) abortJob(ctx context.Context, job *models.EvaluationJob, workerID, stage string, err error) error {
+	// Status cancelled sudah ditulis oleh endpoint cancel
+	if errors.Is(context.Cause(ctx), ErrJobCancelled) {
+		return fmt.Errorf("%s: %w", stage, ErrJobCancelled)
+	}
+	if errors.Is(context.Cause(ctx), ErrLeaseLost) || errors.Is(err, ErrLeaseLost) {
+		return fmt.Errorf("%s: %w", stage, ErrLeaseLost)
 	}
 
-	rubricContext, err := wp.chromaClient.GetRelevantContext(
-		wp.ctx,
-		"CV evaluation rubric scoring criteria",
-		"cv_rubric",
-		1,
-	)
+	if wp.ctx.Err() != nil {
+		if relErr := wp.evaluationService.ReleaseJob(job.ID, workerID); relErr != nil {
+			return fmt.Errorf("%s during shutdown and release failed: %v (cause: %w)", stage, relErr, err)
+		}
+		return fmt.Errorf("%s: interrupted by shutdown, job returned to queue: %w", stage, err)
+	}
+
+	errorMsg := fmt.Sprintf("%s: %v", stage, err)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		errorMsg = fmt.Sprintf("%s: job exceeded timeout of %s", stage, wp.cfg.JobTimeout)
+	}
+
+	retryable := isRetryable(err)
+	history := append(job.ErrorHistory, models.AttemptError{
+		Attempt:   job.Attempts,
+		Stage:     stage,
+		Error:     errorMsg,
+		Retryable: retryable,
+		FailedAt:  time.Now(),
+	})
+
+	switch {
+	case !retryable:
+		if failErr := wp.evaluationService.FailJob(job.ID, errorMsg, history); failErr != nil {
+			return failErr
+		}
+		return fmt.Errorf("%s (permanent)", errorMsg)
+
+	case job.Attempts >= wp.cfg.Retry.MaxAttempts:
+		deadMsg := fmt.Sprintf("giving up after %d attempts: %s", job.Attempts, errorMsg)
+		if dlErr := wp.evaluationService.DeadLetterJob(job.ID, deadMsg, history); dlErr != nil {
+			return dlErr
+		}
+		return fmt.Errorf("%s (moved to dead letter)", errorMsg)
+
+	default:
+		delay := wp.cfg.Retry.Backoff(job.Attempts)
+		if retryErr := wp.evaluationService.RetryJob(job.ID, errorMsg, history, time.Now().Add(delay)); retryErr != nil {
+			return retryErr
+		}
+		return fmt.Errorf("%s (attempt %d/%d, retrying in %s)", errorMsg, job.Attempts, wp.cfg.Retry.MaxAttempts, delay.Round(time.Second))
+	}
+}
+
+// reportStage mencatat stage yang sedang berjalan; kegagalan hanya di-log
+// karena progress bersifat informatif dan tidak boleh menggagalkan job
+func (wp *WorkerPool) reportStage(job *models.EvaluationJob, workerID string, stage models.JobStage) {
+	job.StageTimeline = job.StageTimeline.Enter(stage, job.Attempts, time.Now())
+	if err := wp.evaluationService.UpdateProgress(job.ID, workerID, stage, job.StageTimeline); err != nil {
+		log.Printf("Warning: failed to record progress for job %s: %v", job.ID, err)
+	}
+}
+
+// evaluationContext berisi ground truth hasil retrieval untuk stage penilaian
+type evaluationContext struct {
+	JobDescription string
+	CVRubric       string
+	CaseStudyBrief string
+	ProjectRubric  string
+}
+
+// groundTruthQuery menentukan retrieval satu tipe ground truth, dan
+// teks pengganti jika tidak ada dokumen
+type groundTruthQuery struct {
+	Type     models.GroundTruthType
+	Query    string
+	Fallback string
+}
+
+func groundTruthQueries(job *models.EvaluationJob) []groundTruthQuery {
+	return []groundTruthQuery{
+		{models.GroundTruthTypeJobDescription, services.GroundTruthQuery(models.GroundTruthTypeJobDescription, job.JobTitleEvaluated),
+			"No specific job description available."},
+		{models.GroundTruthTypeCVRubric, services.GroundTruthQuery(models.GroundTruthTypeCVRubric, job.JobTitleEvaluated),
+			"Evaluate based on standard criteria."},
+		{models.GroundTruthTypeCaseStudyBrief, services.GroundTruthQuery(models.GroundTruthTypeCaseStudyBrief, job.JobTitleEvaluated),
+			"Evaluate based on general backend project standards."},
+		{models.GroundTruthTypeProjectRubric, services.GroundTruthQuery(models.GroundTruthTypeProjectRubric, job.JobTitleEvaluated),
+			"Evaluate based on standard project criteria."},
+	}
+}
+
+// newEvaluationContext menyusun prompt context dari chunk yang di-retrieve;
+// tipe tanpa chunk memakai teks fallback agar evaluasi tetap jalan
+func newEvaluationContext(job *models.EvaluationJob, chunks models.RetrievedChunks) evaluationContext {
+	texts := make(map[models.GroundTruthType]string)
+	for _, q := range groundTruthQueries(job) {
+		texts[q.Type] = q.Fallback
+		if text := chunks.Text(q.Type); text != "" {
+			texts[q.Type] = text
+		}
+	}
+	return evaluationContext{
+		JobDescription: texts[models.GroundTruthTypeJobDescription],
+		CVRubric:       texts[models.GroundTruthTypeCVRubric],
+		CaseStudyBrief: texts[models.GroundTruthTypeCaseStudyBrief],
+		ProjectRubric:  texts[models.GroundTruthTypeProjectRubric],
+	}
+}
+
+// retrieveChunks mengambil job description, case study brief, dan rubric milik
+// organisasi job. Job yang menyimpan GroundTruthRefs hanya memakai versi dokumen
+// yang dipilih saat job dibuat (termasuk versi yang sudah di-retire); job lama
+// (tanpa refs) mencari di semua dokumen tipe tersebut. Dokumen yang melebihi
+// budget token diwakili section yang paling relevan, dalam urutan dokumen.
+func (wp *WorkerPool) retrieveChunks(ctx context.Context, job *models.EvaluationJob) models.RetrievedChunks {
+	chunks := models.RetrievedChunks{}
+	for _, q := range groundTruthQueries(job) {
+		found, err := wp.groundTruthChunks(ctx, job, q)
+		if err != nil {
+			log.Printf("Warning: failed to get %s context for job %s: %v", q.Type, job.ID, err)
+			continue
+		}
+		chunks = append(chunks, found...)
+	}
+	return chunks
+}
+
+// groundTruthChunks memilih context satu tipe lewat RetrievalService, jalur
+// yang sama dengan GET /debug/retrieval
+func (wp *WorkerPool) groundTruthChunks(ctx context.Context, job *models.EvaluationJob, q groundTruthQuery) (models.RetrievedChunks, error) {
+	if _, ok := job.GroundTruthRefs.Find(q.Type); job.GroundTruthRefs != nil && !ok {
+		log.Printf("Job %s: no %s document selected, using fallback text", job.ID, q.Type)
+		return nil, nil
+	}
+	sections, whole, err := wp.retrieval.Candidates(ctx, job.OrganizationID, job.GroundTruthRefs, q.Type, q.Query)
 	if err != nil {
-		log.Printf("Warning: failed to get CV rubric context: %v", err)
-		rubricContext = "Evaluate based on standard criteria."
+		return nil, err
 	}
+	if whole {
+		section := sections[0]
+		return models.RetrievedChunks{{Type: q.Type, DocumentID: section.DocumentID, Version: section.Version, Content: section.Content}}, nil
+	}
+	return retrievedChunks(q.Type, wp.retrieval.Select(sections)), nil
+}
 
+// retrievedChunks mencatat section terpilih, sudah digabung ulang, sebagai
+// chunk job
+func retrievedChunks(gtType models.GroundTruthType, sections []vectordb.Section) models.RetrievedChunks {
+	var chunks models.RetrievedChunks
+	for _, section := range vectordb.Reassemble(sections) {
+		chunks = append(chunks, models.RetrievedChunk{
+			Type:       gtType,
+			DocumentID: section.DocumentID,
+			Version:    section.Version,
+			Section:    section.Heading,
+			Score:      section.Score,
+			Content:    section.Content,
+		})
+	}
+	return chunks
+}
+
+// evaluateCV melakukan evaluasi CV dengan RAG dan LLM
+func (wp *WorkerPool) evaluateCV(ctx context.Context, cvText, jobTitle string, evalCtx evaluationContext) (models.RubricResult, error) {
 	// Build prompt untuk LLM
 	prompt := fmt.Sprintf(`You are an expert technical recruiter evaluating a candidate's CV for a %s position.
 
@@ -195,60 +669,38 @@ Candidate's CV:
 %s
 
 Based on the job requirements and evaluation rubric, please:
-1. Rate the overall CV match (0.0 to 1.0 scale) based on:
-   - Technical skills match (40%%)
-   - Experience level (25%%)
-   - Relevant achievements (20%%)
-   - Cultural fit indicators (15%%)
-   
+1. Score EACH of the following parameters with a whole number from 1 to 5 using the rubric's scoring guide,
+   with a short justification citing evidence from the CV:
+%s
+
 2. Provide detailed feedback (3-5 sentences) covering strengths, gaps, and recommendations.
 
+Do NOT compute a weighted or overall score; it is calculated from your parameter scores.
+
 IMPORTANT: Your response MUST be valid JSON in this exact format:
-{
-  "match_rate": 0.82,
-  "feedback": "Your detailed feedback here..."
-}`, jobTitle, jdContext, rubricContext, cvText)
+%s`, jobTitle, evalCtx.JobDescription, evalCtx.CVRubric, cvText, cvRubric.promptSpec(), cvRubric.jsonExample())
 
-	// Call LLM
-	response, err := wp.ollamaClient.Generate(prompt, 0.3)
-	if err != nil {
-		return 0, "", fmt.Errorf("LLM call failed: %w", err)
+	// Call LLM dengan structured output (JSON schema) + validasi
+	var parsed rubricResponse
+	if err := wp.generateStructured(llm.WithStage(ctx, llm.StageCVEvaluation), prompt, 0.3, cvRubric.schema(), &parsed); err != nil {
+		return models.RubricResult{}, fmt.Errorf("CV scoring failed: %w", err)
 	}
 
-	// Parse response
-	matchRate, feedback, err := wp.parseCVResponse(response)
+	// Hitung match rate dari weighted average
+	weighted, breakdown, err := cvRubric.score(parsed)
 	if err != nil {
-		return 0, "", fmt.Errorf("failed to parse CV response: %w", err)
+		return models.RubricResult{}, fmt.Errorf("invalid CV scores: %w", err)
 	}
 
-	return matchRate, feedback, nil
+	return models.RubricResult{
+		Score:     matchRateFromWeighted(weighted),
+		Feedback:  strings.TrimSpace(parsed.Feedback),
+		Breakdown: breakdown,
+	}, nil
 }
 
 // evaluateProject melakukan evaluasi project report
-func (wp *WorkerPool) evaluateProject(reportText string) (float64, string, error) {
-	// Query vector DB untuk case study brief dan rubric
-	briefContext, err := wp.chromaClient.GetRelevantContext(
-		wp.ctx,
-		"case study brief requirements specifications",
-		"case_study_brief",
-		1,
-	)
-	if err != nil {
-		log.Printf("Warning: failed to get case study brief: %v", err)
-		briefContext = "Evaluate based on general backend project standards."
-	}
-
-	rubricContext, err := wp.chromaClient.GetRelevantContext(
-		wp.ctx,
-		"project evaluation rubric scoring criteria",
-		"project_rubric",
-		1,
-	)
-	if err != nil {
-		log.Printf("Warning: failed to get project rubric: %v", err)
-		rubricContext = "Evaluate based on standard project criteria."
-	}
-
+func (wp *WorkerPool) evaluateProject(ctx context.Context, reportText string, evalCtx evaluationContext) (models.RubricResult, error) {
 	// Build prompt
 	prompt := fmt.Sprintf(`You are an expert technical evaluator reviewing a candidate's project report.
 
@@ -262,46 +714,50 @@ Candidate's Project Report:
 %s
 
 Based on the requirements and rubric, please:
-1. Provide a score (1.0 to 5.0 scale) based on:
-   - Correctness & completeness (30%%)
-   - Code quality & structure (25%%)
-   - Resilience & error handling (20%%)
-   - Documentation quality (15%%)
-   - Creativity & extras (10%%)
-   
+1. Score EACH of the following parameters with a whole number from 1 to 5 using the rubric's scoring guide,
+   with a short justification citing evidence from the report:
+%s
+
 2. Provide detailed feedback (3-5 sentences) on strengths, weaknesses, and improvements.
 
+Do NOT compute a weighted or overall score; it is calculated from your parameter scores.
+
 IMPORTANT: Your response MUST be valid JSON in this exact format:
-{
-  "score": 4.2,
-  "feedback": "Your detailed feedback here..."
-}`, briefContext, rubricContext, reportText)
+%s`, evalCtx.CaseStudyBrief, evalCtx.ProjectRubric, reportText, projectRubric.promptSpec(), projectRubric.jsonExample())
 
-	// Call LLM
-	response, err := wp.ollamaClient.Generate(prompt, 0.3)
-	if err != nil {
-		return 0, "", fmt.Errorf("LLM call failed: %w", err)
+	// Call LLM dengan structured output (JSON schema) + validasi
+	var parsed rubricResponse
+	if err := wp.generateStructured(llm.WithStage(ctx, llm.StageProjectEvaluation), prompt, 0.3, projectRubric.schema(), &parsed); err != nil {
+		return models.RubricResult{}, fmt.Errorf("project scoring failed: %w", err)
 	}
 
-	// Parse response
-	score, feedback, err := wp.parseProjectResponse(response)
+	// Project score adalah weighted average (1-5)
+	weighted, breakdown, err := projectRubric.score(parsed)
 	if err != nil {
-		return 0, "", fmt.Errorf("failed to parse project response: %w", err)
+		return models.RubricResult{}, fmt.Errorf("invalid project scores: %w", err)
 	}
 
-	return score, feedback, nil
+	return models.RubricResult{
+		Score:     weighted,
+		Feedback:  strings.TrimSpace(parsed.Feedback),
+		Breakdown: breakdown,
+	}, nil
 }
 
 // generateOverallSummary membuat ringkasan keseluruhan
-func (wp *WorkerPool) generateOverallSummary(cvMatchRate float64, cvFeedback string, projectScore float64, projectFeedback, jobTitle string) (string, error) {
+func (wp *WorkerPool) generateOverallSummary(ctx context.Context, cvResult, projectResult models.RubricResult, jobTitle string) (string, error) {
 	prompt := fmt.Sprintf(`You are a senior technical hiring manager making a final decision on a candidate for a %s position.
 
 CV Evaluation:
 - Match Rate: %.2f (0-1 scale)
+- Parameter Scores (1-5):
+%s
 - Feedback: %s
 
 Project Evaluation:
-- Score: %.1f (1-5 scale)
+- Score: %.2f (1-5 scale)
+- Parameter Scores (1-5):
+%s
 - Feedback: %s
 
 Based on both evaluations, provide a 3-5 sentence overall summary that:
@@ -309,9 +765,13 @@ Based on both evaluations, provide a 3-5 sentence overall summary that:
 2. Identifies key gaps or concerns
 3. Gives a hiring recommendation (strong hire / hire / maybe / no hire)
 
-Be direct, professional, and actionable.`, jobTitle, cvMatchRate, cvFeedback, projectScore, projectFeedback)
+Be direct, professional, and actionable.`,
+		jobTitle,
+		cvResult.Score, formatBreakdown(cvResult.Breakdown), cvResult.Feedback,
+		projectResult.Score, formatBreakdown(projectResult.Breakdown), projectResult.Feedback,
+	)
 
-	response, err := wp.ollamaClient.Generate(prompt, 0.4)
+	response, err := wp.llmProvider.Generate(llm.WithStage(ctx, llm.StageSummary), prompt, 0.4)
 	if err != nil {
 		return "", fmt.Errorf("LLM call failed: %w", err)
 	}
@@ -319,73 +779,11 @@ Be direct, professional, and actionable.`, jobTitle, cvMatchRate, cvFeedback, pr
 	return strings.TrimSpace(response), nil
 }
 
-// parseCVResponse mem-parse JSON response dari LLM
-func (wp *WorkerPool) parseCVResponse(response string) (float64, string, error) {
-	var result struct {
-		MatchRate float64 `json:"match_rate"`
-		Feedback  string  `json:"feedback"`
+// formatBreakdown menuliskan skor per parameter untuk prompt summary
+func formatBreakdown(breakdown models.ScoreBreakdown) string {
+	var b strings.Builder
+	for _, p := range breakdown {
+		fmt.Fprintf(&b, "  - %s: %.0f - %s\n", p.Name, p.Score, p.Justification)
 	}
-
-	// Cari JSON block dalam response
-	jsonStart := strings.Index(response, "{")
-	jsonEnd := strings.LastIndex(response, "}")
-
-	if jsonStart >= 0 && jsonEnd > jsonStart {
-		jsonStr := response[jsonStart : jsonEnd+1]
-		if err := json.Unmarshal([]byte(jsonStr), &result); err == nil {
-			return result.MatchRate, result.Feedback, nil
-		}
-	}
-
-	// Fallback: extract dengan regex
-	matchRateRegex := regexp.MustCompile(`"?match_rate"?\s*:?\s*([0-9.]+)`)
-	feedbackRegex := regexp.MustCompile(`"?feedback"?\s*:?\s*"([^"]+)"`)
-
-	matchRateMatch := matchRateRegex.FindStringSubmatch(response)
-	feedbackMatch := feedbackRegex.FindStringSubmatch(response)
-
-	if len(matchRateMatch) > 1 && len(feedbackMatch) > 1 {
-		matchRate, err := strconv.ParseFloat(matchRateMatch[1], 64)
-		if err != nil {
-			return 0, "", fmt.Errorf("failed to parse match_rate: %w", err)
-		}
-		return matchRate, feedbackMatch[1], nil
-	}
-
-	return 0, "", fmt.Errorf("could not parse CV response: %s", response)
-}
-
-// parseProjectResponse mem-parse JSON response dari LLM
-func (wp *WorkerPool) parseProjectResponse(response string) (float64, string, error) {
-	var result struct {
-		Score    float64 `json:"score"`
-		Feedback string  `json:"feedback"`
-	}
-
-	jsonStart := strings.Index(response, "{")
-	jsonEnd := strings.LastIndex(response, "}")
-
-	if jsonStart >= 0 && jsonEnd > jsonStart {
-		jsonStr := response[jsonStart : jsonEnd+1]
-		if err := json.Unmarshal([]byte(jsonStr), &result); err == nil {
-			return result.Score, result.Feedback, nil
-		}
-	}
-
-	// Fallback regex
-	scoreRegex := regexp.MustCompile(`"?score"?\s*:?\s*([0-9.]+)`)
-	feedbackRegex := regexp.MustCompile(`"?feedback"?\s*:?\s*"([^"]+)"`)
-
-	scoreMatch := scoreRegex.FindStringSubmatch(response)
-	feedbackMatch := feedbackRegex.FindStringSubmatch(response)
-
-	if len(scoreMatch) > 1 && len(feedbackMatch) > 1 {
-		score, err := strconv.ParseFloat(scoreMatch[1], 64)
-		if err != nil {
-			return 0, "", fmt.Errorf("failed to parse score: %w", err)
-		}
-		return score, feedbackMatch[1], nil
-	}
-
-	return 0, "", fmt.Errorf("could not parse project response: %s", response)
+	return strings.TrimRight(b.String(), "\n")
 }
@@ -0,0 +1,512 @@
+package worker_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"cv-ai-evaluator/internal/handlers"
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+	"cv-ai-evaluator/internal/services"
+	"cv-ai-evaluator/internal/worker"
+	"cv-ai-evaluator/pkg/llm"
+	"cv-ai-evaluator/pkg/vectordb"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The fake provider's default summary, returned verbatim as overall_summary
+const fakeSummary = "The candidate shows strong backend fundamentals and delivered a working project. Main gap is production AI experience. Recommendation: hire."
+
+// TestEvaluationFlow runs upload → evaluate → result against the fake LLM
+// provider, the in-memory repositories and a local vector DB with a stub
+// embedder, so it needs neither a database, Ollama nor network access
+func TestEvaluationFlow(t *testing.T) {
+	tests := []struct {
+		name         string
+		configure    func(f *llm.FakeProvider)
+		wantStatus   models.JobStatus
+		wantAttempts int
+		wantStages   []string // stage of each recorded attempt error
+		wantLLMCalls int
+	}{
+		{
+			name:         "completes on first attempt",
+			configure:    func(f *llm.FakeProvider) {},
+			wantStatus:   models.JobStatusCompleted,
+			wantAttempts: 1,
+			wantLLMCalls: 3,
+		},
+		{
+			name:         "transient failure is retried",
+			configure:    func(f *llm.FakeProvider) { f.FailNext(1, nil) },
+			wantStatus:   models.JobStatusCompleted,
+			wantAttempts: 2,
+			wantStages:   []string{"CV evaluation failed"},
+			wantLLMCalls: 4,
+		},
+		{
+			// calls 2 (project) and 4 (summary) fail; each retry resumes from
+			// the last checkpoint instead of scoring the CV again
+			name:         "retries resume from checkpoint",
+			configure:    func(f *llm.FakeProvider) { f.FailEvery(2) },
+			wantStatus:   models.JobStatusCompleted,
+			wantAttempts: 3,
+			wantStages:   []string{"Project evaluation failed", "Summary generation failed"},
+			wantLLMCalls: 5,
+		},
+		{
+			name:         "exhausted retries go to dead letter",
+			configure:    func(f *llm.FakeProvider) { f.FailEvery(1) },
+			wantStatus:   models.JobStatusDeadLetter,
+			wantAttempts: 3,
+			wantStages:   []string{"CV evaluation failed", "CV evaluation failed", "CV evaluation failed"},
+			wantLLMCalls: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := llm.NewFakeProvider()
+			tt.configure(provider)
+			router := newTestServer(t, provider)
+
+			cvID, reportID := upload(t, router)
+			jobID := evaluate(t, router, cvID, reportID)
+			result := waitForResult(t, router, jobID)
+
+			if result.Status != string(tt.wantStatus) {
+				t.Fatalf("status = %s (error %q), want %s", result.Status, result.Error, tt.wantStatus)
+			}
+			if result.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", result.Attempts, tt.wantAttempts)
+			}
+			if len(result.ErrorHistory) != len(tt.wantStages) {
+				t.Fatalf("error_history has %d entries, want %d: %+v", len(result.ErrorHistory), len(tt.wantStages), result.ErrorHistory)
+			}
+			for i, entry := range result.ErrorHistory {
+				if entry.Attempt != i+1 || entry.Stage != tt.wantStages[i] || !entry.Retryable {
+					t.Errorf("error_history[%d] = attempt %d, stage %q, retryable %t; want attempt %d, stage %q, retryable",
+						i, entry.Attempt, entry.Stage, entry.Retryable, i+1, tt.wantStages[i])
+				}
+				if !strings.Contains(entry.Error, llm.ErrFakeInjected.Error()) {
+					t.Errorf("error_history[%d].error = %q, want the injected failure", i, entry.Error)
+				}
+			}
+			if calls := len(provider.Calls()); calls != tt.wantLLMCalls {
+				t.Errorf("LLM calls = %d, want %d", calls, tt.wantLLMCalls)
+			}
+
+			if prompt := provider.Calls()[0].Prompt; !strings.Contains(prompt, "Jane Doe, Backend Engineer") {
+				t.Errorf("CV text is missing from the first prompt:\n%s", prompt)
+			}
+			if len(result.GroundTruth) != 4 {
+				t.Errorf("ground_truth has %d refs, want one per type", len(result.GroundTruth))
+			}
+
+			if tt.wantStatus != models.JobStatusCompleted {
+				if result.Result != nil {
+					t.Errorf("result = %+v, want none for status %s", result.Result, result.Status)
+				}
+				if !strings.HasPrefix(result.Error, "giving up after 3 attempts") {
+					t.Errorf("error = %q, want the dead letter reason", result.Error)
+				}
+				return
+			}
+
+			if result.Error != "" {
+				t.Errorf("error = %q, want none for a completed job", result.Error)
+			}
+			for _, gtType := range services.AllGroundTruthTypes() {
+				if !hasChunk(result.RetrievedChunks, gtType) {
+					t.Errorf("retrieved_chunks has no %s section", gtType)
+				}
+			}
+			assertFakeResult(t, result.Result)
+		})
+	}
+}
+
+// TestDebugRetrievalMatchesJob checks that GET /debug/retrieval for a job
+// title pins the same documents as the job and selects the sections the
+// worker put in the job's prompts
+func TestDebugRetrievalMatchesJob(t *testing.T) {
+	router := newTestServer(t, llm.NewFakeProvider())
+	cvID, reportID := upload(t, router)
+	result := waitForResult(t, router, evaluate(t, router, cvID, reportID))
+	if result.Status != string(models.JobStatusCompleted) {
+		t.Fatalf("status = %s (error %q), want completed", result.Status, result.Error)
+	}
+
+	for _, gtType := range services.AllGroundTruthTypes() {
+		query := url.Values{"job_title": {"Backend Engineer"}, "document_type": {string(gtType)}}
+		req := httptest.NewRequest(http.MethodGet, "/debug/retrieval?"+query.Encode(), nil)
+		var debug handlers.RetrievalDebugResponse
+		do(t, router, req, http.StatusOK, &debug)
+
+		ref, _ := result.GroundTruth.Find(gtType)
+		if debug.Document.ID != ref.ID {
+			t.Errorf("%s: debug ranked document %s, job pinned %s", gtType, debug.Document.ID, ref.ID)
+		}
+
+		var selected, retrieved []string
+		for _, chunk := range debug.Data {
+			if chunk.Selected {
+				selected = append(selected, chunk.Section)
+			}
+		}
+		for _, chunk := range result.RetrievedChunks {
+			if chunk.Type == gtType {
+				retrieved = append(retrieved, chunk.Section)
+			}
+		}
+		slices.Sort(selected)
+		slices.Sort(retrieved)
+		if len(selected) == 0 || !slices.Equal(selected, retrieved) {
+			t.Errorf("%s: debug selected sections %q, job retrieved %q", gtType, selected, retrieved)
+		}
+	}
+}
+
+// TestStructuredSchemasAreStrict checks that every schema the worker sends is
+// accepted by OpenAI strict mode once the unsupported range keywords are
+// dropped, while the full schema still rejects an out-of-range score
+func TestStructuredSchemasAreStrict(t *testing.T) {
+	provider := llm.NewFakeProvider()
+	router := newTestServer(t, provider)
+
+	cvID, reportID := upload(t, router)
+	jobID := evaluate(t, router, cvID, reportID)
+	if result := waitForResult(t, router, jobID); result.Status != string(models.JobStatusCompleted) {
+		t.Fatalf("status = %s (error %q), want completed", result.Status, result.Error)
+	}
+
+	schemas := 0
+	for _, call := range provider.Calls() {
+		if call.Schema == nil {
+			continue
+		}
+		schemas++
+		if violations := call.Schema.Strict().StrictViolations(); len(violations) > 0 {
+			t.Errorf("%s schema is not strict-mode valid: %v", call.Stage, violations)
+		}
+
+		var response map[string]interface{}
+		if err := json.Unmarshal([]byte(regexp.MustCompile(`"score":\s*\d+`).ReplaceAllString(call.Response, `"score": 6`)), &response); err != nil {
+			t.Fatalf("decode %s response: %v", call.Stage, err)
+		}
+		if len(call.Schema.Validate(response)) == 0 {
+			t.Errorf("%s schema accepted a score of 6", call.Stage)
+		}
+	}
+	if schemas != 2 {
+		t.Errorf("got %d structured calls, want one per rubric", schemas)
+	}
+}
+
+// TestCallbackRequiresWebhookSecret checks that a job with a callback URL is
+// refused while the server has no secret to sign the callback with
+func TestCallbackRequiresWebhookSecret(t *testing.T) {
+	router := newTestServer(t, llm.NewFakeProvider())
+	cvID, reportID := upload(t, router)
+
+	payload, _ := json.Marshal(handlers.EvaluateRequest{
+		JobTitle:    "Backend Engineer",
+		CVId:        cvID,
+		ReportId:    reportID,
+		CallbackURL: "https://ats.example.com/hooks",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	var resp map[string]string
+	do(t, router, req, http.StatusBadRequest, &resp)
+	if !strings.Contains(resp["error"], "WEBHOOK_SECRET") {
+		t.Errorf("error = %q, want it to name the missing secret", resp["error"])
+	}
+}
+
+// assertFakeResult checks the scores the server computes from the fake
+// provider's default rubric answers
+func assertFakeResult(t *testing.T, got *handlers.EvaluationResult) {
+	t.Helper()
+	if got == nil {
+		t.Fatal("result is missing")
+	}
+	// CV: 4*0.40 + 3*0.25 + 3*0.20 + 4*0.15 = 3.55 → 0.71
+	if got.CVMatchRate != 0.71 {
+		t.Errorf("cv_match_rate = %v, want 0.71", got.CVMatchRate)
+	}
+	// Project: 4*0.30 + 4*0.25 + 3*0.20 + 4*0.15 + 3*0.10 = 3.7
+	if got.ProjectScore != 3.7 {
+		t.Errorf("project_score = %v, want 3.7", got.ProjectScore)
+	}
+	if len(got.CVScoreBreakdown) != 4 || len(got.ProjectScoreBreakdown) != 5 {
+		t.Errorf("breakdowns have %d CV and %d project parameters, want 4 and 5",
+			len(got.CVScoreBreakdown), len(got.ProjectScoreBreakdown))
+	}
+	if !strings.HasPrefix(got.CVFeedback, "Solid backend experience") {
+		t.Errorf("cv_feedback = %q", got.CVFeedback)
+	}
+	if !strings.HasPrefix(got.ProjectFeedback, "Meets the core requirements") {
+		t.Errorf("project_feedback = %q", got.ProjectFeedback)
+	}
+	if got.OverallSummary != fakeSummary {
+		t.Errorf("overall_summary = %q, want %q", got.OverallSummary, fakeSummary)
+	}
+}
+
+func hasChunk(chunks models.RetrievedChunks, gtType models.GroundTruthType) bool {
+	for _, chunk := range chunks {
+		if chunk.Type == gtType && chunk.DocumentID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// newTestServer wires the API routes of the evaluation flow the way main does,
+// with authentication disabled
+func newTestServer(t *testing.T, provider llm.Provider) http.Handler {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+
+	chromaClient, err := vectordb.NewChromaClientWithEmbedding(filepath.Join(dir, "chroma"), stubEmbedding, vectordb.ChunkOptions{})
+	if err != nil {
+		t.Fatalf("failed to open vector DB: %v", err)
+	}
+
+	documentRepo := repository.NewMemoryDocumentRepository()
+	jobRepo := repository.NewMemoryJobRepository(documentRepo)
+	documentService := services.NewDocumentService(filepath.Join(dir, "uploads"), documentRepo)
+	evaluationService := services.NewEvaluationService(jobRepo)
+	groundTruthService := services.NewGroundTruthService(repository.NewMemoryGroundTruthRepository(), chromaClient, filepath.Join(dir, "groundtruth"))
+	organizationService := services.NewOrganizationService(repository.NewMemoryOrganizationRepository())
+	progressService := services.NewProgressService(jobRepo)
+
+	ingestGroundTruth(t, groundTruthService, filepath.Join(dir, "sources"))
+
+	retriever := vectordb.NewRetriever(chromaClient, vectordb.RetrieverConfig{Hybrid: true})
+	// smaller than every ground truth document, so sections are retrieved
+	const contextTokens = 40
+	workerPool := worker.NewWorkerPool(worker.PoolConfig{
+		WorkerCount:   2,
+		LeaseDuration: 5 * time.Second,
+		PollInterval:  10 * time.Millisecond,
+		JobTimeout:    10 * time.Second,
+		ContextTokens: contextTokens,
+		Retry: worker.RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   10 * time.Millisecond,
+			MaxDelay:    20 * time.Millisecond,
+		},
+		MaxRepairAttempts: 1,
+		DocumentReader:    pdfStringReader{},
+	}, provider, retriever, evaluationService, groundTruthService)
+	workerPool.Start()
+	t.Cleanup(workerPool.Stop)
+
+	router := gin.New()
+	uploadHandler := handlers.NewUploadHandler(documentService)
+	evaluateHandler := handlers.NewEvaluateHandler(workerPool, documentService, evaluationService, groundTruthService, organizationService, "", services.NewCallbackURLPolicy(nil), "")
+	resultHandler := handlers.NewResultHandler(evaluationService, progressService)
+	retrievalHandler := handlers.NewRetrievalHandler(services.NewRetrievalService(retriever, groundTruthService, contextTokens))
+	router.POST("/upload", uploadHandler.Upload)
+	router.POST("/evaluate", evaluateHandler.Evaluate)
+	router.GET("/result/:id", resultHandler.GetResult)
+	router.GET("/debug/retrieval", retrievalHandler.Debug)
+	return router
+}
+
+// ingestGroundTruth syncs one markdown document per type into the default organisation
+func ingestGroundTruth(t *testing.T, groundTruthService *services.GroundTruthService, root string) {
+	t.Helper()
+	documents := map[models.GroundTruthType]string{
+		models.GroundTruthTypeJobDescription: "# Backend Engineer\n\n## Responsibilities\n\nBuild and operate Go services, REST APIs and PostgreSQL schemas.\n\n## Requirements\n\nThree or more years of backend work and exposure to LLM integration.\n",
+		models.GroundTruthTypeCaseStudyBrief: "# Case Study\n\n## Task\n\nBuild an API that scores a CV and a project report against a job description with an LLM.\n\n## Constraints\n\nJobs are asynchronous and must survive LLM failures with retries.\n",
+		models.GroundTruthTypeCVRubric:       "# CV Rubric\n\n## Technical Skills\n\nScore 1-5 on backend, databases, APIs, cloud and AI exposure.\n\n## Experience\n\nScore 1-5 on years of experience and project complexity.\n",
+		models.GroundTruthTypeProjectRubric:  "# Project Rubric\n\n## Correctness\n\nScore 1-5 on prompt design, chaining and RAG context injection.\n\n## Resilience\n\nScore 1-5 on retries, timeouts and handling of API failures.\n",
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var sources []services.GroundTruthSource
+	for gtType, content := range documents {
+		path := filepath.Join(root, string(gtType)+".md")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, services.GroundTruthSource{Path: path, DocumentType: gtType})
+	}
+
+	report, err := groundTruthService.SyncSources(context.Background(), models.DefaultOrganizationID, root, sources, false)
+	if err != nil {
+		t.Fatalf("failed to ingest ground truth: %v", err)
+	}
+	if created := report.Count(services.IngestCreate); created != len(documents) {
+		t.Fatalf("ingested %d ground truth documents, want %d", created, len(documents))
+	}
+}
+
+// stubEmbedding hashes the words of text into a small normalised vector, so
+// chunks sharing words with the query rank higher without an embedding model
+func stubEmbedding(_ context.Context, text string) ([]float32, error) {
+	vector := make([]float32, 32)
+	for _, term := range vectordb.Tokenize(text) {
+		h := fnv.New32a()
+		h.Write([]byte(term))
+		vector[h.Sum32()%uint32(len(vector))]++
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		vector[0], norm = 1, 1
+	}
+	for i := range vector {
+		vector[i] /= float32(math.Sqrt(norm))
+	}
+	return vector, nil
+}
+
+func upload(t *testing.T, router http.Handler) (cvID, reportID string) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	files := map[string][]string{
+		"cv":     {"Jane Doe, Backend Engineer", "Go, PostgreSQL, Kubernetes and REST APIs", "Five years building microservices"},
+		"report": {"Project report: CV evaluation service", "Asynchronous jobs with retries and RAG over the rubrics"},
+	}
+	for field, lines := range files {
+		part, err := form.CreateFormFile(field, field+".pdf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(textPDF(lines))
+	}
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	var resp handlers.UploadResponse
+	do(t, router, req, http.StatusOK, &resp)
+	return resp.CVDocumentID, resp.ReportDocumentID
+}
+
+func evaluate(t *testing.T, router http.Handler, cvID, reportID string) string {
+	t.Helper()
+	payload, _ := json.Marshal(handlers.EvaluateRequest{JobTitle: "Backend Engineer", CVId: cvID, ReportId: reportID})
+	req := httptest.NewRequest(http.MethodPost, "/evaluate", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	var resp handlers.EvaluateResponse
+	do(t, router, req, http.StatusOK, &resp)
+	if resp.Status != string(models.JobStatusQueued) {
+		t.Fatalf("evaluate returned status %q, want queued", resp.Status)
+	}
+	return resp.ID
+}
+
+// waitForResult polls GET /result/:id until the job reaches a terminal status
+func waitForResult(t *testing.T, router http.Handler, jobID string) handlers.ResultResponse {
+	t.Helper()
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		var resp handlers.ResultResponse
+		do(t, router, httptest.NewRequest(http.MethodGet, "/result/"+jobID, nil), http.StatusOK, &resp)
+		if models.JobStatus(resp.Status).IsTerminal() {
+			return resp
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s still %s after 15s", jobID, resp.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func do(t *testing.T, router http.Handler, req *http.Request, wantStatus int, out interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != wantStatus {
+		t.Fatalf("%s %s returned %d, want %d: %s", req.Method, req.URL.Path, rec.Code, wantStatus, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+		t.Fatalf("%s %s returned invalid JSON: %v", req.Method, req.URL.Path, err)
+	}
+}
+
+// pdfStringReader reads the text strings of a PDF made by textPDF. unipdf
+// cannot extract text without a license key, which needs network access.
+type pdfStringReader struct{}
+
+var pdfString = regexp.MustCompile(`\(([^)]*)\) '`)
+
+func (pdfStringReader) ReadDocument(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, match := range pdfString.FindAllSubmatch(data, -1) {
+		lines = append(lines, string(match[1]))
+	}
+	if len(lines) == 0 {
+		return "", fmt.Errorf("no text in %s", filePath)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (pdfStringReader) CleanText(text string) string {
+	return strings.TrimSpace(text)
+}
+
+// textPDF builds a one-page PDF with a line of Helvetica text per entry
+func textPDF(lines []string) []byte {
+	var content strings.Builder
+	content.WriteString("BT /F1 12 Tf 50 750 Td 14 TL")
+	for _, line := range lines {
+		fmt.Fprintf(&content, " (%s) '", line)
+	}
+	content.WriteString(" ET")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdf.Len()
+		fmt.Fprintf(&pdf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := pdf.Len()
+	fmt.Fprintf(&pdf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return pdf.Bytes()
+}
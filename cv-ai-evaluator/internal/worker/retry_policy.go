@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"cv-ai-evaluator/pkg/llm"
+)
+
+// RetryPolicy mengatur percobaan ulang job yang gagal karena error sementara
+type RetryPolicy struct {
+	MaxAttempts int           // total percobaan termasuk yang pertama
+	BaseDelay   time.Duration // jeda sebelum percobaan kedua
+	MaxDelay    time.Duration // batas atas jeda exponential backoff
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = 30 * time.Second
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = 10 * time.Minute
+	}
+	return p
+}
+
+// Backoff menghitung jeda sebelum percobaan berikutnya setelah attempt gagal:
+// exponential (BaseDelay * 2^(attempt-1), dibatasi MaxDelay) dengan jitter
+// acak di rentang [d/2, d] agar job yang gagal bersamaan tidak retry serentak
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// permanentError menandai error yang tidak akan berhasil jika diulang,
+// misalnya PDF yang tidak bisa dibaca
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// isRetryable mengklasifikasikan error stage: error input dan 4xx dari server
+// LLM bersifat permanen; error jaringan, 5xx/429, timeout, dan output LLM yang
+// tidak valid (model bersifat acak) layak dicoba lagi
+func isRetryable(err error) bool {
+	var permErr *permanentError
+	if errors.As(err, &permErr) {
+		return false
+	}
+
+	var statusErr *llm.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Retryable()
+	}
+
+	if errors.Is(err, ErrJobCancelled) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	return true
+}
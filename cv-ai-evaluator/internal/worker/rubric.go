@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/pkg/llm"
+)
+
+// rubricParameter adalah satu parameter pada scoring rubric (skala 1-5)
+type rubricParameter struct {
+	Key         string
+	Name        string
+	Weight      float64
+	Description string
+}
+
+// rubric adalah kumpulan parameter berbobot; bobot total harus 1.0.
+// Parameter dan bobot tetap di kode: dokumen rubric ground truth hanya masuk ke
+// prompt sebagai panduan skor, sehingga mengganti dokumen tersebut tidak
+// mengubah parameter maupun bobot yang dipakai untuk menghitung skor.
+type rubric []rubricParameter
+
+// cvRubric mengikuti storage/groundtruth/cv_scoring_rubric.md
+var cvRubric = rubric{
+	{Key: "technical_skills", Name: "Technical Skills Match", Weight: 0.40, Description: "backend, databases, APIs, cloud, AI/LLM exposure"},
+	{Key: "experience_level", Name: "Experience Level", Weight: 0.25, Description: "years of experience and project complexity"},
+	{Key: "relevant_achievements", Name: "Relevant Achievements", Weight: 0.20, Description: "measurable impact of past work"},
+	{Key: "cultural_fit", Name: "Cultural / Collaboration Fit", Weight: 0.15, Description: "communication, learning mindset, teamwork/leadership"},
+}
+
+// projectRubric mengikuti storage/groundtruth/project_scoring_rubric.md
+var projectRubric = rubric{
+	{Key: "correctness", Name: "Correctness (Prompt & Chaining)", Weight: 0.30, Description: "prompt design, LLM chaining, RAG context injection"},
+	{Key: "code_quality", Name: "Code Quality & Structure", Weight: 0.25, Description: "clean, modular, reusable, tested"},
+	{Key: "resilience", Name: "Resilience & Error Handling", Weight: 0.20, Description: "long jobs, retries, randomness, API failures"},
+	{Key: "documentation", Name: "Documentation & Explanation", Weight: 0.15, Description: "README clarity, setup instructions, trade-offs"},
+	{Key: "creativity", Name: "Creativity / Bonus", Weight: 0.10, Description: "extra features beyond requirements"},
+}
+
+// parameterAssessment adalah jawaban LLM untuk satu parameter; schema
+// membatasi Score ke bilangan bulat 1-5
+type parameterAssessment struct {
+	Score         float64 `json:"score"`
+	Justification string  `json:"justification"`
+}
+
+// rubricResponse adalah format JSON yang diminta dari LLM
+type rubricResponse struct {
+	Scores   map[string]parameterAssessment `json:"scores"`
+	Feedback string                         `json:"feedback"`
+}
+
+// promptSpec menuliskan daftar parameter untuk disisipkan ke prompt
+func (r rubric) promptSpec() string {
+	var b strings.Builder
+	for _, p := range r {
+		fmt.Fprintf(&b, "   - %s: %s (weight %.0f%%) - %s\n", p.Key, p.Name, p.Weight*100, p.Description)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// jsonExample menuliskan contoh format JSON jawaban untuk prompt
+func (r rubric) jsonExample() string {
+	var b strings.Builder
+	b.WriteString("{\n  \"scores\": {\n")
+	for i, p := range r {
+		fmt.Fprintf(&b, "    %q: {\"score\": 4, \"justification\": \"One or two sentences citing evidence...\"}", p.Key)
+		if i < len(r)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("  },\n  \"feedback\": \"Your detailed feedback here...\"\n}")
+	return b.String()
+}
+
+// schema membangun JSON schema untuk jawaban rubric: setiap parameter wajib
+// ada dengan skor bulat 1-5 dan justifikasi, ditambah feedback. Setiap object
+// tertutup (additionalProperties false) agar lolos strict mode OpenAI.
+func (r rubric) schema() *llm.Schema {
+	minScore, maxScore := 1.0, 5.0
+	minLength := 1
+	closed := false
+
+	params := make(map[string]*llm.Schema, len(r))
+	required := make([]string, 0, len(r))
+	for _, p := range r {
+		params[p.Key] = &llm.Schema{
+			Type:        "object",
+			Description: p.Name,
+			Properties: map[string]*llm.Schema{
+				"score":         {Type: "integer", Minimum: &minScore, Maximum: &maxScore},
+				"justification": {Type: "string", MinLength: &minLength},
+			},
+			Required:             []string{"score", "justification"},
+			AdditionalProperties: &closed,
+		}
+		required = append(required, p.Key)
+	}
+
+	return &llm.Schema{
+		Type: "object",
+		Properties: map[string]*llm.Schema{
+			"scores": {
+				Type:                 "object",
+				Properties:           params,
+				Required:             required,
+				AdditionalProperties: &closed,
+			},
+			"feedback": {Type: "string", MinLength: &minLength},
+		},
+		Required:             []string{"scores", "feedback"},
+		AdditionalProperties: &closed,
+	}
+}
+
+// score memvalidasi jawaban LLM dan menghitung weighted average (skala 1-5)
+// secara deterministik di server
+func (r rubric) score(resp rubricResponse) (float64, models.ScoreBreakdown, error) {
+	breakdown := make(models.ScoreBreakdown, 0, len(r))
+	var weighted, totalWeight float64
+
+	for _, p := range r {
+		assessment, ok := resp.Scores[p.Key]
+		if !ok {
+			return 0, nil, fmt.Errorf("missing score for parameter %q", p.Key)
+		}
+		if assessment.Score < 1 || assessment.Score > 5 {
+			return 0, nil, fmt.Errorf("score for %q out of range 1-5: %v", p.Key, assessment.Score)
+		}
+
+		weighted += assessment.Score * p.Weight
+		totalWeight += p.Weight
+		breakdown = append(breakdown, models.ParameterScore{
+			Parameter:     p.Key,
+			Name:          p.Name,
+			Weight:        p.Weight,
+			Score:         assessment.Score,
+			Justification: strings.TrimSpace(assessment.Justification),
+		})
+	}
+
+	return roundTo(weighted/totalWeight, 2), breakdown, nil
+}
+
+// matchRateFromWeighted mengonversi weighted score (1-5) ke match rate (0-1)
+func matchRateFromWeighted(weighted float64) float64 {
+	return roundTo(weighted*0.2, 2)
+}
+
+func roundTo(value float64, decimals int) float64 {
+	pow := math.Pow(10, float64(decimals))
+	return math.Round(value*pow) / pow
+}
@@ -0,0 +1,14 @@
+package worker
+
+import (
+	"context"
+
+	"cv-ai-evaluator/pkg/llm"
+)
+
+// generateStructured meminta jawaban JSON sesuai schema lewat
+// llm.GenerateStructured, dengan jumlah repair dibatasi oleh
+// PoolConfig.MaxRepairAttempts
+func (wp *WorkerPool) generateStructured(ctx context.Context, prompt string, temperature float64, schema *llm.Schema, out interface{}) error {
+	return llm.GenerateStructured(ctx, wp.llmProvider, prompt, temperature, schema, wp.cfg.MaxRepairAttempts, out)
+}
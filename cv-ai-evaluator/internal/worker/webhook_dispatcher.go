@@ -0,0 +1,250 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/services"
+)
+
+const (
+	// Batas body response yang disimpan per percobaan
+	maxWebhookResponseBody = 1024
+)
+
+// WebhookConfig mengatur pengiriman callback hasil evaluasi
+type WebhookConfig struct {
+	// Secret HMAC-SHA256 untuk organisasi yang tidak punya webhook secret
+	// sendiri; tanpa secret callback tidak dikirim
+	Secret       string
+	Timeout      time.Duration
+	PollInterval time.Duration
+	Retry        RetryPolicy
+	// CallbackPolicy membatasi host yang boleh dihubungi; default hanya host publik
+	CallbackPolicy *services.CallbackURLPolicy
+}
+
+func (c WebhookConfig) withDefaults() WebhookConfig {
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = 2 * time.Second
+	}
+	if c.Retry.MaxAttempts <= 0 {
+		c.Retry.MaxAttempts = 5
+	}
+	if c.Retry.BaseDelay <= 0 {
+		c.Retry.BaseDelay = 10 * time.Second
+	}
+	c.Retry = c.Retry.withDefaults()
+	if c.CallbackPolicy == nil {
+		c.CallbackPolicy = services.NewCallbackURLPolicy(nil)
+	}
+	return c
+}
+
+// WebhookPayloadFunc membangun body callback dari job yang sudah selesai
+type WebhookPayloadFunc func(job *models.EvaluationJob) interface{}
+
+// WebhookDispatcher mengirim hasil job ke callback_url. Antrian pengiriman
+// disimpan di tabel evaluation_jobs (webhook_status = pending), sehingga
+// pengiriman yang tertunda tetap dilanjutkan setelah restart.
+type WebhookDispatcher struct {
+	cfg                 WebhookConfig
+	client              *http.Client
+	webhookService      *services.WebhookService
+	organizationService *services.OrganizationService
+	payload             WebhookPayloadFunc
+	wg                  sync.WaitGroup
+	ctx                 context.Context
+	cancel              context.CancelFunc
+}
+
+func NewWebhookDispatcher(cfg WebhookConfig, webhookService *services.WebhookService, organizationService *services.OrganizationService, payload WebhookPayloadFunc) *WebhookDispatcher {
+	cfg = cfg.withDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &WebhookDispatcher{
+		cfg:                 cfg,
+		client:              cfg.CallbackPolicy.HTTPClient(cfg.Timeout),
+		webhookService:      webhookService,
+		organizationService: organizationService,
+		payload:             payload,
+		ctx:                 ctx,
+		cancel:              cancel,
+	}
+}
+
+// Start menjalankan loop pengiriman di background
+func (d *WebhookDispatcher) Start() {
+	d.wg.Add(1)
+	go d.run()
+}
+
+// Stop menghentikan dispatcher dan menunggu pengiriman yang sedang berjalan
+func (d *WebhookDispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+func (d *WebhookDispatcher) run() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Kirim semua webhook yang jatuh tempo sebelum menunggu tick berikutnya
+		for d.ctx.Err() == nil {
+			// Reservasi cukup lama untuk satu request, setelah itu bisa diambil lagi
+			job, err := d.webhookService.ClaimNextDelivery(2 * d.cfg.Timeout)
+			if err != nil {
+				log.Printf("Webhook dispatcher failed to claim delivery: %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			d.deliver(job)
+		}
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver mengirim satu percobaan webhook dan menjadwalkan retry jika gagal
+func (d *WebhookDispatcher) deliver(job *models.EvaluationJob) {
+	event := "job." + string(job.Status)
+	delivery := &models.WebhookDelivery{
+		JobID:   job.ID,
+		URL:     job.CallbackURL.String,
+		Event:   event,
+		Attempt: job.WebhookAttempts,
+	}
+
+	secret, err := d.signingSecret(job)
+	// Penerima memverifikasi signature, jadi callback tanpa secret tidak
+	// pernah dikirim dan tidak ada gunanya di-retry
+	if err == nil && secret == "" {
+		delivery.Error = sql.NullString{String: "no webhook secret configured, refusing to send an unsigned callback", Valid: true}
+		if err := d.webhookService.RecordAttempt(delivery); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		log.Printf("Webhook for job %s not sent: no webhook secret configured", job.ID)
+		if err := d.webhookService.MarkFailed(job.ID); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		return
+	}
+
+	var statusCode int
+	var respBody string
+	start := time.Now()
+	if err == nil {
+		statusCode, respBody, err = d.post(job, event, secret)
+	}
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	delivery.StatusCode = statusCode
+	if respBody != "" {
+		delivery.ResponseBody = sql.NullString{String: respBody, Valid: true}
+	}
+	if err == nil && (statusCode < 200 || statusCode >= 300) {
+		err = fmt.Errorf("callback returned status %d", statusCode)
+	}
+	if err != nil {
+		delivery.Error = sql.NullString{String: err.Error(), Valid: true}
+	}
+	delivery.Success = err == nil
+
+	if recErr := d.webhookService.RecordAttempt(delivery); recErr != nil {
+		log.Printf("Warning: %v", recErr)
+	}
+
+	var updateErr error
+	switch {
+	case err == nil:
+		updateErr = d.webhookService.MarkDelivered(job.ID)
+	case job.WebhookAttempts >= d.cfg.Retry.MaxAttempts:
+		log.Printf("Webhook for job %s failed after %d attempts: %v", job.ID, job.WebhookAttempts, err)
+		updateErr = d.webhookService.MarkFailed(job.ID)
+	default:
+		delay := d.cfg.Retry.Backoff(job.WebhookAttempts)
+		log.Printf("Webhook for job %s failed (attempt %d/%d), retrying in %s: %v",
+			job.ID, job.WebhookAttempts, d.cfg.Retry.MaxAttempts, delay.Round(time.Second), err)
+		updateErr = d.webhookService.ScheduleRetry(job.ID, time.Now().Add(delay))
+	}
+	if updateErr != nil {
+		log.Printf("Warning: %v", updateErr)
+	}
+}
+
+// signingSecret memilih secret organisasi pemilik job, atau secret global
+// jika organisasi belum punya
+func (d *WebhookDispatcher) signingSecret(job *models.EvaluationJob) (string, error) {
+	secret, err := d.organizationService.WebhookSecret(job.OrganizationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load webhook secret: %w", err)
+	}
+	if secret == "" {
+		secret = d.cfg.Secret
+	}
+	return secret, nil
+}
+
+func (d *WebhookDispatcher) post(job *models.EvaluationJob, event, secret string) (int, string, error) {
+	body, err := json.Marshal(d.payload(job))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, job.CallbackURL.String, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cv-ai-evaluator-webhook")
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Job-ID", job.ID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhook(secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBody))
+	return resp.StatusCode, string(respBody), nil
+}
+
+// SignWebhook menghitung HMAC-SHA256 (hex) atas "<timestamp>.<body>".
+// Penerima menghitung ulang nilai ini dan membandingkannya dengan header
+// X-Webhook-Signature; timestamp mencegah replay request lama.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,121 @@
+package worker_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cv-ai-evaluator/internal/models"
+	"cv-ai-evaluator/internal/repository"
+	"cv-ai-evaluator/internal/services"
+	"cv-ai-evaluator/internal/worker"
+)
+
+// TestWebhookSignedPerOrganization checks that callbacks are signed with the
+// secret of the job's organisation, that organisations without one fall back
+// to the global secret, and that nothing is sent when neither exists
+func TestWebhookSignedPerOrganization(t *testing.T) {
+	type callback struct {
+		timestamp, signature string
+		body                 []byte
+	}
+	received := make(chan callback, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- callback{r.Header.Get("X-Webhook-Timestamp"), r.Header.Get("X-Webhook-Signature"), body}
+	}))
+	t.Cleanup(receiver.Close)
+
+	organizationService := services.NewOrganizationService(repository.NewMemoryOrganizationRepository())
+	org, err := organizationService.CreateOrganization("PT Client", "")
+	if err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	if !strings.HasPrefix(org.WebhookSecret.String, "whsec_") {
+		t.Fatalf("organization webhook secret = %q, want a generated secret", org.WebhookSecret.String)
+	}
+
+	tests := []struct {
+		name         string
+		org          string
+		globalSecret string
+		wantSecret   string // "" means no callback is sent
+	}{
+		{"organization secret", org.ID, "global-secret", org.WebhookSecret.String},
+		{"global secret without an organization secret", models.DefaultOrganizationID, "global-secret", "global-secret"},
+		{"no secret at all", models.DefaultOrganizationID, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documentRepo := repository.NewMemoryDocumentRepository()
+			jobRepo := repository.NewMemoryJobRepository(documentRepo)
+			evaluationService := services.NewEvaluationService(jobRepo)
+			webhookService := services.NewWebhookService(jobRepo, repository.NewMemoryWebhookDeliveryRepository())
+
+			job, err := evaluationService.CreateEvaluationJob(services.NewJob{
+				CVDocumentID:     "cv",
+				ReportDocumentID: "report",
+				JobTitle:         "Backend Engineer",
+				CallbackURL:      receiver.URL,
+			}, services.Scope{OrganizationID: tt.org})
+			if err != nil {
+				t.Fatalf("create job: %v", err)
+			}
+			if err := evaluationService.FailJob(job.ID, "failed", nil); err != nil {
+				t.Fatalf("fail job: %v", err)
+			}
+
+			dispatcher := worker.NewWebhookDispatcher(worker.WebhookConfig{
+				Secret:         tt.globalSecret,
+				PollInterval:   10 * time.Millisecond,
+				CallbackPolicy: services.NewCallbackURLPolicy([]string{"127.0.0.1"}),
+			}, webhookService, organizationService, func(job *models.EvaluationJob) interface{} {
+				return map[string]string{"id": job.ID}
+			})
+			dispatcher.Start()
+			t.Cleanup(dispatcher.Stop)
+
+			if tt.wantSecret == "" {
+				waitForWebhookStatus(t, evaluationService, job.ID, models.WebhookStatusFailed)
+				select {
+				case <-received:
+					t.Fatalf("an unsigned callback was sent")
+				default:
+				}
+				return
+			}
+
+			select {
+			case got := <-received:
+				want := "sha256=" + worker.SignWebhook(tt.wantSecret, got.timestamp, got.body)
+				if got.signature != want {
+					t.Errorf("signature = %q, want %q", got.signature, want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("no callback received")
+			}
+		})
+	}
+}
+
+func waitForWebhookStatus(t *testing.T, evaluationService *services.EvaluationService, jobID, status string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := evaluationService.GetJobByID(jobID)
+		if err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+		if job.WebhookStatus.String == status {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("webhook status = %q, want %q", job.WebhookStatus.String, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,23 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// StatusError adalah response non-200 dari server LLM
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// Retryable bernilai true untuk error sementara (overload, rate limit, 5xx);
+// 4xx lain (model tidak ada, request salah, auth) tidak akan sembuh jika diulang
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
@@ -0,0 +1,248 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrFakeInjected adalah error default untuk failure injection FakeProvider
+var ErrFakeInjected = errors.New("fake provider: injected failure")
+
+// fakeStagePatterns mencocokkan prompt dari internal/worker ke stage-nya,
+// dipakai jika ctx tidak ditandai dengan WithStage
+var fakeStagePatterns = map[string]*regexp.Regexp{
+	StageCVEvaluation:      regexp.MustCompile(`(?i)evaluating a candidate's CV`),
+	StageProjectEvaluation: regexp.MustCompile(`(?i)reviewing a candidate's project report`),
+	StageSummary:           regexp.MustCompile(`(?i)making a final decision on a candidate`),
+	StageRerank:            regexp.MustCompile(`(?i)ranking passages of hiring ground truth`),
+}
+
+// fakeRerankPassage mencocokkan penanda "[n]" setiap passage di prompt rerank
+var fakeRerankPassage = regexp.MustCompile(`(?m)^\[(\d+)\]$`)
+
+// FakeRule adalah satu jawaban terprogram untuk prompt yang cocok
+type FakeRule struct {
+	Stage    string         // cocokkan berdasarkan stage (opsional)
+	Pattern  *regexp.Regexp // cocokkan berdasarkan regex prompt (opsional)
+	Response string
+	// Respond (opsional) menyusun jawaban dari prompt, menggantikan Response
+	Respond func(prompt string) string
+	Err     error
+	Latency time.Duration // override latency global jika > 0
+	Times   int           // berapa kali rule berlaku, 0 = tanpa batas
+}
+
+// FakeCall mencatat satu panggilan Generate
+type FakeCall struct {
+	Stage       string
+	Prompt      string
+	Temperature float64
+	Schema      *Schema // nil untuk Generate biasa
+	Response    string
+	Err         error
+}
+
+// FakeProvider adalah Provider in-process yang deterministik untuk test
+// dan pengembangan offline. Tidak ada akses jaringan sama sekali.
+type FakeProvider struct {
+	mu        sync.Mutex
+	rules     []*FakeRule
+	calls     []FakeCall
+	latency   time.Duration
+	failNext  int
+	failErr   error
+	failEvery int
+}
+
+// NewFakeProvider membuat FakeProvider dengan jawaban default yang valid
+// untuk setiap stage, sehingga pipeline bisa berjalan end-to-end
+func NewFakeProvider() *FakeProvider {
+	f := &FakeProvider{}
+	f.rules = append(f.rules,
+		&FakeRule{Stage: StageCVEvaluation, Response: `{"scores": {` +
+			`"technical_skills": {"score": 4, "justification": "Go, MySQL and REST APIs in production."}, ` +
+			`"experience_level": {"score": 3, "justification": "Around three years of backend work."}, ` +
+			`"relevant_achievements": {"score": 3, "justification": "Some quantified performance improvements."}, ` +
+			`"cultural_fit": {"score": 4, "justification": "Mentions mentoring and cross-team projects."}}, ` +
+			`"feedback": "Solid backend experience with Go and REST APIs. Limited exposure to AI/LLM integration."}`},
+		&FakeRule{Stage: StageProjectEvaluation, Response: `{"scores": {` +
+			`"correctness": {"score": 4, "justification": "Prompt chaining and RAG retrieval work as specified."}, ` +
+			`"code_quality": {"score": 4, "justification": "Clear layering between handlers, services and worker."}, ` +
+			`"resilience": {"score": 3, "justification": "Retries exist but failures are not classified."}, ` +
+			`"documentation": {"score": 4, "justification": "README covers setup and trade-offs."}, ` +
+			`"creativity": {"score": 3, "justification": "A few useful extras."}}, ` +
+			`"feedback": "Meets the core requirements with clean structure. Error handling could be more thorough."}`},
+		&FakeRule{Stage: StageSummary, Response: "The candidate shows strong backend fundamentals and delivered a working project. Main gap is production AI experience. Recommendation: hire."},
+		// nilai sama untuk semua passage: reranking mempertahankan urutan hasil fusion
+		&FakeRule{Stage: StageRerank, Respond: fakeRerankResponse},
+	)
+	return f
+}
+
+// fakeRerankResponse memberi setiap passage di prompt rerank nilai 5, sehingga
+// jawabannya lolos schema yang mewajibkan nilai untuk semua passage
+func fakeRerankResponse(prompt string) string {
+	var scores []string
+	for _, match := range fakeRerankPassage.FindAllStringSubmatch(prompt, -1) {
+		scores = append(scores, fmt.Sprintf("%q: 5", match[1]))
+	}
+	return `{"scores": {` + strings.Join(scores, ", ") + `}}`
+}
+
+// OnStage mendaftarkan jawaban untuk stage tertentu. Rule terbaru menang.
+func (f *FakeProvider) OnStage(stage, response string) *FakeProvider {
+	return f.AddRule(FakeRule{Stage: stage, Response: response})
+}
+
+// OnPattern mendaftarkan jawaban untuk prompt yang cocok dengan regex
+func (f *FakeProvider) OnPattern(pattern, response string) *FakeProvider {
+	return f.AddRule(FakeRule{Pattern: regexp.MustCompile(pattern), Response: response})
+}
+
+// AddRule mendaftarkan rule lengkap (misalnya dengan Err atau Times)
+func (f *FakeProvider) AddRule(rule FakeRule) *FakeProvider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rules = append([]*FakeRule{&rule}, f.rules...)
+	return f
+}
+
+// WithLatency mengatur delay untuk setiap panggilan
+func (f *FakeProvider) WithLatency(d time.Duration) *FakeProvider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+	return f
+}
+
+// FailNext membuat n panggilan berikutnya gagal dengan err (nil = ErrFakeInjected)
+func (f *FakeProvider) FailNext(n int, err error) *FakeProvider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNext = n
+	f.failErr = err
+	return f
+}
+
+// FailEvery membuat setiap panggilan ke-n gagal dengan ErrFakeInjected (0 = nonaktif)
+func (f *FakeProvider) FailEvery(n int) *FakeProvider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failEvery = n
+	return f
+}
+
+// Calls mengembalikan salinan semua panggilan yang tercatat
+func (f *FakeProvider) Calls() []FakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := make([]FakeCall, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+// Generate mengembalikan jawaban dari rule pertama yang cocok
+func (f *FakeProvider) Generate(ctx context.Context, prompt string, temperature float64) (string, error) {
+	return f.generate(ctx, prompt, temperature, nil)
+}
+
+// GenerateJSON sama dengan Generate; schema hanya dicatat, tidak ditegakkan,
+// sehingga test bisa menyuntikkan jawaban yang tidak valid
+func (f *FakeProvider) GenerateJSON(ctx context.Context, prompt string, temperature float64, schema *Schema) (string, error) {
+	return f.generate(ctx, prompt, temperature, schema)
+}
+
+func (f *FakeProvider) generate(ctx context.Context, prompt string, temperature float64, schema *Schema) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("fake provider: %w", err)
+	}
+
+	f.mu.Lock()
+	stage := StageFromContext(ctx)
+	if stage == "" {
+		stage = detectFakeStage(prompt)
+	}
+	call := FakeCall{Stage: stage, Prompt: prompt, Temperature: temperature, Schema: schema}
+	latency := f.latency
+
+	switch {
+	case f.failNext > 0:
+		f.failNext--
+		call.Err = f.failErr
+		if call.Err == nil {
+			call.Err = ErrFakeInjected
+		}
+	case f.failEvery > 0 && (len(f.calls)+1)%f.failEvery == 0:
+		call.Err = ErrFakeInjected
+	default:
+		rule := f.match(stage, prompt)
+		if rule == nil {
+			call.Err = fmt.Errorf("fake provider: no rule matches prompt (stage %q)", stage)
+			break
+		}
+		if rule.Times > 0 {
+			rule.Times--
+			if rule.Times == 0 {
+				f.removeRule(rule)
+			}
+		}
+		if rule.Latency > 0 {
+			latency = rule.Latency
+		}
+		call.Response = rule.Response
+		if rule.Respond != nil {
+			call.Response = rule.Respond(prompt)
+		}
+		call.Err = rule.Err
+	}
+
+	f.calls = append(f.calls, call)
+	f.mu.Unlock()
+
+	if latency > 0 {
+		if err := sleepContext(ctx, latency); err != nil {
+			return "", fmt.Errorf("fake provider: %w", err)
+		}
+	}
+
+	return call.Response, call.Err
+}
+
+// match mencari rule yang cocok; harus dipanggil dengan mu terkunci
+func (f *FakeProvider) match(stage, prompt string) *FakeRule {
+	for _, rule := range f.rules {
+		if rule.Stage != "" && rule.Stage != stage {
+			continue
+		}
+		if rule.Pattern != nil && !rule.Pattern.MatchString(prompt) {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
+// removeRule menghapus rule yang sudah habis; harus dipanggil dengan mu terkunci
+func (f *FakeProvider) removeRule(target *FakeRule) {
+	for i, rule := range f.rules {
+		if rule == target {
+			f.rules = append(f.rules[:i], f.rules[i+1:]...)
+			return
+		}
+	}
+}
+
+// detectFakeStage menebak stage pipeline dari isi prompt
+func detectFakeStage(prompt string) string {
+	for stage, pattern := range fakeStagePatterns {
+		if pattern.MatchString(prompt) {
+			return stage
+		}
+	}
+	return ""
+}
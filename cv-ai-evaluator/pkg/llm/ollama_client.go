@@ -2,6 +2,7 @@ package llm
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -20,6 +21,8 @@ type OllamaRequest struct {
     Prompt string `json:"prompt"`
     Stream bool   `json:"stream"`
     Options map[string]interface{} `json:"options,omitempty"`
+    // Format berisi JSON schema untuk structured output (Ollama >= 0.5)
+    Format *Schema `json:"format,omitempty"`
 }
 
 type OllamaResponse struct {
@@ -39,51 +42,71 @@ func NewOllamaClient(baseURL, model string) *OllamaClient {
     }
 }
 
-// Generate mengirim prompt ke Ollama dan mengembalikan response
-func (o *OllamaClient) Generate(prompt string, temperature float64) (string, error) {
-    reqBody := OllamaRequest{
+// Generate mengirim prompt ke Ollama dan mengembalikan response.
+// Request dan jeda retry dibatalkan saat ctx selesai.
+func (o *OllamaClient) Generate(ctx context.Context, prompt string, temperature float64) (string, error) {
+    return o.send(ctx, OllamaRequest{
         Model:  o.Model,
         Prompt: prompt,
         Stream: false,
         Options: map[string]interface{}{
             "temperature": temperature,
         },
-    }
+    })
+}
 
+// GenerateJSON mengirim prompt dengan JSON schema di field format
+func (o *OllamaClient) GenerateJSON(ctx context.Context, prompt string, temperature float64, schema *Schema) (string, error) {
+    return o.send(ctx, OllamaRequest{
+        Model:  o.Model,
+        Prompt: prompt,
+        Stream: false,
+        Options: map[string]interface{}{
+            "temperature": temperature,
+        },
+        Format: schema,
+    })
+}
+
+// send melakukan request ke /api/generate dengan retry
+func (o *OllamaClient) send(ctx context.Context, reqBody OllamaRequest) (string, error) {
     jsonData, err := json.Marshal(reqBody)
     if err != nil {
         return "", fmt.Errorf("failed to marshal request: %w", err)
     }
 
     url := fmt.Sprintf("%s/api/generate", o.BaseURL)
-    
+
     // Retry logic untuk handle timeout
-    maxRetries := 3
-    var lastErr error
+    return withRetry(ctx, defaultMaxRetries, func(ctx context.Context) (string, bool, error) {
+        return o.doRequest(ctx, url, jsonData)
+    })
+}
 
-    for i := 0; i < maxRetries; i++ {
-        resp, err := o.Client.Post(url, "application/json", bytes.NewBuffer(jsonData))
-        if err != nil {
-            lastErr = fmt.Errorf("request failed (attempt %d/%d): %w", i+1, maxRetries, err)
-            time.Sleep(time.Duration(i+1) * 2 * time.Second) // Exponential backoff
-            continue
-        }
-        defer resp.Body.Close()
+// doRequest melakukan satu request HTTP; retryable menandakan error boleh diulang
+func (o *OllamaClient) doRequest(ctx context.Context, url string, body []byte) (string, bool, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+    if err != nil {
+        return "", false, fmt.Errorf("failed to build request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
 
-        if resp.StatusCode != http.StatusOK {
-            bodyBytes, _ := io.ReadAll(resp.Body)
-            lastErr = fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, string(bodyBytes))
-            time.Sleep(time.Duration(i+1) * 2 * time.Second)
-            continue
-        }
+    resp, err := o.Client.Do(req)
+    if err != nil {
+        return "", true, fmt.Errorf("request failed: %w", err)
+    }
+    defer resp.Body.Close()
 
-        var ollamaResp OllamaResponse
-        if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-            return "", fmt.Errorf("failed to decode response: %w", err)
-        }
+    if resp.StatusCode != http.StatusOK {
+        bodyBytes, _ := io.ReadAll(resp.Body)
+        statusErr := &StatusError{Provider: "ollama", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+        return "", statusErr.Retryable(), statusErr
+    }
 
-        return ollamaResp.Response, nil
+    var ollamaResp OllamaResponse
+    if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
+        return "", false, fmt.Errorf("failed to decode response: %w", err)
     }
 
-    return "", fmt.Errorf("all retry attempts failed: %w", lastErr)
+    return ollamaResp.Response, false, nil
 }
@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIClient berbicara dengan server yang kompatibel dengan OpenAI
+// chat-completions API (OpenAI, llama.cpp server, vLLM, dll)
+type OpenAIClient struct {
+	BaseURL string
+	Model   string
+	APIKey  string
+	Client  *http.Client
+}
+
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ChatCompletionRequest struct {
+	Model          string          `json:"model"`
+	Messages       []ChatMessage   `json:"messages"`
+	Temperature    float64         `json:"temperature"`
+	Stream         bool            `json:"stream"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat meminta structured output dengan JSON schema
+type ResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *ResponseSchema `json:"json_schema,omitempty"`
+}
+
+type ResponseSchema struct {
+	Name   string  `json:"name"`
+	Schema *Schema `json:"schema"`
+	Strict bool    `json:"strict"`
+}
+
+type ChatCompletionResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int         `json:"index"`
+		Message      ChatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// NewOpenAIClient membuat client baru. baseURL sudah termasuk prefix versi,
+// contoh: http://localhost:8001/v1
+func NewOpenAIClient(baseURL, model, apiKey string) *OpenAIClient {
+	return &OpenAIClient{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Model:   model,
+		APIKey:  apiKey,
+		Client: &http.Client{
+			Timeout: 300 * time.Second, // 5 menit timeout untuk LLM
+		},
+	}
+}
+
+// Generate mengirim prompt sebagai satu pesan user ke endpoint chat/completions
+func (o *OpenAIClient) Generate(ctx context.Context, prompt string, temperature float64) (string, error) {
+	return o.send(ctx, ChatCompletionRequest{
+		Model: o.Model,
+		Messages: []ChatMessage{
+			{Role: "user", Content: prompt},
+		},
+		Temperature: temperature,
+		Stream:      false,
+	})
+}
+
+// GenerateJSON mengirim prompt dengan response_format bertipe json_schema.
+// Schema dikirim dalam strict mode bila memenuhi aturannya; kalau tidak,
+// API akan menolak dengan 400 (permanen), jadi dikirim non-strict dan
+// jawabannya tetap divalidasi caller.
+func (o *OpenAIClient) GenerateJSON(ctx context.Context, prompt string, temperature float64, schema *Schema) (string, error) {
+	strict := schema.Strict()
+	return o.send(ctx, ChatCompletionRequest{
+		Model: o.Model,
+		Messages: []ChatMessage{
+			{Role: "user", Content: prompt},
+		},
+		Temperature: temperature,
+		Stream:      false,
+		ResponseFormat: &ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &ResponseSchema{
+				Name:   "evaluation",
+				Schema: strict,
+				Strict: len(strict.StrictViolations()) == 0,
+			},
+		},
+	})
+}
+
+// send melakukan request ke chat/completions dengan retry
+func (o *OpenAIClient) send(ctx context.Context, reqBody ChatCompletionRequest) (string, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/chat/completions", o.BaseURL)
+
+	// Retry logic untuk handle timeout
+	return withRetry(ctx, defaultMaxRetries, func(ctx context.Context) (string, bool, error) {
+		return o.doRequest(ctx, url, jsonData)
+	})
+}
+
+// doRequest melakukan satu request HTTP; retryable menandakan error boleh diulang
+func (o *OpenAIClient) doRequest(ctx context.Context, url string, body []byte) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.APIKey)
+	}
+
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return "", true, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		statusErr := &StatusError{Provider: "openai-compatible server", StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+		return "", statusErr.Retryable(), statusErr
+	}
+
+	var chatResp ChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return "", false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return "", false, fmt.Errorf("response contains no choices")
+	}
+
+	return chatResp.Choices[0].Message.Content, false, nil
+}
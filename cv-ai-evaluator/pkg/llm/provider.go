@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Nama provider yang didukung (dipilih lewat LLM_PROVIDER)
+const (
+	ProviderOllama = "ollama"
+	ProviderOpenAI = "openai"
+	ProviderFake   = "fake"
+)
+
+// Provider adalah abstraksi backend LLM yang dipakai oleh worker.
+// Implementasi harus aman dipanggil dari banyak goroutine sekaligus.
+// Pembatalan ctx (shutdown, deadline job) harus menghentikan request yang
+// sedang berjalan beserta jeda retry-nya.
+type Provider interface {
+	// Generate mengirim prompt dan mengembalikan teks jawaban model
+	Generate(ctx context.Context, prompt string, temperature float64) (string, error)
+
+	// GenerateJSON meminta model menjawab JSON yang dibatasi oleh schema.
+	// Hasilnya tetap harus divalidasi oleh caller dengan Schema.Validate.
+	GenerateJSON(ctx context.Context, prompt string, temperature float64, schema *Schema) (string, error)
+}
+
+// Stage pipeline evaluasi, dipasang di context oleh worker untuk logging
+// dan agar FakeProvider bisa memilih jawaban per stage
+const (
+	StageCVEvaluation      = "cv_evaluation"
+	StageProjectEvaluation = "project_evaluation"
+	StageSummary           = "summary"
+	StageRerank            = "rerank"
+)
+
+type stageContextKey struct{}
+
+// WithStage menandai ctx dengan stage pipeline yang sedang berjalan
+func WithStage(ctx context.Context, stage string) context.Context {
+	return context.WithValue(ctx, stageContextKey{}, stage)
+}
+
+// StageFromContext mengembalikan stage yang dipasang WithStage (atau "")
+func StageFromContext(ctx context.Context) string {
+	stage, _ := ctx.Value(stageContextKey{}).(string)
+	return stage
+}
+
+// Pastikan semua client memenuhi interface Provider
+var (
+	_ Provider = (*OllamaClient)(nil)
+	_ Provider = (*OpenAIClient)(nil)
+	_ Provider = (*FakeProvider)(nil)
+)
+
+// ProviderConfig berisi parameter untuk membuat Provider
+type ProviderConfig struct {
+	Provider string
+	BaseURL  string
+	Model    string
+	APIKey   string
+	Timeout  time.Duration
+}
+
+// NewProvider membuat Provider sesuai nama provider di config
+func NewProvider(cfg ProviderConfig) (Provider, error) {
+	switch cfg.Provider {
+	case ProviderOllama, "":
+		client := NewOllamaClient(cfg.BaseURL, cfg.Model)
+		if cfg.Timeout > 0 {
+			client.Client.Timeout = cfg.Timeout
+		}
+		return client, nil
+	case ProviderOpenAI:
+		client := NewOpenAIClient(cfg.BaseURL, cfg.Model, cfg.APIKey)
+		if cfg.Timeout > 0 {
+			client.Client.Timeout = cfg.Timeout
+		}
+		return client, nil
+	case ProviderFake:
+		return NewFakeProvider(), nil
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider: %q", cfg.Provider)
+	}
+}
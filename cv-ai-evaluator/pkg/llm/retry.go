@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultMaxRetries adalah jumlah percobaan request ke server LLM
+const defaultMaxRetries = 3
+
+// attemptFunc menjalankan satu request; retryable menandakan error boleh diulang
+type attemptFunc func(ctx context.Context) (result string, retryable bool, err error)
+
+// withRetry menjalankan attempt dengan backoff. Pembatalan ctx menghentikan
+// request yang sedang berjalan maupun jeda backoff, dan error-nya tetap
+// membungkus ctx.Err() sehingga caller bisa memakai errors.Is.
+func withRetry(ctx context.Context, maxRetries int, attempt attemptFunc) (string, error) {
+	var lastErr error
+
+	for i := 0; i < maxRetries; i++ {
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("LLM request aborted: %w", err)
+		}
+
+		result, retryable, err := attempt(ctx)
+		if err == nil {
+			return result, nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("LLM request aborted: %w (last error: %v)", ctxErr, err)
+		}
+		if !retryable {
+			return "", err
+		}
+
+		lastErr = fmt.Errorf("attempt %d/%d: %w", i+1, maxRetries, err)
+		if i == maxRetries-1 {
+			break
+		}
+
+		// Backoff yang bisa dibatalkan
+		backoff := time.Duration(i+1) * 2 * time.Second
+		if err := sleepContext(ctx, backoff); err != nil {
+			return "", fmt.Errorf("LLM request aborted during backoff: %w (last error: %v)", err, lastErr)
+		}
+	}
+
+	return "", fmt.Errorf("all retry attempts failed: %w", lastErr)
+}
+
+// sleepContext menunggu d atau sampai ctx dibatalkan
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema adalah subset JSON Schema yang cukup untuk structured output.
+// Di-marshal apa adanya ke field "format" Ollama; OpenAI menerima versi
+// Strict() karena strict mode menolak minimum/maximum/minLength.
+type Schema struct {
+	Type                 string             `json:"type"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+}
+
+// Validate memeriksa nilai hasil json.Unmarshal (ke interface{}) terhadap schema
+// dan mengembalikan daftar pelanggaran yang bisa dibaca manusia (dan LLM)
+func (s *Schema) Validate(value interface{}) []string {
+	var violations []string
+	s.validate("$", value, &violations)
+	return violations
+}
+
+func (s *Schema) validate(path string, value interface{}, violations *[]string) {
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			*violations = append(*violations, fmt.Sprintf("%s: expected object, got %s", path, jsonTypeName(value)))
+			return
+		}
+		for _, key := range s.Required {
+			if _, ok := obj[key]; !ok {
+				*violations = append(*violations, fmt.Sprintf("%s: missing required field %q", path, key))
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			prop, ok := s.Properties[key]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*violations = append(*violations, fmt.Sprintf("%s: unexpected field %q", path, key))
+				}
+				continue
+			}
+			prop.validate(path+"."+key, obj[key], violations)
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			*violations = append(*violations, fmt.Sprintf("%s: expected string, got %s", path, jsonTypeName(value)))
+			return
+		}
+		if s.MinLength != nil && len(strings.TrimSpace(str)) < *s.MinLength {
+			*violations = append(*violations, fmt.Sprintf("%s: must be at least %d characters", path, *s.MinLength))
+		}
+
+	case "number", "integer":
+		num, ok := value.(float64)
+		if !ok {
+			*violations = append(*violations, fmt.Sprintf("%s: expected %s, got %s", path, s.Type, jsonTypeName(value)))
+			return
+		}
+		if s.Type == "integer" && num != float64(int64(num)) {
+			*violations = append(*violations, fmt.Sprintf("%s: expected integer, got %v", path, num))
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			*violations = append(*violations, fmt.Sprintf("%s: %v is below minimum %v", path, num, *s.Minimum))
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			*violations = append(*violations, fmt.Sprintf("%s: %v is above maximum %v", path, num, *s.Maximum))
+		}
+	}
+}
+
+// Strict mengembalikan salinan schema tanpa keyword yang ditolak strict mode
+// OpenAI (minimum, maximum, minLength). Batasan itu tetap dicek lewat
+// Validate pada schema asli setelah jawaban diterima.
+func (s *Schema) Strict() *Schema {
+	strict := &Schema{
+		Type:                 s.Type,
+		Description:          s.Description,
+		Required:             s.Required,
+		AdditionalProperties: s.AdditionalProperties,
+	}
+	if s.Properties != nil {
+		strict.Properties = make(map[string]*Schema, len(s.Properties))
+		for key, prop := range s.Properties {
+			strict.Properties[key] = prop.Strict()
+		}
+	}
+	return strict
+}
+
+// StrictViolations memeriksa aturan strict mode OpenAI: setiap object wajib
+// additionalProperties false, setiap property wajib ada di required, dan
+// keyword minimum/maximum/minLength tidak boleh dipakai
+func (s *Schema) StrictViolations() []string {
+	var violations []string
+	s.strictViolations("$", &violations)
+	return violations
+}
+
+func (s *Schema) strictViolations(path string, violations *[]string) {
+	if s.Minimum != nil || s.Maximum != nil || s.MinLength != nil {
+		*violations = append(*violations, fmt.Sprintf("%s: minimum, maximum and minLength are not supported", path))
+	}
+	if s.Type != "object" {
+		return
+	}
+	if s.AdditionalProperties == nil || *s.AdditionalProperties {
+		*violations = append(*violations, fmt.Sprintf("%s: object must set additionalProperties to false", path))
+	}
+
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		found := false
+		for _, required := range s.Required {
+			if required == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*violations = append(*violations, fmt.Sprintf("%s: property %q must be listed in required", path, key))
+		}
+		s.Properties[key].strictViolations(path+"."+key, violations)
+	}
+}
+
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
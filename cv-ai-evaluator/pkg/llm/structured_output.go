@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GenerateStructured meminta jawaban JSON sesuai schema, memvalidasinya, lalu
+// men-decode-nya ke out. Jika tidak valid, prompt "repair" berisi error
+// validasi dikirim ulang, maksimal maxRepairs kali.
+func GenerateStructured(ctx context.Context, provider Provider, prompt string, temperature float64, schema *Schema, maxRepairs int, out interface{}) error {
+	currentPrompt := prompt
+
+	for attempt := 0; ; attempt++ {
+		response, err := provider.GenerateJSON(ctx, currentPrompt, temperature, schema)
+		if err != nil {
+			return fmt.Errorf("LLM call failed: %w", err)
+		}
+
+		violations := decodeAndValidate(response, schema, out)
+		if len(violations) == 0 {
+			return nil
+		}
+
+		if attempt >= maxRepairs {
+			return fmt.Errorf("structured output invalid after %d repair attempt(s): %s",
+				attempt, strings.Join(violations, "; "))
+		}
+
+		currentPrompt = buildRepairPrompt(prompt, response, violations)
+	}
+}
+
+// decodeAndValidate mem-parse response sebagai JSON, memvalidasi terhadap
+// schema, lalu men-decode ke out. Mengembalikan daftar pelanggaran.
+func decodeAndValidate(response string, schema *Schema, out interface{}) []string {
+	raw := []byte(stripCodeFence(response))
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return []string{fmt.Sprintf("response is not valid JSON: %v", err)}
+	}
+
+	if violations := schema.Validate(generic); len(violations) > 0 {
+		return violations
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		return []string{fmt.Sprintf("response does not match expected structure: %v", err)}
+	}
+
+	return nil
+}
+
+// stripCodeFence membuang ```json ... ``` yang kadang ditambahkan model
+func stripCodeFence(response string) string {
+	trimmed := strings.TrimSpace(response)
+	if !strings.HasPrefix(trimmed, "```") {
+		return trimmed
+	}
+	trimmed = strings.TrimPrefix(trimmed, "```")
+	trimmed = strings.TrimPrefix(trimmed, "json")
+	trimmed = strings.TrimSuffix(trimmed, "```")
+	return strings.TrimSpace(trimmed)
+}
+
+// buildRepairPrompt menyusun prompt ulang berisi jawaban sebelumnya dan error validasinya
+func buildRepairPrompt(originalPrompt, previousResponse string, violations []string) string {
+	return fmt.Sprintf(`%s
+
+Your previous response was:
+%s
+
+It failed validation with the following errors:
+- %s
+
+Respond again with ONLY the corrected JSON object. Every required field must be present and every score must be within the allowed range.`,
+		originalPrompt, previousResponse, strings.Join(violations, "\n- "))
+}
@@ -19,6 +19,28 @@ func NewDocumentReader() *DocumentReader {
 
 // ReadDocument membaca dokumen berdasarkan extension
 func (d *DocumentReader) ReadDocument(filePath string) (string, error) {
+	rawText, err := d.readRaw(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	// BUG FIX: Panggil CleanText SEBELUM mengembalikan
+	return d.CleanText(rawText), nil
+}
+
+// ReadRawDocument membaca dokumen tanpa CleanText: baris, paragraf, dan
+// heading markdown dipertahankan untuk dokumen yang dipotong per section
+func (d *DocumentReader) ReadRawDocument(filePath string) (string, error) {
+	rawText, err := d.readRaw(filePath)
+	if err != nil {
+		return "", err
+	}
+	rawText = strings.ReplaceAll(rawText, "\r\n", "\n")
+	rawText = strings.ReplaceAll(rawText, "\r", "\n")
+	return strings.TrimSpace(rawText), nil
+}
+
+func (d *DocumentReader) readRaw(filePath string) (string, error) {
 	ext := strings.ToLower(filepath.Ext(filePath))
 	var rawText string
 	var err error
@@ -35,9 +57,7 @@ func (d *DocumentReader) ReadDocument(filePath string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-
-	// BUG FIX: Panggil CleanText SEBELUM mengembalikan
-	return d.CleanText(rawText), nil
+	return rawText, nil
 }
 
 // readTextFile membaca file text/markdown
@@ -0,0 +1,167 @@
+package vectordb
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Parameter Okapi BM25 yang umum dipakai
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// KeywordIndex adalah index BM25 atas chunk ground truth. Melengkapi embedding
+// all-minilm yang lemah untuk istilah spesifik (nama teknologi, kode
+// parameter rubric) yang jarang muncul di data latihnya.
+type KeywordIndex struct {
+	mu       sync.RWMutex
+	chunks   map[string]keywordChunk
+	df       map[string]int // jumlah chunk yang memuat term
+	totalLen int
+}
+
+type keywordChunk struct {
+	documentID string
+	terms      map[string]int
+	length     int
+}
+
+// NewKeywordIndex membuat index kosong
+func NewKeywordIndex() *KeywordIndex {
+	return &KeywordIndex{
+		chunks: make(map[string]keywordChunk),
+		df:     make(map[string]int),
+	}
+}
+
+// Add meng-index teks chunk id milik documentID, menggantikan isi lama chunk
+// dengan id yang sama
+func (x *KeywordIndex) Add(id, documentID, text string) {
+	terms := make(map[string]int)
+	length := 0
+	for _, term := range Tokenize(text) {
+		terms[term]++
+		length++
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(id)
+	x.chunks[id] = keywordChunk{documentID: documentID, terms: terms, length: length}
+	for term := range terms {
+		x.df[term]++
+	}
+	x.totalLen += length
+}
+
+// RemoveDocument menghapus semua chunk dokumen
+func (x *KeywordIndex) RemoveDocument(documentID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for id, chunk := range x.chunks {
+		if chunk.documentID == documentID {
+			x.remove(id)
+		}
+	}
+}
+
+// remove harus dipanggil dengan mu terkunci
+func (x *KeywordIndex) remove(id string) {
+	chunk, ok := x.chunks[id]
+	if !ok {
+		return
+	}
+	for term := range chunk.terms {
+		if x.df[term]--; x.df[term] <= 0 {
+			delete(x.df, term)
+		}
+	}
+	x.totalLen -= chunk.length
+	delete(x.chunks, id)
+}
+
+// Len mengembalikan jumlah chunk di index
+func (x *KeywordIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.chunks)
+}
+
+// Score menghitung skor BM25 query untuk chunk ids. IDF dihitung atas seluruh
+// index, bukan hanya ids. Chunk yang tidak memuat satu pun term query tidak
+// ada di hasil.
+func (x *KeywordIndex) Score(query string, ids []string) map[string]float32 {
+	terms := uniqueTerms(Tokenize(query))
+	scores := make(map[string]float32)
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if len(x.chunks) == 0 || len(terms) == 0 {
+		return scores
+	}
+
+	n := float64(len(x.chunks))
+	avgLen := float64(x.totalLen) / n
+	if avgLen == 0 {
+		avgLen = 1
+	}
+	for _, id := range ids {
+		chunk, ok := x.chunks[id]
+		if !ok {
+			continue
+		}
+		score := 0.0
+		for _, term := range terms {
+			tf := float64(chunk.terms[term])
+			if tf == 0 {
+				continue
+			}
+			df := float64(x.df[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(chunk.length)/avgLen))
+		}
+		if score > 0 {
+			scores[id] = float32(score)
+		}
+	}
+	return scores
+}
+
+// stopWords adalah kata umum bahasa Inggris yang tidak membedakan chunk
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "was": true,
+	"were": true, "will": true, "with": true,
+}
+
+// Tokenize memecah teks menjadi term BM25: huruf kecil, dipisah di karakter
+// selain huruf dan angka, tanpa stop word
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := fields[:0]
+	for _, field := range fields {
+		if !stopWords[field] {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
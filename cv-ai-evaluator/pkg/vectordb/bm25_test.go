@@ -0,0 +1,97 @@
+package vectordb
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Golang and PostgreSQL", []string{"golang", "postgresql"}},
+		{"REST/gRPC APIs, e.g. v2", []string{"rest", "grpc", "apis", "e", "g", "v2"}},
+		{"the of and", nil},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestKeywordIndexScore(t *testing.T) {
+	index := NewKeywordIndex()
+	index.Add("go", "doc-1", "Golang services and Golang tooling")
+	index.Add("sql", "doc-1", "PostgreSQL schemas and backend services")
+	index.Add("cloud", "doc-2", "Cloud deployment of backend services")
+	all := []string{"go", "sql", "cloud"}
+
+	tests := []struct {
+		name  string
+		query string
+		ids   []string
+		want  []string // ids with a score, best first
+	}{
+		{"rare term outweighs a term in every chunk", "golang services", all, []string{"go", "sql", "cloud"}},
+		{"chunks without a query term are left out", "postgresql", all, []string{"sql"}},
+		{"only the requested ids are scored", "services", []string{"cloud"}, []string{"cloud"}},
+		{"stop words alone score nothing", "the and of", all, nil},
+		{"unknown term scores nothing", "kubernetes", all, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores := index.Score(tt.query, tt.ids)
+			got := make([]string, 0, len(scores))
+			for id := range scores {
+				got = append(got, id)
+			}
+			slices.SortFunc(got, func(a, b string) int {
+				if scores[a] != scores[b] {
+					if scores[a] > scores[b] {
+						return -1
+					}
+					return 1
+				}
+				return slices.Index(all, a) - slices.Index(all, b)
+			})
+			if len(got) == 0 {
+				got = nil
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Score(%q) ranked %q (%v), want %q", tt.query, got, scores, tt.want)
+			}
+		})
+	}
+}
+
+// TestKeywordIndexReplace checks that re-adding a chunk replaces its terms and
+// that removing a document drops its chunks from the scores
+func TestKeywordIndexReplace(t *testing.T) {
+	index := NewKeywordIndex()
+	index.Add("a", "doc-1", "Golang")
+	index.Add("b", "doc-2", "Python")
+	index.Add("a", "doc-1", "Rust")
+
+	if index.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", index.Len())
+	}
+	if scores := index.Score("golang", []string{"a", "b"}); len(scores) != 0 {
+		t.Errorf("replaced chunk still matches its old text: %v", scores)
+	}
+	if scores := index.Score("rust", []string{"a"}); scores["a"] <= 0 {
+		t.Errorf("replaced chunk does not match its new text: %v", scores)
+	}
+
+	index.RemoveDocument("doc-1")
+	if index.Len() != 1 {
+		t.Fatalf("Len() after RemoveDocument = %d, want 1", index.Len())
+	}
+	if scores := index.Score("rust", []string{"a"}); len(scores) != 0 {
+		t.Errorf("removed document still scores: %v", scores)
+	}
+}
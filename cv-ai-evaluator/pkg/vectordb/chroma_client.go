@@ -4,19 +4,56 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"sync"
 
 	chromem "github.com/philippgille/chromem-go"
 )
 
+// DefaultTenant memakai collection lama "cv_evaluator" sehingga ground truth
+// yang di-ingest sebelum multi-tenant tetap terbaca. Tenant lain mendapat
+// collection sendiri, jadi retrieval tidak mungkin tercampur antar tenant.
+const (
+	DefaultTenant     = "default"
+	defaultCollection = "cv_evaluator"
+)
+
+// Metadata yang ditambahkan ke setiap chunk
+const (
+	metadataDocumentID = "document_id" // ID dokumen induk
+	metadataChunk      = "chunk"       // urutan chunk di dokumen
+	metadataHeading    = "heading"     // jalur heading section
+	metadataOverlap    = "overlap"     // lihat Chunk.Overlap
+	metadataContinued  = "continued"
+)
+
 type ChromaClient struct {
-	DB         *chromem.DB
+	// DB dan Collection diganti oleh Reload; dbMu melindungi keduanya
+	dbMu sync.RWMutex
+	DB   *chromem.DB
+	// Collection milik DefaultTenant
 	Collection *chromem.Collection
+
+	persistPath   string
+	embeddingFunc chromem.EmbeddingFunc
+	chunking      ChunkOptions
+
+	// index BM25 per collection, dibangun saat pertama kali dipakai. Key-nya
+	// pointer collection, jadi index collection sebelum Reload tidak terpakai lagi.
+	keywordMu sync.Mutex
+	keyword   map[*chromem.Collection]*KeywordIndex
 }
 
-func NewChromaClient(persistPath string) (*ChromaClient, error) {
-	// Buat embedded ChromaDB
-	db := chromem.NewDB()
+// EmbeddingFunc mengubah teks menjadi vektor embedding
+type EmbeddingFunc = chromem.EmbeddingFunc
 
+// NewChromaClient membuka (atau membuat) vector DB persisten di persistPath.
+// Dokumen yang di-ingest oleh satu proses (misalnya perintah ingest) baru
+// terbaca oleh proses lain (API server) yang membuka path yang sama setelah
+// proses itu memanggil Reload atau di-restart. Dokumen dipotong menurut
+// chunking sebelum di-embed.
+func NewChromaClient(persistPath, ollamaURL string, chunking ChunkOptions) (*ChromaClient, error) {
 	// CRITICAL FIX: Gunakan model yang ADA di Ollama
 	// Opsi 1: all-minilm (paling ringan, 23MB, built-in di chromem-go)
 	// Opsi 2: mxbai-embed-large (harus pull dulu: ollama pull mxbai-embed-large)
@@ -27,47 +64,207 @@ func NewChromaClient(persistPath string) (*ChromaClient, error) {
 	// Atau bisa pakai NewEmbeddingFuncDefault() untuk testing
 	embeddingFunc := chromem.NewEmbeddingFuncOllama(
 		"all-minilm",                 // Model yang lebih universal
-		"http://localhost:11434",     // Base URL Ollama
+		ollamaURL+"/api",             // Base URL Ollama
 	)
 
 	log.Println("✅ Embedding function created successfully")
 
+	return NewChromaClientWithEmbedding(persistPath, embeddingFunc, chunking)
+}
+
+// NewChromaClientWithEmbedding sama dengan NewChromaClient tetapi memakai
+// embeddingFunc, misalnya embedding deterministik untuk test tanpa Ollama.
+// Semua dokumen di persistPath harus di-embed dengan fungsi (dan dimensi) yang sama.
+func NewChromaClientWithEmbedding(persistPath string, embeddingFunc EmbeddingFunc, chunking ChunkOptions) (*ChromaClient, error) {
+	client := &ChromaClient{
+		persistPath:   persistPath,
+		embeddingFunc: embeddingFunc,
+		chunking:      chunking.withDefaults(),
+	}
+	if err := client.Reload(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ ChromaDB collection '%s' ready (%s, %d documents)", defaultCollection, persistPath, client.Collection.Count())
+
+	return client, nil
+}
+
+// Reload membuka ulang vector DB dari disk dan membuang index BM25, sehingga
+// dokumen yang ditulis proses lain (perintah ingest) ikut terbaca. Query yang
+// sedang berjalan selesai dengan data lama.
+func (c *ChromaClient) Reload() error {
+	// Buat embedded ChromaDB yang disimpan ke disk
+	db, err := chromem.NewPersistentDB(c.persistPath, false)
+	if err != nil {
+		return fmt.Errorf("failed to open persistent vector DB at %s: %w", c.persistPath, err)
+	}
+
 	// Buat atau get collection dengan Ollama embedding
-	collection, err := db.GetOrCreateCollection(
-		"cv_evaluator",
-		map[string]string{"description": "Ground truth documents for CV evaluation"},
-		embeddingFunc,
-	)
+	collection, err := db.GetOrCreateCollection(defaultCollection, collectionMetadata(DefaultTenant), c.embeddingFunc)
+	if err != nil {
+		return fmt.Errorf("failed to create collection %s: %w", defaultCollection, err)
+	}
+
+	c.dbMu.Lock()
+	c.DB = db
+	c.Collection = collection
+	c.dbMu.Unlock()
+
+	c.keywordMu.Lock()
+	c.keyword = make(map[*chromem.Collection]*KeywordIndex)
+	c.keywordMu.Unlock()
+	return nil
+}
+
+func collectionMetadata(tenant string) map[string]string {
+	return map[string]string{"description": "Ground truth documents for CV evaluation", "tenant": tenant}
+}
+
+// collectionName mengembalikan nama collection untuk tenant
+func collectionName(tenant string) string {
+	if tenant == "" || tenant == DefaultTenant {
+		return defaultCollection
+	}
+	return defaultCollection + "_" + tenant
+}
 
+// tenantCollection mengambil collection tenant. Jika create false dan tenant
+// belum punya collection, hasilnya nil tanpa error.
+func (c *ChromaClient) tenantCollection(tenant string, create bool) (*chromem.Collection, error) {
+	c.dbMu.RLock()
+	db := c.DB
+	c.dbMu.RUnlock()
+
+	name := collectionName(tenant)
+	if !create {
+		return db.GetCollection(name, c.embeddingFunc), nil
+	}
+
+	collection, err := db.GetOrCreateCollection(name, collectionMetadata(tenant), c.embeddingFunc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection %s: %w", name, err)
+	}
+	return collection, nil
+}
+
+// AddDocument memotong dokumen per section markdown dan menyimpan setiap chunk
+// ke vector database milik tenant dengan ID "<id>#<urutan>". Metadata dokumen
+// disalin ke setiap chunk. Chunk lama dokumen yang sama diganti.
+func (c *ChromaClient) AddDocument(ctx context.Context, tenant, id, content string, metadata map[string]string) error {
+	collection, err := c.tenantCollection(tenant, true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create collection: %w", err)
+		return err
+	}
+
+	chunks := ChunkMarkdown(content, c.chunking)
+	if len(chunks) == 0 {
+		return fmt.Errorf("failed to add document %s: no content", id)
+	}
+
+	docs := make([]chromem.Document, 0, len(chunks))
+	for _, chunk := range chunks {
+		// heading ikut di-embed agar chunk lanjutan tetap dikenali section-nya
+		embedding, err := c.embeddingFunc(ctx, chunk.Heading+"\n\n"+chunk.Content)
+		if err != nil {
+			return fmt.Errorf("failed to embed chunk %d of document %s: %w", chunk.Index, id, err)
+		}
+
+		chunkMetadata := make(map[string]string, len(metadata)+5)
+		for k, v := range metadata {
+			chunkMetadata[k] = v
+		}
+		chunkMetadata[metadataDocumentID] = id
+		chunkMetadata[metadataChunk] = strconv.Itoa(chunk.Index)
+		chunkMetadata[metadataHeading] = chunk.Heading
+		chunkMetadata[metadataOverlap] = strconv.Itoa(chunk.Overlap)
+		chunkMetadata[metadataContinued] = strconv.FormatBool(chunk.Continued)
+
+		docs = append(docs, chromem.Document{
+			ID:        chunkID(id, chunk.Index),
+			Content:   chunk.Content,
+			Metadata:  chunkMetadata,
+			Embedding: embedding,
+		})
+	}
+
+	// semua embedding sudah jadi sebelum isi lama dihapus
+	if err := c.DeleteDocument(ctx, tenant, id); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err := collection.AddDocument(ctx, doc); err != nil {
+			c.DeleteDocument(ctx, tenant, id)
+			return fmt.Errorf("failed to add document: %w", err)
+		}
 	}
 
-	log.Println("✅ ChromaDB collection 'cv_evaluator' ready")
+	c.keywordMu.Lock()
+	if index := c.keyword[collection]; index != nil {
+		for _, doc := range docs {
+			index.Add(doc.ID, id, keywordText(doc.Metadata, doc.Content))
+		}
+	}
+	c.keywordMu.Unlock()
 
-	return &ChromaClient{
-		DB:         db,
-		Collection: collection,
-	}, nil
+	return nil
 }
 
-// AddDocument menambahkan dokumen ke vector database
-func (c *ChromaClient) AddDocument(ctx context.Context, id, content string, metadata map[string]string) error {
-	err := c.Collection.AddDocument(ctx, chromem.Document{
-		ID:       id,
-		Content:  content,
-		Metadata: metadata,
-	})
+// DeleteDocument menghapus semua chunk dokumen tenant dari vector database,
+// termasuk dokumen utuh yang di-index sebelum chunking. Dokumen yang tidak
+// ada tidak dianggap error.
+func (c *ChromaClient) DeleteDocument(ctx context.Context, tenant, id string) error {
+	collection, err := c.tenantCollection(tenant, false)
 	if err != nil {
-		return fmt.Errorf("failed to add document: %w", err)
+		return err
+	}
+	if collection == nil {
+		return nil
+	}
+
+	if err := collection.Delete(ctx, map[string]string{metadataDocumentID: id}, nil); err != nil {
+		return fmt.Errorf("failed to delete document %s: %w", id, err)
+	}
+	if err := collection.Delete(ctx, nil, nil, id); err != nil {
+		return fmt.Errorf("failed to delete document %s: %w", id, err)
 	}
 
+	c.keywordMu.Lock()
+	if index := c.keyword[collection]; index != nil {
+		index.RemoveDocument(id)
+	}
+	c.keywordMu.Unlock()
 	return nil
 }
 
-// Query mencari dokumen yang relevan
-func (c *ChromaClient) Query(ctx context.Context, queryText string, nResults int, whereFilter map[string]string) ([]chromem.Result, error) {
-	results, err := c.Collection.Query(ctx, queryText, nResults, whereFilter, nil)
+// IsChunked melaporkan apakah dokumen sudah di-index per chunk. Dokumen yang
+// di-index utuh sebelum chunking perlu di-index ulang.
+func (c *ChromaClient) IsChunked(ctx context.Context, tenant, id string) bool {
+	collection, err := c.tenantCollection(tenant, false)
+	if err != nil || collection == nil {
+		return false
+	}
+	_, err = collection.GetByID(ctx, chunkID(id, 0))
+	return err == nil
+}
+
+func chunkID(id string, index int) string {
+	return fmt.Sprintf("%s#%d", id, index)
+}
+
+// Query mencari dokumen yang relevan di antara dokumen milik tenant
+func (c *ChromaClient) Query(ctx context.Context, tenant, queryText string, nResults int, whereFilter map[string]string) ([]chromem.Result, error) {
+	collection, err := c.tenantCollection(tenant, false)
+	if err != nil {
+		return nil, err
+	}
+	if collection == nil || collection.Count() == 0 {
+		return nil, nil
+	}
+
+	// chromem menolak nResults yang melebihi jumlah dokumen di collection
+	nResults = min(nResults, collection.Count())
+	results, err := collection.Query(ctx, queryText, nResults, whereFilter, nil)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
@@ -75,23 +272,197 @@ func (c *ChromaClient) Query(ctx context.Context, queryText string, nResults int
 	return results, nil
 }
 
-// GetRelevantContext mengambil context yang relevan untuk evaluasi
-func (c *ChromaClient) GetRelevantContext(ctx context.Context, queryText string, docType string, nResults int) (string, error) {
+// QuerySections mencari nResults chunk yang paling mirip dengan queryText di
+// antara chunk milik tenant yang cocok dengan whereFilter, terurut dari skor
+// tertinggi
+func (c *ChromaClient) QuerySections(ctx context.Context, tenant, queryText string, nResults int, whereFilter map[string]string) ([]Section, error) {
+	results, err := c.Query(ctx, tenant, queryText, nResults, whereFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make([]Section, 0, len(results))
+	for _, result := range results {
+		sections = append(sections, newSection(result))
+	}
+	return sections, nil
+}
+
+// RankSections mengembalikan semua chunk tenant yang cocok dengan whereFilter,
+// masing-masing dengan peringkat embedding dan peringkat BM25 terhadap
+// queryText, terurut dari similarity tertinggi. Query hanya di-embed sekali.
+func (c *ChromaClient) RankSections(ctx context.Context, tenant, queryText string, whereFilter map[string]string) ([]Section, error) {
+	collection, err := c.tenantCollection(tenant, false)
+	if err != nil {
+		return nil, err
+	}
+	if collection == nil || collection.Count() == 0 {
+		return nil, nil
+	}
+
+	embedding, err := c.embeddingFunc(ctx, queryText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	// pencarian chromem selalu exhaustive, jadi mengambil semua chunk yang
+	// lolos filter tidak lebih mahal daripada mengambil n teratas
+	results, err := collection.QueryEmbedding(ctx, embedding, collection.Count(), whereFilter, nil)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	index, err := c.keywordIndex(ctx, collection, embedding)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make([]Section, 0, len(results))
+	ids := make([]string, 0, len(results))
+	for i, result := range results {
+		section := newSection(result)
+		section.Ranking.VectorRank = i + 1
+		sections = append(sections, section)
+		ids = append(ids, result.ID)
+	}
+	rankKeywords(sections, index.Score(queryText, ids), ids)
+	return sections, nil
+}
+
+// keywordIndex mengembalikan index BM25 collection, membangunnya dari isi
+// collection jika belum ada. chromem tidak punya cara untuk membaca semua
+// dokumen selain query, jadi embedding apa pun dengan dimensi yang benar
+// dipakai untuk mengambil semuanya.
+func (c *ChromaClient) keywordIndex(ctx context.Context, collection *chromem.Collection, embedding []float32) (*KeywordIndex, error) {
+	c.keywordMu.Lock()
+	defer c.keywordMu.Unlock()
+	if index := c.keyword[collection]; index != nil {
+		return index, nil
+	}
+
+	index := NewKeywordIndex()
+	if n := collection.Count(); n > 0 {
+		all, err := collection.QueryEmbedding(ctx, embedding, n, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build keyword index for %s: %w", collection.Name, err)
+		}
+		for _, result := range all {
+			documentID := result.Metadata[metadataDocumentID]
+			if documentID == "" {
+				documentID = result.ID
+			}
+			index.Add(result.ID, documentID, keywordText(result.Metadata, result.Content))
+		}
+	}
+	c.keyword[collection] = index
+	return index, nil
+}
+
+// keywordText adalah teks chunk yang di-index BM25: heading ikut, sama seperti
+// yang di-embed
+func keywordText(metadata map[string]string, content string) string {
+	return metadata[metadataHeading] + "\n\n" + content
+}
+
+// rankKeywords mengisi skor dan peringkat BM25 section; ids[i] adalah ID
+// chunk sections[i]
+func rankKeywords(sections []Section, scores map[string]float32, ids []string) {
+	order := make([]int, 0, len(scores))
+	for i, id := range ids {
+		if score, ok := scores[id]; ok {
+			sections[i].Ranking.Keyword = score
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return sections[order[a]].Ranking.Keyword > sections[order[b]].Ranking.Keyword
+	})
+	for rank, i := range order {
+		sections[i].Ranking.KeywordRank = rank + 1
+	}
+}
+
+// Sections memotong teks dokumen yang tidak (lagi) ada di vector DB dengan
+// pengaturan chunking yang sama, dalam urutan dokumen dan tanpa skor
+func (c *ChromaClient) Sections(id, version, content string) []Section {
+	chunks := ChunkMarkdown(content, c.chunking)
+	sections := make([]Section, 0, len(chunks))
+	for _, chunk := range chunks {
+		sections = append(sections, Section{
+			DocumentID: id,
+			Version:    version,
+			Heading:    chunk.Heading,
+			Index:      chunk.Index,
+			Overlap:    chunk.Overlap,
+			Continued:  chunk.Continued,
+			Content:    chunk.Content,
+		})
+	}
+	return sections
+}
+
+// GetRelevantContext mengambil context yang relevan untuk evaluasi, hanya dari
+// ground truth milik tenant: section teratas sampai tokenBudget, digabung
+// sesuai urutan di dokumen
+func (c *ChromaClient) GetRelevantContext(ctx context.Context, tenant, queryText string, docType string, nResults, tokenBudget int) (string, error) {
 	whereFilter := map[string]string{"type": docType}
-	results, err := c.Query(ctx, queryText, nResults, whereFilter)
+	sections, err := c.QuerySections(ctx, tenant, queryText, nResults, whereFilter)
 	if err != nil {
 		return "", err
 	}
 
-	if len(results) == 0 {
-		return "", fmt.Errorf("no relevant documents found for type: %s", docType)
+	if len(sections) == 0 {
+		return "", fmt.Errorf("no relevant documents found for type %s in tenant %s", docType, tenant)
 	}
 
-	// Gabungkan semua hasil menjadi satu context
-	var context string
-	for _, result := range results {
-		context += result.Content + "\n\n"
+	return JoinSections(Reassemble(SelectSections(sections, tokenBudget))), nil
+}
+
+// GetDocumentContent mengambil isi lengkap satu dokumen tenant berdasarkan ID,
+// disusun ulang dari chunk-nya
+func (c *ChromaClient) GetDocumentContent(ctx context.Context, tenant, id string) (string, error) {
+	collection, err := c.tenantCollection(tenant, false)
+	if err != nil {
+		return "", err
+	}
+	if collection == nil {
+		return "", fmt.Errorf("document %s not found: tenant %s has no documents", id, tenant)
+	}
+
+	// dokumen yang di-index utuh sebelum chunking
+	if doc, err := collection.GetByID(ctx, id); err == nil {
+		return doc.Content, nil
 	}
 
-	return context, nil
+	var sections []Section
+	for i := 0; ; i++ {
+		doc, err := collection.GetByID(ctx, chunkID(id, i))
+		if err != nil {
+			break
+		}
+		sections = append(sections, newSection(chromem.Result{ID: doc.ID, Metadata: doc.Metadata, Content: doc.Content}))
+	}
+	if len(sections) == 0 {
+		return "", fmt.Errorf("document %s not found in tenant %s", id, tenant)
+	}
+	return JoinSections(Reassemble(sections)), nil
+}
+
+// CountByType menghitung dokumen tenant dengan ID yang diberikan yang benar-benar
+// ada di vector DB, dikelompokkan berdasarkan metadata "type"
+func (c *ChromaClient) CountByType(ctx context.Context, tenant string, ids []string) map[string]int {
+	counts := make(map[string]int)
+	collection, err := c.tenantCollection(tenant, false)
+	if err != nil || collection == nil {
+		return counts
+	}
+	for _, id := range ids {
+		doc, err := collection.GetByID(ctx, chunkID(id, 0))
+		if err != nil {
+			// dokumen yang di-index utuh sebelum chunking
+			if doc, err = collection.GetByID(ctx, id); err != nil {
+				continue
+			}
+		}
+		counts[doc.Metadata["type"]]++
+	}
+	return counts
 }
@@ -0,0 +1,86 @@
+package vectordb
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"testing"
+)
+
+// stubEmbedding hashes the words of text into a small normalised vector, so
+// chunks sharing words with the query rank higher without an embedding model
+func stubEmbedding(_ context.Context, text string) ([]float32, error) {
+	vector := make([]float32, 32)
+	for _, term := range Tokenize(text) {
+		h := fnv.New32a()
+		h.Write([]byte(term))
+		vector[h.Sum32()%uint32(len(vector))]++
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		vector[0], norm = 1, 1
+	}
+	for i := range vector {
+		vector[i] /= float32(math.Sqrt(norm))
+	}
+	return vector, nil
+}
+
+// TestReloadReadsOtherProcessWrites checks that documents another client (the
+// ingest command) writes to the same directory reach both the embedding and
+// the BM25 ranking after Reload
+func TestReloadReadsOtherProcessWrites(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	server, err := NewChromaClientWithEmbedding(dir, stubEmbedding, ChunkOptions{})
+	if err != nil {
+		t.Fatalf("open server client: %v", err)
+	}
+	if err := server.AddDocument(ctx, DefaultTenant, "doc-python", "# Skills\n\nPython and Django experience", nil); err != nil {
+		t.Fatalf("add document: %v", err)
+	}
+	// Builds the keyword index the server keeps in memory
+	if _, err := server.RankSections(ctx, DefaultTenant, "golang microservices", nil); err != nil {
+		t.Fatalf("rank: %v", err)
+	}
+
+	ingest, err := NewChromaClientWithEmbedding(dir, stubEmbedding, ChunkOptions{})
+	if err != nil {
+		t.Fatalf("open ingest client: %v", err)
+	}
+	if err := ingest.AddDocument(ctx, DefaultTenant, "doc-go", "# Skills\n\nGolang microservices experience", nil); err != nil {
+		t.Fatalf("add document: %v", err)
+	}
+
+	ranked := func() map[string]Section {
+		t.Helper()
+		sections, err := server.RankSections(ctx, DefaultTenant, "golang microservices", nil)
+		if err != nil {
+			t.Fatalf("rank: %v", err)
+		}
+		byDocument := make(map[string]Section)
+		for _, section := range sections {
+			byDocument[section.DocumentID] = section
+		}
+		return byDocument
+	}
+
+	if _, ok := ranked()["doc-go"]; ok {
+		t.Fatalf("server saw the ingested document before Reload")
+	}
+	if err := server.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	section, ok := ranked()["doc-go"]
+	if !ok {
+		t.Fatalf("server did not see the ingested document after Reload")
+	}
+	if section.Ranking.KeywordRank != 1 || section.Ranking.VectorRank != 1 {
+		t.Fatalf("ingested document ranked vector %d, keyword %d; want 1 and 1", section.Ranking.VectorRank, section.Ranking.KeywordRank)
+	}
+}
@@ -0,0 +1,228 @@
+package vectordb
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ChunkOptions mengatur pemotongan dokumen sebelum di-embed. Ukuran dihitung
+// dalam token perkiraan (lihat EstimateTokens).
+type ChunkOptions struct {
+	// MaxTokens adalah ukuran maksimum satu chunk; section yang lebih besar
+	// dipotong per paragraf
+	MaxTokens int
+	// OverlapTokens adalah ekor chunk sebelumnya yang diulang di awal chunk
+	// lanjutan dalam section yang sama, agar kalimat di batas potongan tidak
+	// kehilangan konteks
+	OverlapTokens int
+}
+
+func (o ChunkOptions) withDefaults() ChunkOptions {
+	if o.MaxTokens <= 0 {
+		o.MaxTokens = 400
+	}
+	if o.OverlapTokens < 0 {
+		o.OverlapTokens = 0
+	}
+	// overlap sebesar chunk tidak pernah maju
+	if o.OverlapTokens > o.MaxTokens/2 {
+		o.OverlapTokens = o.MaxTokens / 2
+	}
+	return o
+}
+
+// Chunk adalah satu potongan dokumen
+type Chunk struct {
+	// Index adalah urutan chunk di dokumen, mulai dari 0
+	Index int
+	// Heading adalah jalur heading section chunk, contoh
+	// "CV Evaluation Rubric > Technical Skills Match"; kosong untuk teks
+	// sebelum heading pertama
+	Heading string
+	Content string
+	// Overlap adalah panjang (byte) awal Content yang mengulang chunk
+	// sebelumnya; Continued berarti chunk melanjutkan section chunk sebelumnya
+	Overlap   int
+	Continued bool
+}
+
+// EstimateTokens memperkirakan jumlah token teks (sekitar 4 karakter per token
+// untuk teks bahasa Inggris). Cukup untuk membatasi ukuran chunk dan context
+// tanpa tokenizer model.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+var markdownHeading = regexp.MustCompile(`^(#{1,6})[ \t]+(.+?)[ \t#]*$`)
+
+// markdownSection adalah teks di bawah satu heading, sampai heading berikutnya
+type markdownSection struct {
+	heading string
+	text    string
+}
+
+// ChunkMarkdown memotong dokumen markdown per section (heading #..######).
+// Heading tanpa isi digabung ke section berikutnya, section yang melebihi
+// MaxTokens dipotong per paragraf dengan overlap. Teks tanpa heading (misalnya
+// hasil ekstraksi PDF) dipotong per ukuran saja.
+func ChunkMarkdown(text string, opts ChunkOptions) []Chunk {
+	opts = opts.withDefaults()
+
+	var chunks []Chunk
+	for _, section := range splitMarkdownSections(text) {
+		for i, part := range splitSection(section.text, opts) {
+			chunk := Chunk{
+				Index:     len(chunks),
+				Heading:   section.heading,
+				Content:   part,
+				Continued: i > 0,
+			}
+			if i > 0 && opts.OverlapTokens > 0 {
+				overlap := tail(chunks[len(chunks)-1].Content, opts.OverlapTokens)
+				if overlap != "" {
+					chunk.Content = overlap + "\n\n" + part
+					chunk.Overlap = len(overlap) + 2
+				}
+			}
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks
+}
+
+func splitMarkdownSections(text string) []markdownSection {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	type level struct {
+		depth int
+		title string
+	}
+	var (
+		sections []markdownSection
+		stack    []level
+		lines    []string
+		pending  string // heading tanpa isi, menunggu section berikutnya
+		inFence  bool
+	)
+	path := func() string {
+		titles := make([]string, 0, len(stack))
+		for _, l := range stack {
+			titles = append(titles, l.title)
+		}
+		return strings.Join(titles, " > ")
+	}
+	flush := func() {
+		body := strings.TrimSpace(strings.Join(lines, "\n"))
+		lines = nil
+		if body == "" {
+			return
+		}
+		// section yang hanya berisi heading-nya sendiri
+		if !strings.Contains(body, "\n") && markdownHeading.MatchString(body) {
+			pending += body + "\n\n"
+			return
+		}
+		sections = append(sections, markdownSection{heading: path(), text: pending + body})
+		pending = ""
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+		}
+		if match := markdownHeading.FindStringSubmatch(line); match != nil && !inFence {
+			flush()
+			depth := len(match[1])
+			for len(stack) > 0 && stack[len(stack)-1].depth >= depth {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, level{depth: depth, title: match[2]})
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	if pending != "" {
+		sections = append(sections, markdownSection{heading: path(), text: strings.TrimSpace(pending)})
+	}
+	return sections
+}
+
+// splitSection memotong teks satu section menjadi bagian-bagian <= MaxTokens,
+// sebisa mungkin di batas paragraf
+func splitSection(text string, opts ChunkOptions) []string {
+	if EstimateTokens(text) <= opts.MaxTokens {
+		return []string{text}
+	}
+
+	// sisakan tempat untuk overlap di awal chunk lanjutan
+	budget := opts.MaxTokens - opts.OverlapTokens
+	var (
+		parts   []string
+		current []string
+		size    int
+	)
+	emit := func() {
+		if len(current) > 0 {
+			parts = append(parts, strings.Join(current, "\n\n"))
+			current, size = nil, 0
+		}
+	}
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		for _, piece := range splitWords(paragraph, budget) {
+			tokens := EstimateTokens(piece)
+			if size > 0 && size+tokens > budget {
+				emit()
+			}
+			current = append(current, piece)
+			size += tokens
+		}
+	}
+	emit()
+	return parts
+}
+
+// splitWords memotong paragraf yang sendirian sudah melebihi maxTokens
+func splitWords(paragraph string, maxTokens int) []string {
+	if EstimateTokens(paragraph) <= maxTokens {
+		return []string{paragraph}
+	}
+	var (
+		pieces []string
+		words  []string
+		size   int
+	)
+	for _, word := range strings.Fields(paragraph) {
+		tokens := EstimateTokens(word + " ")
+		if size > 0 && size+tokens > maxTokens {
+			pieces = append(pieces, strings.Join(words, " "))
+			words, size = nil, 0
+		}
+		words = append(words, word)
+		size += tokens
+	}
+	if len(words) > 0 {
+		pieces = append(pieces, strings.Join(words, " "))
+	}
+	return pieces
+}
+
+// tail mengembalikan kata-kata terakhir teks sebanyak kira-kira tokens
+func tail(text string, tokens int) string {
+	words := strings.Fields(text)
+	size := 0
+	start := len(words)
+	for start > 0 {
+		size += EstimateTokens(words[start-1] + " ")
+		if size > tokens {
+			break
+		}
+		start--
+	}
+	return strings.Join(words[start:], " ")
+}
@@ -0,0 +1,274 @@
+package vectordb
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestChunkMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Chunk
+	}{
+		{
+			name: "heading without body is merged into the next section",
+			text: "# Rubric\n\n## Skills\n\nGo and SQL",
+			want: []Chunk{{Index: 0, Heading: "Rubric > Skills", Content: "# Rubric\n\n## Skills\n\nGo and SQL"}},
+		},
+		{
+			name: "heading inside a code fence does not start a section",
+			text: "# Setup\n\n```sh\n# not a heading\nmake run\n```\n\nThen open the UI",
+			want: []Chunk{{Index: 0, Heading: "Setup", Content: "# Setup\n\n```sh\n# not a heading\nmake run\n```\n\nThen open the UI"}},
+		},
+		{
+			name: "text before the first heading has no heading",
+			text: "Intro\n\n# Skills\n\nGo",
+			want: []Chunk{
+				{Index: 0, Heading: "", Content: "Intro"},
+				{Index: 1, Heading: "Skills", Content: "# Skills\n\nGo"},
+			},
+		},
+		{
+			name: "sibling heading replaces the previous one in the path",
+			text: "# Rubric\n\n## Skills\n\nGo\n\n## Experience\n\nFive years",
+			want: []Chunk{
+				{Index: 0, Heading: "Rubric > Skills", Content: "# Rubric\n\n## Skills\n\nGo"},
+				{Index: 1, Heading: "Rubric > Experience", Content: "## Experience\n\nFive years"},
+			},
+		},
+		{
+			name: "trailing heading without body is kept",
+			text: "# Skills\n\nGo\n\n# Notes",
+			want: []Chunk{
+				{Index: 0, Heading: "Skills", Content: "# Skills\n\nGo"},
+				{Index: 1, Heading: "Notes", Content: "# Notes"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChunkMarkdown(tt.text, ChunkOptions{})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ChunkMarkdown() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestChunkMarkdownOverlap checks that continued chunks of an oversized
+// section repeat the tail of the previous chunk and stay within MaxTokens
+func TestChunkMarkdownOverlap(t *testing.T) {
+	var paragraphs []string
+	for i := 0; i < 6; i++ {
+		paragraphs = append(paragraphs, fmt.Sprintf("Paragraph %d covers retries, timeouts and error handling.", i))
+	}
+	opts := ChunkOptions{MaxTokens: 40, OverlapTokens: 5}
+	chunks := ChunkMarkdown("# Resilience\n\n"+strings.Join(paragraphs, "\n\n"), opts)
+
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want the section split", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.Heading != "Resilience" {
+			t.Errorf("chunk %d heading = %q, want Resilience", i, chunk.Heading)
+		}
+		if tokens := EstimateTokens(chunk.Content); tokens > opts.MaxTokens {
+			t.Errorf("chunk %d has %d tokens, want at most %d", i, tokens, opts.MaxTokens)
+		}
+		if i == 0 {
+			if chunk.Continued || chunk.Overlap != 0 {
+				t.Errorf("first chunk continued %t, overlap %d; want neither", chunk.Continued, chunk.Overlap)
+			}
+			continue
+		}
+		if !chunk.Continued || chunk.Overlap == 0 {
+			t.Fatalf("chunk %d continued %t, overlap %d; want a continued chunk with overlap", i, chunk.Continued, chunk.Overlap)
+		}
+		overlap := strings.TrimSuffix(chunk.Content[:chunk.Overlap], "\n\n")
+		if overlap != tail(chunks[i-1].Content, opts.OverlapTokens) {
+			t.Errorf("chunk %d starts with %q, want the tail of chunk %d", i, overlap, i-1)
+		}
+	}
+}
+
+func TestSplitSection(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts ChunkOptions
+		want []string
+	}{
+		{
+			name: "section within the limit stays whole",
+			text: "Go and SQL\n\nREST APIs",
+			opts: ChunkOptions{MaxTokens: 10},
+			want: []string{"Go and SQL\n\nREST APIs"},
+		},
+		{
+			name: "split at paragraph boundaries",
+			text: "aaaa bbbb\n\ncccc dddd\n\neeee ffff",
+			opts: ChunkOptions{MaxTokens: 7},
+			want: []string{"aaaa bbbb\n\ncccc dddd", "eeee ffff"},
+		},
+		{
+			name: "oversized paragraph is split by words",
+			text: "one two three four five six seven eight",
+			opts: ChunkOptions{MaxTokens: 6},
+			want: []string{"one two three four", "five six seven\n\neight"},
+		},
+		{
+			name: "overlap is reserved in every part",
+			text: "aaaa bbbb\n\ncccc dddd\n\neeee ffff",
+			opts: ChunkOptions{MaxTokens: 7, OverlapTokens: 2},
+			want: []string{"aaaa bbbb", "cccc dddd", "eeee ffff"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitSection(tt.text, tt.opts); !slices.Equal(got, tt.want) {
+				t.Errorf("splitSection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		name      string
+		paragraph string
+		maxTokens int
+		want      []string
+	}{
+		{"paragraph within the limit", "Go and SQL", 5, []string{"Go and SQL"}},
+		{"split between words", "one two three four five six", 4, []string{"one two three", "four five", "six"}},
+		{"word longer than the limit is kept whole", "supercalifragilistic", 2, []string{"supercalifragilistic"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitWords(tt.paragraph, tt.maxTokens); !slices.Equal(got, tt.want) {
+				t.Errorf("splitWords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTail(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		tokens int
+		want   string
+	}{
+		{"last word", "alpha beta gamma", 2, "gamma"},
+		{"last words", "alpha beta gamma", 4, "beta gamma"},
+		{"whole text within the limit", "alpha beta gamma", 100, "alpha beta gamma"},
+		{"no tokens", "alpha beta gamma", 0, ""},
+		{"empty text", "", 5, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tail(tt.text, tt.tokens); got != tt.want {
+				t.Errorf("tail(%q, %d) = %q, want %q", tt.text, tt.tokens, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectSections(t *testing.T) {
+	section := func(doc string, index, tokens int, score float32) Section {
+		return Section{DocumentID: doc, Index: index, Content: strings.Repeat("x", tokens*4), Score: score}
+	}
+	tests := []struct {
+		name     string
+		sections []Section
+		budget   int
+		want     []string // document#index in the returned order
+	}{
+		{
+			name:     "top section is kept even when over budget",
+			sections: []Section{section("a", 0, 25, 0.9), section("a", 1, 2, 0.5)},
+			budget:   5,
+			want:     []string{"a#0"},
+		},
+		{
+			name:     "smaller lower-ranked sections fill the budget",
+			sections: []Section{section("a", 0, 4, 0.9), section("a", 1, 10, 0.8), section("a", 2, 2, 0.7)},
+			budget:   7,
+			want:     []string{"a#0", "a#2"},
+		},
+		{
+			name:     "document order within the document of the best section first",
+			sections: []Section{section("b", 3, 1, 0.9), section("a", 0, 1, 0.8), section("b", 1, 1, 0.7)},
+			budget:   10,
+			want:     []string{"b#1", "b#3", "a#0"},
+		},
+		{
+			name:   "nothing to select",
+			budget: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range SelectSections(tt.sections, tt.budget) {
+				got = append(got, fmt.Sprintf("%s#%d", s.DocumentID, s.Index))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SelectSections() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReassemble(t *testing.T) {
+	first := Section{DocumentID: "a", Index: 0, Heading: "Skills", Content: "Go and SQL"}
+	continued := func(doc string, index int) Section {
+		return Section{DocumentID: doc, Index: index, Heading: "Skills", Overlap: len("SQL\n\n"), Continued: true, Content: "SQL\n\nREST APIs"}
+	}
+	tests := []struct {
+		name     string
+		sections []Section
+		want     []string
+	}{
+		{
+			name:     "overlap is stripped after the adjacent chunk",
+			sections: []Section{first, continued("a", 1)},
+			want:     []string{"Go and SQL", "REST APIs"},
+		},
+		{
+			name:     "overlap is kept and the heading labelled after a gap",
+			sections: []Section{first, continued("a", 2)},
+			want:     []string{"Go and SQL", "[Skills]\nSQL\n\nREST APIs"},
+		},
+		{
+			name:     "chunk of another document is not adjacent",
+			sections: []Section{first, continued("b", 1)},
+			want:     []string{"Go and SQL", "[Skills]\nSQL\n\nREST APIs"},
+		},
+		{
+			name:     "continued chunk selected alone is labelled",
+			sections: []Section{continued("a", 1)},
+			want:     []string{"[Skills]\nSQL\n\nREST APIs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range Reassemble(tt.sections) {
+				got = append(got, s.Content)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Reassemble() contents = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
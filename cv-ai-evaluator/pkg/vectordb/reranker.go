@@ -0,0 +1,204 @@
+package vectordb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cv-ai-evaluator/pkg/llm"
+)
+
+// Reranker menilai ulang relevansi kandidat terhadap query, lebih teliti
+// (dan lebih mahal) daripada embedding atau BM25. Hasilnya satu skor per
+// section dengan urutan yang sama; makin tinggi makin relevan.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, sections []Section) ([]float32, error)
+}
+
+// Jenis reranker yang didukung (dipilih lewat RAG_RERANKER)
+const (
+	RerankerNone         = "none"
+	RerankerLLM          = "llm"
+	RerankerCrossEncoder = "cross-encoder"
+)
+
+// Pastikan semua reranker memenuhi interface Reranker
+var (
+	_ Reranker = (*LLMReranker)(nil)
+	_ Reranker = (*CrossEncoderReranker)(nil)
+)
+
+// NewReranker membuat Reranker sesuai jenisnya. Jenis kosong atau "none"
+// berarti tanpa reranking (nil). maxRepairs hanya dipakai reranker LLM.
+func NewReranker(kind, crossEncoderURL string, provider llm.Provider, maxRepairs int) (Reranker, error) {
+	switch kind {
+	case "", RerankerNone:
+		return nil, nil
+	case RerankerLLM:
+		return NewLLMReranker(provider, maxRepairs), nil
+	case RerankerCrossEncoder:
+		if crossEncoderURL == "" {
+			return nil, fmt.Errorf("reranker %q needs a service URL", kind)
+		}
+		return NewCrossEncoderReranker(crossEncoderURL), nil
+	default:
+		return nil, fmt.Errorf("unknown reranker %q (supported: %s, %s, %s)", kind, RerankerNone, RerankerLLM, RerankerCrossEncoder)
+	}
+}
+
+// rerankPassage adalah teks section yang dinilai reranker: heading ikut agar
+// chunk lanjutan tetap dikenali section-nya
+func rerankPassage(section Section) string {
+	if section.Heading == "" {
+		return section.Content
+	}
+	return section.Heading + "\n\n" + section.Content
+}
+
+// LLMReranker meminta LLM evaluasi memberi nilai 0-10 untuk setiap kandidat
+// dalam satu panggilan. Jawaban divalidasi dan di-repair seperti output
+// evaluasi worker (llm.GenerateStructured).
+type LLMReranker struct {
+	provider   llm.Provider
+	maxRepairs int
+}
+
+// NewLLMReranker membuat reranker yang memakai provider LLM, dengan maksimal
+// maxRepairs re-prompt jika jawabannya tidak sesuai schema
+func NewLLMReranker(provider llm.Provider, maxRepairs int) *LLMReranker {
+	return &LLMReranker{provider: provider, maxRepairs: maxRepairs}
+}
+
+// Rerank menilai semua section sekaligus; setiap section wajib dinilai
+func (r *LLMReranker) Rerank(ctx context.Context, query string, sections []Section) ([]float32, error) {
+	var passages strings.Builder
+	for i, section := range sections {
+		fmt.Fprintf(&passages, "[%d]\n%s\n\n", i+1, rerankPassage(section))
+	}
+	prompt := fmt.Sprintf(`You are ranking passages of hiring ground truth documents (job descriptions, case study briefs and scoring rubrics) by how useful they are for the query below.
+
+Query: %s
+
+Passages:
+%s
+Rate EVERY passage from 0 (irrelevant) to 10 (directly answers the query).
+
+IMPORTANT: Your response MUST be valid JSON mapping each passage number to its rating, for example:
+{"scores": {"1": 7, "2": 0}}`, query, passages.String())
+
+	var parsed struct {
+		Scores map[string]float32 `json:"scores"`
+	}
+	if err := llm.GenerateStructured(llm.WithStage(ctx, llm.StageRerank), r.provider, prompt, 0, rerankSchema(len(sections)), r.maxRepairs, &parsed); err != nil {
+		return nil, fmt.Errorf("LLM rerank failed: %w", err)
+	}
+
+	scores := make([]float32, len(sections))
+	for i := range sections {
+		scores[i] = min(max(parsed.Scores[strconv.Itoa(i+1)], 0), 10)
+	}
+	return scores, nil
+}
+
+// rerankSchema membatasi jawaban LLM ke nilai 0-10 untuk passage 1..n;
+// semua object tertutup agar schema lolos strict mode OpenAI
+func rerankSchema(n int) *llm.Schema {
+	minimum, maximum := 0.0, 10.0
+	noExtra := false
+	properties := make(map[string]*llm.Schema, n)
+	required := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		key := strconv.Itoa(i)
+		properties[key] = &llm.Schema{Type: "number", Minimum: &minimum, Maximum: &maximum}
+		required = append(required, key)
+	}
+	return &llm.Schema{
+		Type: "object",
+		Properties: map[string]*llm.Schema{
+			"scores": {
+				Type:                 "object",
+				Properties:           properties,
+				Required:             required,
+				AdditionalProperties: &noExtra,
+			},
+		},
+		Required:             []string{"scores"},
+		AdditionalProperties: &noExtra,
+	}
+}
+
+// CrossEncoderReranker memanggil model cross-encoder (misalnya
+// BAAI/bge-reranker-base) yang di-serve dengan API /rerank milik Text
+// Embeddings Inference
+type CrossEncoderReranker struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewCrossEncoderReranker membuat reranker untuk service di baseURL
+func NewCrossEncoderReranker(baseURL string) *CrossEncoderReranker {
+	return &CrossEncoderReranker{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type crossEncoderRequest struct {
+	Query    string   `json:"query"`
+	Texts    []string `json:"texts"`
+	Truncate bool     `json:"truncate"`
+}
+
+type crossEncoderResult struct {
+	Index int     `json:"index"`
+	Score float32 `json:"score"`
+}
+
+// Rerank mengirim query dan semua section dalam satu request
+func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, sections []Section) ([]float32, error) {
+	texts := make([]string, len(sections))
+	for i, section := range sections {
+		texts[i] = rerankPassage(section)
+	}
+	body, err := json.Marshal(crossEncoderRequest{Query: query, Texts: texts, Truncate: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rerank request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.BaseURL+"/rerank", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rerank request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rerank request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("rerank service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+	}
+
+	var results []crossEncoderResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode rerank response: %w", err)
+	}
+
+	scores := make([]float32, len(sections))
+	for _, result := range results {
+		if result.Index < 0 || result.Index >= len(sections) {
+			return nil, fmt.Errorf("rerank response has unknown index %d", result.Index)
+		}
+		scores[result.Index] = result.Score
+	}
+	return scores, nil
+}
@@ -0,0 +1,71 @@
+package vectordb
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"cv-ai-evaluator/pkg/llm"
+)
+
+func TestLLMReranker(t *testing.T) {
+	sections := []Section{
+		{Heading: "Requirements", Content: "Golang and PostgreSQL"},
+		{Heading: "Benefits", Content: "Remote work"},
+		{Heading: "Process", Content: "Three interview rounds"},
+	}
+	tests := []struct {
+		name      string
+		responses []string // answered in order before the fake's default
+		want      []float32
+		wantErr   bool
+		wantCalls int
+	}{
+		{"fake default rates every passage", nil, []float32{5, 5, 5}, false, 1},
+		{"code fence is stripped", []string{"```json\n{\"scores\": {\"1\": 9, \"2\": 2, \"3\": 0}}\n```"}, []float32{9, 2, 0}, false, 1},
+		{"missing passage is repaired", []string{`{"scores": {"1": 9, "3": 1}}`}, []float32{5, 5, 5}, false, 2},
+		{"invalid JSON is repaired", []string{`scores: 1=9`}, []float32{5, 5, 5}, false, 2},
+		{"gives up after the repair limit", []string{`{"scores": {"1": 11}}`, `{"scores": {}}`}, nil, true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := llm.NewFakeProvider()
+			for i := len(tt.responses) - 1; i >= 0; i-- {
+				provider.AddRule(llm.FakeRule{Stage: llm.StageRerank, Response: tt.responses[i], Times: 1})
+			}
+
+			scores, err := NewLLMReranker(provider, 1).Rerank(context.Background(), "golang backend", sections)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("Rerank err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(scores, tt.want) {
+				t.Fatalf("Rerank = %v, want %v", scores, tt.want)
+			}
+
+			calls := provider.Calls()
+			if len(calls) != tt.wantCalls {
+				t.Fatalf("%d LLM call(s), want %d", len(calls), tt.wantCalls)
+			}
+			if len(calls) > 1 && !strings.Contains(calls[1].Prompt, "failed validation") {
+				t.Fatalf("second call is not a repair prompt:\n%s", calls[1].Prompt)
+			}
+		})
+	}
+}
+
+// TestRerankSchemaIsStrict checks that the rerank schema is accepted by OpenAI
+// strict mode once the range keywords are dropped, while the full schema
+// still rejects an out-of-range score
+func TestRerankSchemaIsStrict(t *testing.T) {
+	schema := rerankSchema(3)
+	if violations := schema.Strict().StrictViolations(); len(violations) > 0 {
+		t.Errorf("rerank schema is not strict-mode valid: %v", violations)
+	}
+
+	response := map[string]interface{}{"scores": map[string]interface{}{"1": 11.0, "2": 5.0, "3": 0.0}}
+	if len(schema.Validate(response)) == 0 {
+		t.Errorf("rerank schema accepted a score of 11")
+	}
+}
@@ -0,0 +1,120 @@
+package vectordb
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	chromem "github.com/philippgille/chromem-go"
+)
+
+// Section adalah satu chunk ground truth hasil retrieval beserta dokumen
+// asalnya
+type Section struct {
+	DocumentID string
+	Version    string
+	Heading    string
+	Index      int
+	Overlap    int
+	Continued  bool
+	Content    string
+	// Score adalah skor yang menentukan urutan section: skor reranker jika
+	// kandidat di-rerank, skor fusion pada hybrid retrieval, atau similarity
+	// embedding. 0 untuk section yang tidak di-rank.
+	Score float32
+	// Ranking merinci asal Score
+	Ranking Ranking
+}
+
+// Ranking adalah skor dan peringkat section dari setiap tahap retrieval.
+// Peringkat dimulai dari 1; 0 berarti section tidak di-rank oleh tahap itu.
+type Ranking struct {
+	Similarity  float32 // cosine similarity embedding
+	VectorRank  int
+	Keyword     float32 // skor BM25
+	KeywordRank int     // 0 jika chunk tidak memuat term query
+	Fused       float32 // reciprocal rank fusion kedua peringkat
+	Rerank      float32
+	Reranked    bool
+}
+
+// newSection membaca metadata chunk hasil query. Dokumen yang di-index
+// sebelum chunking tersimpan utuh dan menjadi satu section.
+func newSection(result chromem.Result) Section {
+	section := Section{
+		DocumentID: result.Metadata[metadataDocumentID],
+		Version:    result.Metadata["version"],
+		Heading:    result.Metadata[metadataHeading],
+		Continued:  result.Metadata[metadataContinued] == "true",
+		Content:    result.Content,
+		Score:      result.Similarity,
+		Ranking:    Ranking{Similarity: result.Similarity},
+	}
+	if section.DocumentID == "" {
+		section.DocumentID = result.ID
+	}
+	section.Index, _ = strconv.Atoi(result.Metadata[metadataChunk])
+	section.Overlap, _ = strconv.Atoi(result.Metadata[metadataOverlap])
+	return section
+}
+
+// SelectSections mengambil section dengan skor tertinggi selama total tokennya
+// muat di tokenBudget, lalu mengurutkannya sesuai urutan di dokumen (dokumen
+// dengan section terbaik lebih dulu). Section teratas selalu diambil meskipun
+// sendirian melebihi budget, agar context tidak pernah kosong.
+func SelectSections(ranked []Section, tokenBudget int) []Section {
+	ranked = append([]Section(nil), ranked...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Score > ranked[j].Score })
+
+	var selected []Section
+	used := 0
+	for _, section := range ranked {
+		tokens := EstimateTokens(section.Content)
+		if len(selected) > 0 && used+tokens > tokenBudget {
+			continue
+		}
+		selected = append(selected, section)
+		used += tokens
+	}
+
+	documentRank := make(map[string]int)
+	for _, section := range selected {
+		if _, ok := documentRank[section.DocumentID]; !ok {
+			documentRank[section.DocumentID] = len(documentRank)
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].DocumentID != selected[j].DocumentID {
+			return documentRank[selected[i].DocumentID] < documentRank[selected[j].DocumentID]
+		}
+		return selected[i].Index < selected[j].Index
+	})
+	return selected
+}
+
+// Reassemble menyiapkan section yang sudah terurut untuk digabung: overlap
+// dibuang jika chunk sebelumnya ikut terpilih, dan section lanjutan yang
+// terpisah dari awalnya diberi label heading-nya
+func Reassemble(sections []Section) []Section {
+	out := make([]Section, len(sections))
+	for i, section := range sections {
+		adjacent := i > 0 && sections[i-1].DocumentID == section.DocumentID && sections[i-1].Index == section.Index-1
+		switch {
+		case adjacent && section.Overlap > 0 && section.Overlap <= len(section.Content):
+			section.Content = section.Content[section.Overlap:]
+		case !adjacent && section.Continued && section.Heading != "":
+			section.Content = "[" + section.Heading + "]\n" + section.Content
+		}
+		out[i] = section
+	}
+	return out
+}
+
+// JoinSections menggabungkan section hasil Reassemble menjadi satu context
+func JoinSections(sections []Section) string {
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		parts = append(parts, section.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,142 @@
+package vectordb
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// DefaultRRFK adalah konstanta k reciprocal rank fusion dari paper aslinya
+// (Cormack dkk., 2009); makin besar, makin rata bobot antar peringkat
+const DefaultRRFK = 60
+
+// DefaultCandidates adalah jumlah section teratas yang dikembalikan (dan
+// di-rerank) per query jika RetrieverConfig.Candidates tidak diisi
+const DefaultCandidates = 12
+
+// RetrieverConfig mengatur cara Retriever me-rank section ground truth
+type RetrieverConfig struct {
+	// Hybrid menggabungkan peringkat embedding dengan peringkat BM25 lewat
+	// reciprocal rank fusion; false berarti embedding saja
+	Hybrid bool
+	// RRFK adalah konstanta k fusion, default DefaultRRFK
+	RRFK int
+	// Candidates adalah jumlah section teratas yang dikembalikan per query
+	Candidates int
+	// Reranker (opsional) menilai ulang kandidat teratas hasil fusion
+	Reranker Reranker
+}
+
+// Retriever mencari section ground truth yang paling relevan untuk sebuah
+// query: peringkat embedding dan BM25 atas chunk yang sama digabung, lalu
+// kandidat teratas bisa dinilai ulang oleh Reranker
+type Retriever struct {
+	client *ChromaClient
+	cfg    RetrieverConfig
+}
+
+// NewRetriever membuat Retriever di atas vector DB client
+func NewRetriever(client *ChromaClient, cfg RetrieverConfig) *Retriever {
+	if cfg.RRFK <= 0 {
+		cfg.RRFK = DefaultRRFK
+	}
+	if cfg.Candidates <= 0 {
+		cfg.Candidates = DefaultCandidates
+	}
+	return &Retriever{client: client, cfg: cfg}
+}
+
+// Config mengembalikan pengaturan Retriever
+func (r *Retriever) Config() RetrieverConfig {
+	return r.cfg
+}
+
+// Retrieve mengembalikan Candidates section tenant yang cocok dengan whereFilter dan
+// paling relevan dengan query, terurut dari Score tertinggi
+func (r *Retriever) Retrieve(ctx context.Context, tenant, query string, whereFilter map[string]string) ([]Section, error) {
+	sections, err := r.client.RankSections(ctx, tenant, query, whereFilter)
+	if err != nil {
+		return nil, err
+	}
+	return r.rank(ctx, query, sections, r.cfg.Hybrid), nil
+}
+
+// Rank memilih Candidates section paling relevan dari section yang tidak ada di vector
+// DB, misalnya versi dokumen yang sudah di-retire. Tanpa embedding, BM25 (juga
+// saat Hybrid false) dan Reranker yang menentukan urutan; section yang tidak
+// di-rank tetap dalam urutan dokumen. Jika tidak ada section yang mendapat
+// skor, semua section dikembalikan.
+func (r *Retriever) Rank(ctx context.Context, query string, sections []Section) []Section {
+	sections = append([]Section(nil), sections...)
+	index := NewKeywordIndex()
+	ids := make([]string, len(sections))
+	for i, section := range sections {
+		ids[i] = chunkID(section.DocumentID, section.Index)
+		index.Add(ids[i], section.DocumentID, section.Heading+"\n\n"+section.Content)
+	}
+	rankKeywords(sections, index.Score(query, ids), ids)
+	return r.rank(ctx, query, sections, true)
+}
+
+// Sections memotong teks dokumen yang tidak ada di vector DB dengan pengaturan
+// chunking vector DB, sebagai input Rank
+func (r *Retriever) Sections(id, version, content string) []Section {
+	return r.client.Sections(id, version, content)
+}
+
+// rank menggabungkan peringkat section (BM25 ikut jika hybrid), mengambil
+// kandidat teratas dan me-rerank-nya. Tanpa skor sama sekali, memotong ke
+// Candidates hanya akan membuang section berdasarkan posisinya di dokumen.
+func (r *Retriever) rank(ctx context.Context, query string, sections []Section, hybrid bool) []Section {
+	scored := false
+	for i := range sections {
+		ranking := &sections[i].Ranking
+		ranking.Fused = r.fuse(ranking.VectorRank)
+		if hybrid {
+			ranking.Fused += r.fuse(ranking.KeywordRank)
+			sections[i].Score = ranking.Fused
+		} else {
+			sections[i].Score = ranking.Similarity
+		}
+		scored = scored || sections[i].Score != 0
+	}
+	sortByScore(sections)
+	if scored && len(sections) > r.cfg.Candidates {
+		sections = sections[:r.cfg.Candidates]
+	}
+
+	if r.cfg.Reranker == nil || len(sections) == 0 {
+		return sections
+	}
+	scores, err := r.cfg.Reranker.Rerank(ctx, query, sections)
+	if err == nil && len(scores) != len(sections) {
+		err = fmt.Errorf("reranker returned %d scores for %d sections", len(scores), len(sections))
+	}
+	if err != nil {
+		// reranking hanya memperbaiki urutan; tanpa itu hasil fusion tetap dipakai
+		log.Printf("Warning: reranking failed, keeping fused ranking: %v", err)
+		return sections
+	}
+	for i := range sections {
+		sections[i].Ranking.Rerank = scores[i]
+		sections[i].Ranking.Reranked = true
+		sections[i].Score = scores[i]
+	}
+	sortByScore(sections)
+	return sections
+}
+
+// fuse adalah kontribusi satu peringkat ke skor reciprocal rank fusion
+func (r *Retriever) fuse(rank int) float32 {
+	if rank <= 0 {
+		return 0
+	}
+	return 1 / float32(r.cfg.RRFK+rank)
+}
+
+// sortByScore mengurutkan section dari Score tertinggi; section dengan skor
+// sama tetap dalam urutan sebelumnya
+func sortByScore(sections []Section) {
+	sort.SliceStable(sections, func(i, j int) bool { return sections[i].Score > sections[j].Score })
+}
@@ -0,0 +1,196 @@
+package vectordb
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRankWithoutEmbeddings(t *testing.T) {
+	sections := []Section{
+		{DocumentID: "doc", Index: 0, Heading: "Intro", Content: "About the company"},
+		{DocumentID: "doc", Index: 1, Heading: "Benefits", Content: "Remote work and health insurance"},
+		{DocumentID: "doc", Index: 2, Heading: "Process", Content: "Three interview rounds"},
+		{DocumentID: "doc", Index: 3, Heading: "Requirements", Content: "Golang and PostgreSQL experience"},
+	}
+	tests := []struct {
+		name   string
+		hybrid bool
+		query  string
+		first  int // index of the best section
+		count  int
+	}{
+		{"keyword match first when hybrid", true, "golang experience", 3, 2},
+		{"keyword match first without hybrid", false, "golang experience", 3, 2},
+		{"nothing matches keeps every section", false, "kubernetes", 0, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retriever := NewRetriever(nil, RetrieverConfig{Hybrid: tt.hybrid, Candidates: 2})
+			ranked := retriever.Rank(context.Background(), tt.query, sections)
+
+			var got []int
+			for _, section := range ranked {
+				got = append(got, section.Index)
+			}
+			if len(got) != tt.count || got[0] != tt.first {
+				t.Fatalf("Rank returned sections %v, want %d starting with %d", got, tt.count, tt.first)
+			}
+		})
+	}
+}
+
+func TestFuse(t *testing.T) {
+	retriever := NewRetriever(nil, RetrieverConfig{RRFK: 60})
+	tests := []struct {
+		rank int
+		want float32
+	}{
+		{0, 0},
+		{1, 1.0 / 61},
+		{10, 1.0 / 70},
+	}
+
+	for _, tt := range tests {
+		if got := retriever.fuse(tt.rank); got != tt.want {
+			t.Errorf("fuse(%d) = %v, want %v", tt.rank, got, tt.want)
+		}
+	}
+}
+
+// rankedSections returns sections with the given stage rankings, as
+// RankSections and rankKeywords leave them
+func rankedSections(rankings ...Ranking) []Section {
+	sections := make([]Section, len(rankings))
+	for i, ranking := range rankings {
+		sections[i] = Section{DocumentID: "doc", Index: i, Content: "chunk", Ranking: ranking}
+	}
+	return sections
+}
+
+func sectionIndexes(sections []Section) []int {
+	indexes := make([]int, 0, len(sections))
+	for _, section := range sections {
+		indexes = append(indexes, section.Index)
+	}
+	return indexes
+}
+
+func TestRankFusion(t *testing.T) {
+	// 0: nearest embedding without any query term; 1: a little further but
+	// the only chunk containing the query terms; 2: far and without terms
+	sections := rankedSections(
+		Ranking{Similarity: 0.62, VectorRank: 1},
+		Ranking{Similarity: 0.58, VectorRank: 2, Keyword: 3.1, KeywordRank: 1},
+		Ranking{Similarity: 0.20, VectorRank: 3},
+	)
+	tests := []struct {
+		name   string
+		hybrid bool
+		want   []int
+	}{
+		{"term match outranks the vector-only neighbour when hybrid", true, []int{1, 0, 2}},
+		{"embedding order without hybrid", false, []int{0, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retriever := NewRetriever(nil, RetrieverConfig{})
+			ranked := retriever.rank(context.Background(), "golang", slices.Clone(sections), tt.hybrid)
+			if got := sectionIndexes(ranked); !slices.Equal(got, tt.want) {
+				t.Errorf("rank order = %v, want %v", got, tt.want)
+			}
+			for _, section := range ranked {
+				want := section.Ranking.Similarity
+				if tt.hybrid {
+					want = retriever.fuse(section.Ranking.VectorRank) + retriever.fuse(section.Ranking.KeywordRank)
+				}
+				if section.Score != want {
+					t.Errorf("section %d score = %v, want %v", section.Index, section.Score, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRankCandidates(t *testing.T) {
+	tests := []struct {
+		name     string
+		rankings []Ranking
+		hybrid   bool
+		want     []int
+	}{
+		{
+			name:     "truncated to the best candidates",
+			rankings: []Ranking{{Similarity: 0.1, VectorRank: 3}, {Similarity: 0.9, VectorRank: 1}, {Similarity: 0.5, VectorRank: 2}},
+			want:     []int{1, 2},
+		},
+		{
+			name:     "nothing scored keeps every section in document order",
+			rankings: []Ranking{{}, {}, {}},
+			hybrid:   true,
+			want:     []int{0, 1, 2},
+		},
+		{
+			name:     "unscored sections follow the scored ones",
+			rankings: []Ranking{{}, {KeywordRank: 1}, {}},
+			hybrid:   true,
+			want:     []int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retriever := NewRetriever(nil, RetrieverConfig{Candidates: 2})
+			ranked := retriever.rank(context.Background(), "golang", rankedSections(tt.rankings...), tt.hybrid)
+			if got := sectionIndexes(ranked); !slices.Equal(got, tt.want) {
+				t.Errorf("rank order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// stubReranker answers every Rerank call with fixed scores or an error
+type stubReranker struct {
+	scores []float32
+	err    error
+}
+
+func (r stubReranker) Rerank(context.Context, string, []Section) ([]float32, error) {
+	return r.scores, r.err
+}
+
+func TestRankReranker(t *testing.T) {
+	// fused order is 0, 1, 2
+	rankings := []Ranking{{VectorRank: 1}, {VectorRank: 2}, {VectorRank: 3}}
+	tests := []struct {
+		name     string
+		reranker stubReranker
+		want     []int
+		reranked bool
+	}{
+		{"reranker scores reorder the candidates", stubReranker{scores: []float32{1, 9, 5}}, []int{1, 2, 0}, true},
+		{"wrong number of scores keeps the fused order", stubReranker{scores: []float32{1, 9}}, []int{0, 1, 2}, false},
+		{"reranker error keeps the fused order", stubReranker{err: errors.New("rerank service down")}, []int{0, 1, 2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retriever := NewRetriever(nil, RetrieverConfig{Reranker: tt.reranker})
+			ranked := retriever.rank(context.Background(), "golang", rankedSections(rankings...), true)
+			if got := sectionIndexes(ranked); !slices.Equal(got, tt.want) {
+				t.Errorf("rank order = %v, want %v", got, tt.want)
+			}
+			for _, section := range ranked {
+				if section.Ranking.Reranked != tt.reranked {
+					t.Errorf("section %d reranked = %t, want %t", section.Index, section.Ranking.Reranked, tt.reranked)
+				}
+				if !tt.reranked && section.Score != section.Ranking.Fused {
+					t.Errorf("section %d score = %v, want its fused score %v", section.Index, section.Score, section.Ranking.Fused)
+				}
+			}
+		})
+	}
+}